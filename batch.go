@@ -0,0 +1,208 @@
+package burp
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+)
+
+// MaxBatchSize bounds operations of a batch.
+const MaxBatchSize = 1000
+
+var (
+	ErrBatchEmpty          = Error("batch has no operation")
+	ErrBatchTooLarge       = Errorf("batch exceed %d operations", MaxBatchSize)
+	ErrBatchOpNotSupported = Error("batch operation not supported, want create, update or delete")
+	ErrBatchAborted        = Error("not run as another operation of the batch failed")
+	ErrAtomicBatchDelete   = Error("atomic batch cannot delete beers without transactions")
+	ErrVersionMissing      = Error("version is missing")
+)
+
+type BatchOpKind string
+
+const (
+	BatchCreate BatchOpKind = "create"
+	BatchUpdate BatchOpKind = "update"
+	BatchDelete BatchOpKind = "delete"
+)
+
+// BatchOp is an operation on a beer within a batch. Only
+// the id of Beer is read to delete it.
+type BatchOp struct {
+	Kind BatchOpKind
+	Beer *Beer
+}
+
+// BatchResult is the outcome of an operation of a batch.
+type BatchResult struct {
+	// Beer is the created or updated beer, nil otherwise.
+	Beer *Beer
+	Err  error
+}
+
+// Batch runs operations in order, and returns their results in the same
+// order. Beers created without id are given a new one, those updated
+// need their version.
+//
+// Operations of a batch are independent unless it is atomic: then
+// either all are run, in a single repository transaction, or none is.
+// Without Tx, atomic batches only create and update beers.
+func (b *Brewer) Batch(ctx context.Context, ops []*BatchOp, atomic bool) ([]*BatchResult, error) {
+	if len(ops) == 0 {
+		return nil, ErrBatchEmpty
+	}
+
+	if len(ops) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	if atomic {
+		return b.batchAll(ctx, ops)
+	}
+
+	results := make([]*BatchResult, len(ops))
+	for i, op := range ops {
+		results[i] = &BatchResult{}
+
+		if err := prepareBatchOp(op); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if op.Kind == BatchDelete {
+			results[i].Err = b.RemoveBeer(ctx, op.Beer.ID)
+			continue
+		}
+
+		if results[i].Err = b.SaveBeer(ctx, op.Beer); results[i].Err == nil {
+			results[i].Beer = op.Beer
+		}
+	}
+
+	return results, nil
+}
+
+// batchAll runs all ops or none, none being run when one is invalid.
+func (b *Brewer) batchAll(ctx context.Context, ops []*BatchOp) ([]*BatchResult, error) {
+	results := make([]*BatchResult, len(ops))
+	beers := make([]*Beer, 0, len(ops))
+	failed := false
+
+	for i, op := range ops {
+		results[i] = &BatchResult{}
+
+		err := prepareBatchOp(op)
+		if err == nil && op.Kind == BatchDelete && b.Tx == nil {
+			err = ErrAtomicBatchDelete
+		}
+
+		if err == nil && op.Kind != BatchDelete {
+			b.stamp(op.Beer)
+			err = op.Beer.Validate()
+			beers = append(beers, op.Beer)
+		}
+
+		results[i].Err = err
+		failed = failed || err != nil
+	}
+
+	if failed {
+		abort(results)
+		return results, nil
+	}
+
+	if b.Tx == nil {
+		return b.saveBatch(ctx, beers, results)
+	}
+
+	failedAt := -1
+	err := b.Tx.RunInTx(ctx, func(ctx context.Context) error {
+		for i, op := range ops {
+			var err error
+			if op.Kind == BatchDelete {
+				err = b.RemoveBeer(ctx, op.Beer.ID)
+			} else {
+				err = b.SaveBeer(ctx, op.Beer)
+			}
+
+			if err != nil {
+				failedAt = i
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil && failedAt < 0 {
+		return nil, err
+	}
+
+	if err != nil {
+		results[failedAt].Err = err
+		abort(results)
+		return results, nil
+	}
+
+	for i, op := range ops {
+		if op.Kind != BatchDelete {
+			results[i].Beer = op.Beer
+		}
+	}
+
+	return results, nil
+}
+
+// saveBatch saves beers of an atomic batch without transactions,
+// at once with BeersSaver.
+func (b *Brewer) saveBatch(ctx context.Context, beers []*Beer, results []*BatchResult) ([]*BatchResult, error) {
+	err := b.saveAll(ctx, beers)
+	if err != nil && !errors.As(err, &Err{}) {
+		return nil, err
+	}
+
+	for i, result := range results {
+		result.Err = err
+		if err == nil {
+			result.Beer = beers[i]
+		}
+	}
+
+	return results, nil
+}
+
+// abort fails results of operations not run as others failed.
+func abort(results []*BatchResult) {
+	for _, result := range results {
+		if result.Err == nil {
+			result.Err = ErrBatchAborted
+		}
+	}
+}
+
+// prepareBatchOp checks op can be run, giving a new id
+// to the beer it creates.
+func prepareBatchOp(op *BatchOp) error {
+	if op.Beer == nil {
+		op.Beer = &Beer{}
+	}
+
+	switch op.Kind {
+	case BatchCreate:
+		op.Beer.Version = 0
+		if op.Beer.ID.UUID == uuid.Nil {
+			op.Beer.ID = ID{UUID: uuid.New()}
+		}
+	case BatchUpdate:
+		if op.Beer.Version == 0 {
+			return ErrVersionMissing
+		}
+	case BatchDelete:
+		if err := op.Beer.ID.Validate(); err != nil {
+			return Errorf("invalid id: %w", err)
+		}
+	default:
+		return ErrBatchOpNotSupported
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package burp_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo"
+	"burp/repo/memory"
+	"context"
+	"errors"
+	"testing"
+)
+
+// batchErrs returns errors of results, in order.
+func batchErrs(results []*burp.BatchResult) []error {
+	errs := make([]error, len(results))
+	for i, result := range results {
+		errs[i] = result.Err
+	}
+	return errs
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo}
+	stored := burptest.RandBeer()
+	removed := burptest.RandBeer()
+	for _, beer := range []*burp.Beer{stored, removed} {
+		if err := memRepo.SaveBeer(ctx, beer); err != nil {
+			t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+		}
+	}
+
+	updated := *stored
+	updated.Name = "Renamed"
+	ops := []*burp.BatchOp{
+		{Kind: burp.BatchCreate, Beer: &burp.Beer{Name: "Kwak", Price: burp.Price{Currency: burp.EUR, Amount: 350}}},
+		{Kind: burp.BatchCreate, Beer: &burp.Beer{Name: "Kwak", Price: burp.Price{Currency: "Yen", Amount: 350}}},
+		{Kind: burp.BatchUpdate, Beer: &updated},
+		{Kind: burp.BatchUpdate, Beer: &burp.Beer{ID: stored.ID, Name: "Stale", Price: stored.Price}},
+		{Kind: burp.BatchDelete, Beer: &burp.Beer{ID: removed.ID}},
+		{Kind: burp.BatchDelete, Beer: &burp.Beer{ID: removed.ID}},
+		{Kind: "brew"},
+	}
+
+	results, err := brewer.Batch(ctx, ops, false)
+	if err != nil {
+		t.Fatalf("Batch(ctx, ops, false) returned unexpected error %s", err)
+	}
+
+	want := []error{nil, burp.ErrCurrencyNotSupported, nil, burp.ErrVersionMissing, nil, repo.ErrNotFound, burp.ErrBatchOpNotSupported}
+	for i, err := range batchErrs(results) {
+		if !errors.Is(err, want[i]) {
+			t.Errorf("Batch(ctx, ops, false) returned error %v for operation %d, want %v", err, i, want[i])
+		}
+	}
+
+	if results[0].Beer == nil || results[0].Beer.Version != 1 || results[0].Beer.ID.Validate() != nil {
+		t.Errorf("Batch(ctx, ops, false) returned created beer %+v, want it with an id at version 1", results[0].Beer)
+	}
+
+	if results[2].Beer == nil || results[2].Beer.Version != 2 {
+		t.Errorf("Batch(ctx, ops, false) returned updated beer %+v, want it at version 2", results[2].Beer)
+	}
+}
+
+func TestAtomicBatch(t *testing.T) {
+	ctx := context.Background()
+	brewer := &burp.Brewer{BeerRepo: memory.New()}
+	kwak := &burp.Beer{Name: "Kwak", Price: burp.Price{Currency: burp.EUR, Amount: 350}}
+
+	tests := []struct {
+		description string
+		ops         []*burp.BatchOp
+		want        []error
+	}{
+		{
+			description: "an invalid beer",
+			ops: []*burp.BatchOp{
+				{Kind: burp.BatchCreate, Beer: kwak},
+				{Kind: burp.BatchCreate, Beer: &burp.Beer{Name: "Orval"}},
+			},
+			want: []error{burp.ErrBatchAborted, burp.ErrCurrencyNotSupported},
+		},
+		{
+			description: "a deletion",
+			ops: []*burp.BatchOp{
+				{Kind: burp.BatchCreate, Beer: kwak},
+				{Kind: burp.BatchDelete, Beer: burptest.RandBeer()},
+			},
+			want: []error{burp.ErrBatchAborted, burp.ErrAtomicBatchDelete},
+		},
+		{
+			description: "a conflict",
+			ops: []*burp.BatchOp{
+				{Kind: burp.BatchCreate, Beer: kwak},
+				{Kind: burp.BatchUpdate, Beer: &burp.Beer{ID: burptest.RandBeer().ID, Version: 1, Name: "Orval", Price: kwak.Price}},
+			},
+			want: []error{burp.ErrVersionConflict, burp.ErrVersionConflict},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			results, err := brewer.Batch(ctx, test.ops, true)
+			if err != nil {
+				t.Fatalf("Batch(ctx, ops, true) with %s returned unexpected error %s", test.description, err)
+			}
+
+			for i, err := range batchErrs(results) {
+				if !errors.Is(err, test.want[i]) {
+					t.Errorf("Batch(ctx, ops, true) with %s returned error %v for operation %d, want %v", test.description, err, i, test.want[i])
+				}
+			}
+		})
+	}
+
+	page, err := brewer.ListBeers(ctx, burp.BeerQuery{})
+	if err != nil || len(page.Beers) != 0 {
+		t.Fatalf("ListBeers(ctx, q) after failed atomic batches returned %+v, %v, want no beers", page, err)
+	}
+
+	ops := []*burp.BatchOp{
+		{Kind: burp.BatchCreate, Beer: &burp.Beer{Name: "Kwak", Price: kwak.Price}},
+		{Kind: burp.BatchCreate, Beer: &burp.Beer{Name: "Orval", Price: kwak.Price}},
+	}
+	results, err := brewer.Batch(ctx, ops, true)
+	if err != nil {
+		t.Fatalf("Batch(ctx, ops, true) returned unexpected error %s", err)
+	}
+
+	for i, result := range results {
+		if result.Err != nil || result.Beer == nil || result.Beer.Version != 1 {
+			t.Errorf("Batch(ctx, ops, true) returned result %+v for operation %d, want a created beer", result, i)
+		}
+	}
+}
+
+func TestAtomicBatchInTx(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo, Tx: memRepo}
+	stored := burptest.RandBeer()
+	if err := memRepo.SaveBeer(ctx, stored); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", stored, err)
+	}
+
+	kwak := burp.Price{Currency: burp.EUR, Amount: 350}
+	ops := []*burp.BatchOp{
+		{Kind: burp.BatchCreate, Beer: &burp.Beer{Name: "Kwak", Price: kwak}},
+		{Kind: burp.BatchDelete, Beer: &burp.Beer{ID: stored.ID}},
+		{Kind: burp.BatchDelete, Beer: burptest.RandBeer()},
+	}
+
+	results, err := brewer.Batch(ctx, ops, true)
+	if err != nil {
+		t.Fatalf("Batch(ctx, ops, true) returned unexpected error %s", err)
+	}
+
+	want := []error{burp.ErrBatchAborted, burp.ErrBatchAborted, repo.ErrNotFound}
+	for i, err := range batchErrs(results) {
+		if !errors.Is(err, want[i]) {
+			t.Errorf("Batch(ctx, ops, true) returned error %v for operation %d, want %v", err, i, want[i])
+		}
+	}
+
+	if _, err := memRepo.SelectBeer(ctx, stored.ID); err != nil {
+		t.Errorf("SelectBeer(ctx, %s) after rolled back batch returned error %s", stored.ID, err)
+	}
+
+	ops = []*burp.BatchOp{
+		{Kind: burp.BatchCreate, Beer: &burp.Beer{Name: "Kwak", Price: kwak}},
+		{Kind: burp.BatchDelete, Beer: &burp.Beer{ID: stored.ID}},
+	}
+
+	results, err = brewer.Batch(ctx, ops, true)
+	if err != nil {
+		t.Fatalf("Batch(ctx, ops, true) returned unexpected error %s", err)
+	}
+
+	for i, err := range batchErrs(results) {
+		if err != nil {
+			t.Errorf("Batch(ctx, ops, true) returned unexpected error %s for operation %d", err, i)
+		}
+	}
+
+	if _, err := memRepo.SelectBeer(ctx, stored.ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("SelectBeer(ctx, %s) after batch deleting it returned error %v, want %v", stored.ID, err, repo.ErrNotFound)
+	}
+
+	page, err := brewer.ListBeers(ctx, burp.BeerQuery{})
+	if err != nil || len(page.Beers) != 1 {
+		t.Errorf("ListBeers(ctx, q) after batch returned %+v, %v, want the created beer", page, err)
+	}
+}
+
+func TestBatchSize(t *testing.T) {
+	brewer := &burp.Brewer{BeerRepo: memory.New()}
+
+	_, err := brewer.Batch(context.Background(), nil, false)
+	if !errors.Is(err, burp.ErrBatchEmpty) {
+		t.Errorf("Batch(ctx, nil, false) returned error %v, want %v", err, burp.ErrBatchEmpty)
+	}
+
+	ops := make([]*burp.BatchOp, burp.MaxBatchSize+1)
+	_, err = brewer.Batch(context.Background(), ops, false)
+	if !errors.Is(err, burp.ErrBatchTooLarge) {
+		t.Errorf("Batch(ctx, ops, false) of %d operations returned error %v, want %v", len(ops), err, burp.ErrBatchTooLarge)
+	}
+}
@@ -0,0 +1,71 @@
+// Package beerio encodes and decodes beers in bulk, streaming them
+// as CSV, for spreadsheets, or as newline delimited JSON.
+package beerio
+
+import (
+	"burp"
+	"io"
+	"mime"
+)
+
+var ErrFormatNotSupported = burp.Error("format not supported, want csv or ndjson")
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+)
+
+func (f Format) Validate() error {
+	if f != CSV && f != NDJSON {
+		return ErrFormatNotSupported
+	}
+	return nil
+}
+
+// ContentType returns the media type of f.
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// FormatOf returns the format of given media type.
+func FormatOf(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", ErrFormatNotSupported
+	}
+
+	for _, f := range []Format{CSV, NDJSON} {
+		if mediaType == f.ContentType() {
+			return f, nil
+		}
+	}
+
+	return "", ErrFormatNotSupported
+}
+
+func NewDecoder(f Format, r io.Reader) (burp.BeerDecoder, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	if f == CSV {
+		return NewCSVDecoder(r), nil
+	}
+	return NewNDJSONDecoder(r), nil
+}
+
+func NewEncoder(f Format, w io.Writer) (burp.BeerEncoder, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	if f == CSV {
+		return NewCSVEncoder(w), nil
+	}
+	return NewNDJSONEncoder(w), nil
+}
@@ -0,0 +1,197 @@
+package beerio_test
+
+import (
+	"burp"
+	"burp/beerio"
+	"burp/burptest"
+	"bytes"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeAll decodes beers until io.EOF, collecting row errors.
+func decodeAll(t *testing.T, dec burp.BeerDecoder) ([]*burp.Beer, []*burp.RowError) {
+	t.Helper()
+
+	var (
+		beers  []*burp.Beer
+		errs   []*burp.RowError
+		rowErr *burp.RowError
+	)
+
+	for {
+		beer, err := dec.Decode()
+		if err == io.EOF {
+			return beers, errs
+		}
+
+		if errors.As(err, &rowErr) {
+			errs = append(errs, rowErr)
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Decode() returned unexpected error %s", err)
+		}
+
+		beers = append(beers, beer)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	beers := []*burp.Beer{burptest.RandBeer(), burptest.RandBeer()}
+	beers[0].Version = 3
+	beers[1].Name = `"Odd, beer"`
+	beers[1].BreweryID = &burptest.RandBrewery().ID
+
+	for _, format := range []beerio.Format{beerio.CSV, beerio.NDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := beerio.NewEncoder(format, &buf)
+			if err != nil {
+				t.Fatalf("NewEncoder(%q, w) returned unexpected error %s", format, err)
+			}
+
+			for _, beer := range beers {
+				if err := enc.Encode(beer); err != nil {
+					t.Fatalf("Encode(%+v) returned unexpected error %s", beer, err)
+				}
+			}
+
+			if err := enc.Flush(); err != nil {
+				t.Fatalf("Flush() returned unexpected error %s", err)
+			}
+
+			dec, err := beerio.NewDecoder(format, &buf)
+			if err != nil {
+				t.Fatalf("NewDecoder(%q, r) returned unexpected error %s", format, err)
+			}
+
+			got, errs := decodeAll(t, dec)
+			if len(errs) != 0 {
+				t.Fatalf("Decode() of encoded beers returned row errors %v", errs)
+			}
+
+			// csv drops dates, set on import
+			want := beers
+			if format == beerio.CSV {
+				want = nil
+				for _, beer := range beers {
+					w := *beer
+					w.CreatedAt, w.UpdatedAt = time.Time{}, time.Time{}
+					want = append(want, &w)
+				}
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Decode() of encoded beers returned unexpected beers, (-want/+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	input := "\ufeffName, Amount,CURRENCY\n" +
+		"Kwak,350,Euro\n" +
+		"Chimay,ten,Euro\n" +
+		"Orval,400\n" +
+		"Duvel,420,Euro\n"
+
+	beers, errs := decodeAll(t, beerio.NewCSVDecoder(strings.NewReader(input)))
+
+	want := []*burp.Beer{
+		{Name: "Kwak", Price: burp.Price{Currency: burp.EUR, Amount: 350}},
+		{Name: "Duvel", Price: burp.Price{Currency: burp.EUR, Amount: 420}},
+	}
+	if diff := cmp.Diff(want, beers); diff != "" {
+		t.Errorf("Decode() returned unexpected beers, (-want/+got):\n%s", diff)
+	}
+
+	var rows []int
+	for _, err := range errs {
+		rows = append(rows, err.Row)
+		if !errors.As(err, &burp.Err{}) {
+			t.Errorf("Decode() returned row error %v, want a burp.Err", err)
+		}
+	}
+
+	if diff := cmp.Diff([]int{3, 4}, rows); diff != "" {
+		t.Errorf("Decode() returned errors of unexpected rows, (-want/+got):\n%s", diff)
+	}
+}
+
+func TestDecodeCSVWithInvalidHeader(t *testing.T) {
+	tests := []struct {
+		description string
+		header      string
+	}{
+		{description: "unknown column", header: "name,currency,amount,color"},
+		{description: "duplicate column", header: "name,currency,amount,Name"},
+		{description: "missing column", header: "name,currency"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			dec := beerio.NewCSVDecoder(strings.NewReader(test.header + "\n"))
+
+			_, err := dec.Decode()
+			var rowErr *burp.RowError
+			if !errors.As(err, &burp.Err{}) || errors.As(err, &rowErr) {
+				t.Errorf("Decode() with %s returned error %v, want a burp.Err", test.description, err)
+			}
+		})
+	}
+}
+
+func TestDecodeNDJSON(t *testing.T) {
+	input := `{"name":"Kwak","price":{"currency":"Euro","amount":350}}
+
+{"name":"Chimay","price":{"currency":"Euro","amount":"ten"}}
+{"name":"Orval","color":"amber"}
+{"name":"Duvel"} {"name":"Rochefort"}
+{"name":"Westmalle","price":{"currency":"Euro","amount":390}}
+`
+
+	beers, errs := decodeAll(t, beerio.NewNDJSONDecoder(strings.NewReader(input)))
+
+	want := []*burp.Beer{
+		{Name: "Kwak", Price: burp.Price{Currency: burp.EUR, Amount: 350}},
+		{Name: "Westmalle", Price: burp.Price{Currency: burp.EUR, Amount: 390}},
+	}
+	if diff := cmp.Diff(want, beers); diff != "" {
+		t.Errorf("Decode() returned unexpected beers, (-want/+got):\n%s", diff)
+	}
+
+	var rows []int
+	for _, err := range errs {
+		rows = append(rows, err.Row)
+	}
+
+	if diff := cmp.Diff([]int{3, 4, 5}, rows); diff != "" {
+		t.Errorf("Decode() returned errors of unexpected rows, (-want/+got):\n%s", diff)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        beerio.Format
+		wantErr     error
+	}{
+		{contentType: "text/csv; charset=utf-8", want: beerio.CSV},
+		{contentType: "application/x-ndjson", want: beerio.NDJSON},
+		{contentType: "application/json", wantErr: beerio.ErrFormatNotSupported},
+		{contentType: "", wantErr: beerio.ErrFormatNotSupported},
+	}
+
+	for _, test := range tests {
+		got, err := beerio.FormatOf(test.contentType)
+		if got != test.want || !errors.Is(err, test.wantErr) {
+			t.Errorf("FormatOf(%q) returned %q, %v, want %q, %v", test.contentType, got, err, test.want, test.wantErr)
+		}
+	}
+}
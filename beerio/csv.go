@@ -0,0 +1,254 @@
+package beerio
+
+import (
+	"burp"
+	"encoding/csv"
+	"errors"
+	"github.com/google/uuid"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns are written by CSVEncoder, in order.
+var csvColumns = []string{
+	"id", "version", "name", "currency", "amount", "createdAt", "updatedAt",
+	"brewery", "style", "abv", "ibu", "volume", "description", "breweryId", "tags",
+}
+
+// tagSeparator joins tags in a single field,
+// as it is not allowed in tags.
+const tagSeparator = ";"
+
+// requiredColumns must be read by CSVDecoder, others are optional.
+var requiredColumns = []string{"name", "currency", "amount"}
+
+// CSVDecoder decodes beers from CSV rows, named by a header row.
+// Columns are those of CSVEncoder, in any order and case. Rows
+// without id are new beers, those with one update the stored beer
+// at given version. Creation and update dates are ignored.
+type CSVDecoder struct {
+	r *csv.Reader
+
+	// columns indexes fields by column, nil until header is read
+	columns map[string]int
+	row     int
+}
+
+func NewCSVDecoder(r io.Reader) *CSVDecoder {
+	return &CSVDecoder{r: csv.NewReader(r)}
+}
+
+func (d *CSVDecoder) Row() int { return d.row }
+
+func (d *CSVDecoder) Decode() (*burp.Beer, error) {
+	if d.columns == nil {
+		if err := d.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := d.r.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		d.row = parseErr.StartLine
+		return nil, &burp.RowError{Row: d.row, Err: burp.Errorf("invalid csv: %s", parseErr.Err)}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	d.row, _ = d.r.FieldPos(0)
+
+	beer, err := d.beer(record)
+	if err != nil {
+		return nil, &burp.RowError{Row: d.row, Err: err}
+	}
+
+	return beer, nil
+}
+
+func (d *CSVDecoder) readHeader() error {
+	header, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	d.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		// spreadsheets may start files with a byte order mark
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+
+		column := ""
+		for _, c := range csvColumns {
+			if strings.EqualFold(c, name) {
+				column = c
+			}
+		}
+
+		if column == "" {
+			return burp.Errorf("unknown column %q", name)
+		}
+
+		if _, ok := d.columns[column]; ok {
+			return burp.Errorf("duplicate column %q", name)
+		}
+
+		d.columns[column] = i
+	}
+
+	for _, c := range requiredColumns {
+		if _, ok := d.columns[c]; !ok {
+			return burp.Errorf("column %q is missing", c)
+		}
+	}
+
+	return nil
+}
+
+func (d *CSVDecoder) beer(record []string) (*burp.Beer, error) {
+	field := func(column string) string {
+		i, ok := d.columns[column]
+		if !ok {
+			return ""
+		}
+		return record[i]
+	}
+
+	beer := &burp.Beer{
+		Name:        field("name"),
+		Price:       burp.Price{Currency: burp.ParseCurrency(field("currency"))},
+		Brewery:     field("brewery"),
+		Style:       field("style"),
+		Description: field("description"),
+	}
+
+	if id := strings.TrimSpace(field("id")); id != "" {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, burp.Errorf("invalid id %q", id)
+		}
+		beer.ID = burp.ID{UUID: uid}
+	}
+
+	if id := strings.TrimSpace(field("breweryId")); id != "" {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, burp.Errorf("invalid brewery id %q", id)
+		}
+		beer.BreweryID = &burp.ID{UUID: uid}
+	}
+
+	if tags := strings.TrimSpace(field("tags")); tags != "" {
+		beer.Tags = burp.NormalizeTags(strings.Split(tags, tagSeparator))
+	}
+
+	if version := strings.TrimSpace(field("version")); version != "" {
+		n, err := strconv.ParseUint(version, 10, 0)
+		if err != nil {
+			return nil, burp.Errorf("invalid version %q", version)
+		}
+		beer.Version = uint(n)
+	}
+
+	amount := strings.TrimSpace(field("amount"))
+	n, err := strconv.ParseUint(amount, 10, 0)
+	if err != nil {
+		return nil, burp.Errorf("invalid amount %q", amount)
+	}
+	beer.Price.Amount = uint(n)
+
+	if abv := strings.TrimSpace(field("abv")); abv != "" {
+		if beer.ABV, err = strconv.ParseFloat(abv, 64); err != nil {
+			return nil, burp.Errorf("invalid abv %q", abv)
+		}
+	}
+
+	for column, value := range map[string]*uint{"ibu": &beer.IBU, "volume": &beer.Volume} {
+		p := strings.TrimSpace(field(column))
+		if p == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(p, 10, 0)
+		if err != nil {
+			return nil, burp.Errorf("invalid %s %q", column, p)
+		}
+		*value = uint(n)
+	}
+
+	return beer, nil
+}
+
+// CSVEncoder encodes beers as CSV rows, after a header row.
+type CSVEncoder struct {
+	w      *csv.Writer
+	header bool
+}
+
+func NewCSVEncoder(w io.Writer) *CSVEncoder {
+	return &CSVEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *CSVEncoder) Encode(beer *burp.Beer) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	breweryID := ""
+	if beer.BreweryID != nil {
+		breweryID = beer.BreweryID.String()
+	}
+
+	return e.w.Write([]string{
+		beer.ID.String(),
+		strconv.FormatUint(uint64(beer.Version), 10),
+		beer.Name,
+		string(beer.Price.Currency),
+		strconv.FormatUint(uint64(beer.Price.Amount), 10),
+		beer.CreatedAt.Format(time.RFC3339Nano),
+		beer.UpdatedAt.Format(time.RFC3339Nano),
+		beer.Brewery,
+		beer.Style,
+		formatOptional(beer.ABV != 0, strconv.FormatFloat(beer.ABV, 'f', -1, 64)),
+		formatOptional(beer.IBU != 0, strconv.FormatUint(uint64(beer.IBU), 10)),
+		formatOptional(beer.Volume != 0, strconv.FormatUint(uint64(beer.Volume), 10)),
+		beer.Description,
+		breweryID,
+		strings.Join(beer.Tags, tagSeparator),
+	})
+}
+
+// formatOptional returns value when set, an empty field otherwise,
+// so that unknown details are left blank.
+func formatOptional(set bool, value string) string {
+	if !set {
+		return ""
+	}
+	return value
+}
+
+// Flush writes encoded rows, and the header alone if there are none.
+func (e *CSVEncoder) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *CSVEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+
+	e.header = true
+	return e.w.Write(csvColumns)
+}
@@ -0,0 +1,81 @@
+package beerio
+
+import (
+	"bufio"
+	"burp"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// MaxLineSize bounds the size of a line read by NDJSONDecoder.
+const MaxLineSize = 64 * 1024
+
+// NDJSONDecoder decodes beers from lines of JSON, as encoded by
+// NDJSONEncoder. Blank lines are skipped. Beers without id are new,
+// those with one update the stored beer at given version.
+type NDJSONDecoder struct {
+	s   *bufio.Scanner
+	row int
+}
+
+func NewNDJSONDecoder(r io.Reader) *NDJSONDecoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, MaxLineSize)
+	return &NDJSONDecoder{s: s}
+}
+
+func (d *NDJSONDecoder) Row() int { return d.row }
+
+func (d *NDJSONDecoder) Decode() (*burp.Beer, error) {
+	for d.s.Scan() {
+		d.row++
+
+		line := bytes.TrimSpace(d.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+
+		var beer burp.Beer
+		if err := dec.Decode(&beer); err != nil {
+			return nil, &burp.RowError{Row: d.row, Err: burp.Errorf("invalid json: %s", err)}
+		}
+
+		if dec.InputOffset() != int64(len(line)) {
+			return nil, &burp.RowError{Row: d.row, Err: burp.Error("invalid json: want a single beer per line")}
+		}
+
+		return &beer, nil
+	}
+
+	if err := d.s.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return nil, burp.Errorf("line %d exceed %d bytes", d.row+1, MaxLineSize)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// NDJSONEncoder encodes beers as lines of JSON.
+type NDJSONEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func NewNDJSONEncoder(w io.Writer) *NDJSONEncoder {
+	bw := bufio.NewWriter(w)
+	return &NDJSONEncoder{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (e *NDJSONEncoder) Encode(beer *burp.Beer) error {
+	return e.enc.Encode(beer)
+}
+
+func (e *NDJSONEncoder) Flush() error {
+	return e.w.Flush()
+}
@@ -2,45 +2,261 @@ package burp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
 )
 
 type BeerRepo interface {
 	BeerSaver
+	BeersSaver
 	BeerSelector
 	BeerRemover
+	BeerRestorer
+	BeerPurger
+	BeerLister
+	BeerSearcher
 }
 
+// BeerSaver saves a beer. When it already exists, its stored
+// creation date is preserved and set back on given beer.
 type BeerSaver interface {
 	SaveBeer(ctx context.Context, beer *Beer) error
 }
 
+// BeersSaver saves beers the way BeerSaver does, all of them or none.
+type BeersSaver interface {
+	SaveBeers(ctx context.Context, beers []*Beer) error
+}
+
 type BeerSelector interface {
 	SelectBeer(ctx context.Context, id ID) (*Beer, error)
 }
 
+// BeerRemover moves a beer to trash at given time. Removed
+// beers are no longer selected, listed nor searched.
 type BeerRemover interface {
-	RemoveBeer(ctx context.Context, id ID) error
+	RemoveBeer(ctx context.Context, id ID, at time.Time) error
+}
+
+// BeerRestorer moves a beer out of trash.
+type BeerRestorer interface {
+	RestoreBeer(ctx context.Context, id ID) error
+}
+
+// BeerPurger deletes permanently a beer in trash.
+type BeerPurger interface {
+	PurgeBeer(ctx context.Context, id ID) error
+}
+
+// BeerLister lists at most q.Limit beers matching q.Filter,
+// ordered by q.Sort and following q.After when set.
+type BeerLister interface {
+	ListBeers(ctx context.Context, q BeerQuery) ([]*Beer, error)
+}
+
+// BeerSearcher returns at most s.Limit beers matching s.Text,
+// best matches first as ranked by Score.
+type BeerSearcher interface {
+	SearchBeers(ctx context.Context, s BeerSearch) ([]*BeerMatch, error)
+}
+
+type BeerEventRepo interface {
+	BeerEventSaver
+	BeerHistorySelector
+}
+
+type BeerEventSaver interface {
+	SaveBeerEvent(ctx context.Context, event *BeerEvent) error
+}
+
+// BeerHistorySelector selects events of a beer, oldest first.
+type BeerHistorySelector interface {
+	SelectBeerHistory(ctx context.Context, id ID) ([]*BeerEvent, error)
+}
+
+type PriceRepo interface {
+	PriceChangeSaver
+	PriceChangesSelector
+	PriceAtSelector
+}
+
+// PriceChangeSaver saves a price change, replacing
+// any change of the same beer from the same instant.
+type PriceChangeSaver interface {
+	SavePriceChange(ctx context.Context, c *PriceChange) error
+}
+
+// PriceChangesSelector selects price changes of a beer, oldest first.
+type PriceChangesSelector interface {
+	SelectPriceChanges(ctx context.Context, id ID) ([]*PriceChange, error)
+}
+
+// PriceAtSelector selects the last price change of a beer
+// made effective at or before given instant.
+type PriceAtSelector interface {
+	SelectPriceAt(ctx context.Context, id ID, at time.Time) (*PriceChange, error)
+}
+
+// TxRunner runs fn in a transaction, committed when fn returns nil
+// and rolled back otherwise. Repos called with the context given to
+// fn take part in it, as do nested RunInTx.
+type TxRunner interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type Clock interface {
+	Now() time.Time
 }
 
 type Brewer struct {
 	BeerRepo BeerRepo
+
+	// EventRepo records beers changes, none are when nil.
+	EventRepo BeerEventRepo
+
+	// PriceRepo records beers price history, none is when nil.
+	PriceRepo PriceRepo
+
+	// BreweryRepo stores breweries, beers cannot refer to any when nil.
+	BreweryRepo BreweryRepo
+
+	// StockRepo tracks beers stock, none is when nil.
+	StockRepo StockRepo
+
+	// OrderRepo stores customer orders, none can be placed when nil.
+	OrderRepo OrderRepo
+
+	// Tx runs use cases changing several records atomically,
+	// they are not when nil.
+	Tx TxRunner
+
+	// Rates converts prices to other currencies, they are not when nil.
+	Rates RateProvider
+
+	// Clock tells time of beers changes, system clock when nil.
+	Clock Clock
+}
+
+// inTx runs fn in a transaction of b.Tx, if any.
+func (b *Brewer) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if b.Tx == nil {
+		return fn(ctx)
+	}
+	return b.Tx.RunInTx(ctx, fn)
 }
 
+func (b *Brewer) now() time.Time {
+	if b.Clock == nil {
+		return time.Now().UTC()
+	}
+	return b.Clock.Now().UTC()
+}
+
+// SaveBeer stamps beer with current time, validates and saves it.
+// Creation date sent for an existing beer is ignored.
 func (b *Brewer) SaveBeer(ctx context.Context, beer *Beer) error {
-	if err := b.BeerRepo.SaveBeer(ctx, beer); err != nil {
-		return fmt.Errorf("unable to save beer %+v: %w", beer, err)
+	b.stamp(beer)
+
+	if err := beer.Validate(); err != nil {
+		return err
 	}
 
-	return nil
+	return b.inTx(ctx, func(ctx context.Context) error {
+		if err := b.checkBrewery(ctx, beer); err != nil {
+			return err
+		}
+
+		kind, before, err := b.prior(ctx, beer)
+		if err != nil {
+			return fmt.Errorf("unable to save beer %+v: %w", beer, err)
+		}
+
+		if err := b.BeerRepo.SaveBeer(ctx, beer); err != nil {
+			return fmt.Errorf("unable to save beer %+v: %w", beer, err)
+		}
+
+		return b.recordSave(ctx, kind, before, beer)
+	})
 }
 
-func (b *Brewer) RemoveBeer(ctx context.Context, id ID) error {
-	if err := b.BeerRepo.RemoveBeer(ctx, id); err != nil {
-		return fmt.Errorf("unable to remove beer %+v: %w", id, err)
+// stamp sets creation and update dates of beer to current time,
+// normalizes its tags and clears the name of its brewery when it
+// refers to a stored one, before it is validated.
+func (b *Brewer) stamp(beer *Beer) {
+	now := b.now()
+	beer.CreatedAt = now
+	beer.UpdatedAt = now
+	beer.Tags = NormalizeTags(beer.Tags)
+
+	if beer.BreweryID != nil {
+		beer.Brewery = ""
 	}
+}
 
-	return nil
+// prior returns the kind of change saving beer makes, along
+// with a snapshot of beer as it is before being updated.
+func (b *Brewer) prior(ctx context.Context, beer *Beer) (BeerEventKind, *Beer, error) {
+	if beer.Version == 0 {
+		return BeerCreated, nil, nil
+	}
+
+	before, err := b.snapshot(ctx, beer.ID)
+	return BeerUpdated, before, err
+}
+
+// recordSave records the price and event of a saved beer.
+func (b *Brewer) recordSave(ctx context.Context, kind BeerEventKind, before, beer *Beer) error {
+	if b.PriceRepo != nil && (before == nil || before.Price != beer.Price) {
+		change := &PriceChange{BeerID: beer.ID, Price: beer.Price, From: beer.UpdatedAt}
+		if err := b.PriceRepo.SavePriceChange(ctx, change); err != nil {
+			return fmt.Errorf("unable to save price change %+v: %w", change, err)
+		}
+	}
+
+	return b.record(ctx, kind, beer.ID, before, clone(beer))
+}
+
+func (b *Brewer) RemoveBeer(ctx context.Context, id ID) error {
+	return b.inTx(ctx, func(ctx context.Context) error {
+		before, err := b.snapshot(ctx, id)
+		if err != nil {
+			return fmt.Errorf("unable to remove beer %+v: %w", id, err)
+		}
+
+		if err := b.BeerRepo.RemoveBeer(ctx, id, b.now()); err != nil {
+			return fmt.Errorf("unable to remove beer %+v: %w", id, err)
+		}
+
+		return b.record(ctx, BeerDeleted, id, before, nil)
+	})
+}
+
+func (b *Brewer) RestoreBeer(ctx context.Context, id ID) error {
+	return b.inTx(ctx, func(ctx context.Context) error {
+		if err := b.BeerRepo.RestoreBeer(ctx, id); err != nil {
+			return fmt.Errorf("unable to restore beer %+v: %w", id, err)
+		}
+
+		after, err := b.snapshot(ctx, id)
+		if err != nil {
+			return fmt.Errorf("unable to select restored beer %+v: %w", id, err)
+		}
+
+		return b.record(ctx, BeerRestored, id, nil, after)
+	})
+}
+
+func (b *Brewer) PurgeBeer(ctx context.Context, id ID) error {
+	return b.inTx(ctx, func(ctx context.Context) error {
+		if err := b.BeerRepo.PurgeBeer(ctx, id); err != nil {
+			return fmt.Errorf("unable to purge beer %+v: %w", id, err)
+		}
+
+		return b.record(ctx, BeerPurged, id, nil, nil)
+	})
 }
 
 func (b *Brewer) SelectBeer(ctx context.Context, id ID) (*Beer, error) {
@@ -51,3 +267,241 @@ func (b *Brewer) SelectBeer(ctx context.Context, id ID) (*Beer, error) {
 
 	return beer, nil
 }
+
+// BeerHistory returns changes made to a beer, oldest first.
+func (b *Brewer) BeerHistory(ctx context.Context, id ID) ([]*BeerEvent, error) {
+	if b.EventRepo == nil {
+		return []*BeerEvent{}, nil
+	}
+
+	events, err := b.EventRepo.SelectBeerHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select history of beer %q: %w", id, err)
+	}
+
+	if events == nil {
+		events = []*BeerEvent{}
+	}
+
+	return events, nil
+}
+
+// SchedulePrice changes the price of a beer from a future instant.
+// A beer without price history, saved before prices were recorded,
+// first gets its current price recorded from its last update, so
+// that it keeps it until the scheduled change.
+func (b *Brewer) SchedulePrice(ctx context.Context, c *PriceChange) error {
+	if b.PriceRepo == nil {
+		return ErrPricingUnavailable
+	}
+
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if !c.From.After(b.now()) {
+		return ErrPriceChangeNotInFuture
+	}
+
+	return b.inTx(ctx, func(ctx context.Context) error {
+		beer, err := b.BeerRepo.SelectBeer(ctx, c.BeerID)
+		if err != nil {
+			return fmt.Errorf("unable to schedule price change %+v: %w", c, err)
+		}
+
+		changes, err := b.PriceRepo.SelectPriceChanges(ctx, beer.ID)
+		if err != nil {
+			return fmt.Errorf("unable to select price history of beer %q: %w", beer.ID, err)
+		}
+
+		if len(changes) == 0 {
+			current := &PriceChange{BeerID: beer.ID, Price: beer.Price, From: beer.UpdatedAt}
+			if err := b.PriceRepo.SavePriceChange(ctx, current); err != nil {
+				return fmt.Errorf("unable to save price change %+v: %w", current, err)
+			}
+		}
+
+		if err := b.PriceRepo.SavePriceChange(ctx, c); err != nil {
+			return fmt.Errorf("unable to schedule price change %+v: %w", c, err)
+		}
+
+		return nil
+	})
+}
+
+// PriceHistory returns past, current and scheduled prices of a beer.
+func (b *Brewer) PriceHistory(ctx context.Context, id ID) ([]*PricePeriod, error) {
+	if b.PriceRepo == nil {
+		return []*PricePeriod{}, nil
+	}
+
+	changes, err := b.PriceRepo.SelectPriceChanges(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select price changes of beer %q: %w", id, err)
+	}
+
+	return PricePeriods(changes), nil
+}
+
+// PriceAt returns the price of a beer effective at given instant.
+// Without price history, it is the beer current price.
+func (b *Brewer) PriceAt(ctx context.Context, id ID, at time.Time) (*Price, error) {
+	if b.PriceRepo != nil {
+		change, err := b.PriceRepo.SelectPriceAt(ctx, id, at)
+		if err == nil {
+			return &change.Price, nil
+		}
+
+		if !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("unable to select price of beer %q at %s: %w", id, at, err)
+		}
+	}
+
+	beer, err := b.SelectBeer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.unrecordedPrice(ctx, beer, at)
+}
+
+// priceOf returns the price of a selected beer effective at given instant.
+func (b *Brewer) priceOf(ctx context.Context, beer *Beer, at time.Time) (*Price, error) {
+	if b.PriceRepo == nil {
+		return &beer.Price, nil
+	}
+
+	change, err := b.PriceRepo.SelectPriceAt(ctx, beer.ID, at)
+	if errors.Is(err, ErrNotFound) {
+		return b.unrecordedPrice(ctx, beer, at)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to select price of beer %q at %s: %w", beer.ID, at, err)
+	}
+
+	return &change.Price, nil
+}
+
+// unrecordedPrice returns the price of beer at given instant when none
+// is recorded then. Beers saved before their price was recorded have no
+// price history, and cost their current price. Others had no price
+// before their first recorded one.
+func (b *Brewer) unrecordedPrice(ctx context.Context, beer *Beer, at time.Time) (*Price, error) {
+	if b.PriceRepo == nil {
+		return &beer.Price, nil
+	}
+
+	changes, err := b.PriceRepo.SelectPriceChanges(ctx, beer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select price history of beer %q: %w", beer.ID, err)
+	}
+
+	if len(changes) > 0 {
+		return nil, fmt.Errorf("price of beer %q not found at %s: %w", beer.ID, at, ErrNotFound)
+	}
+
+	return &beer.Price, nil
+}
+
+// snapshot returns a copy of beer of given id to compare
+// with its changes, nil when changes are not recorded.
+func (b *Brewer) snapshot(ctx context.Context, id ID) (*Beer, error) {
+	if b.EventRepo == nil && b.PriceRepo == nil {
+		return nil, nil
+	}
+
+	beer, err := b.BeerRepo.SelectBeer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return clone(beer), nil
+}
+
+func clone(beer *Beer) *Beer {
+	c := *beer
+	return &c
+}
+
+// record saves an event of given kind, if events are recorded.
+func (b *Brewer) record(ctx context.Context, kind BeerEventKind, id ID, before, after *Beer) error {
+	if b.EventRepo == nil {
+		return nil
+	}
+
+	event := &BeerEvent{
+		ID:     ID{UUID: uuid.New()},
+		BeerID: id,
+		Kind:   kind,
+		Actor:  ActorFrom(ctx),
+		Time:   b.now(),
+		Before: before,
+		After:  after,
+	}
+
+	if err := b.EventRepo.SaveBeerEvent(ctx, event); err != nil {
+		return fmt.Errorf("unable to record %s event of beer %q: %w", kind, id, err)
+	}
+
+	return nil
+}
+
+// ListBeers returns a page of beers, along with the cursor
+// of the next page when there are beers left to list.
+func (b *Brewer) ListBeers(ctx context.Context, q BeerQuery) (*BeerPage, error) {
+	if q.Sort == "" {
+		q.Sort = SortByName
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	// fetch one more beer than asked to know if a next page exists
+	limit := q.Limit
+	q.Limit++
+
+	beers, err := b.BeerRepo.ListBeers(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list beers with query %+v: %w", q, err)
+	}
+
+	page := &BeerPage{Beers: beers}
+	if beers == nil {
+		page.Beers = []*Beer{}
+	}
+
+	if len(beers) > limit {
+		page.Beers = beers[:limit]
+		page.Next = NewCursor(page.Beers[limit-1], q.Sort, q.Desc)
+	}
+
+	return page, nil
+}
+
+func (b *Brewer) SearchBeers(ctx context.Context, s BeerSearch) ([]*BeerMatch, error) {
+	s.Text = strings.TrimSpace(s.Text)
+	if s.Limit == 0 {
+		s.Limit = DefaultPageSize
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	matches, err := b.BeerRepo.SearchBeers(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("unable to search beers matching %q: %w", s.Text, err)
+	}
+
+	if matches == nil {
+		matches = []*BeerMatch{}
+	}
+
+	return matches, nil
+}
@@ -3,10 +3,14 @@ package burp_test
 import (
 	"burp"
 	"burp/burptest"
+	"burp/repo"
+	"burp/repo/memory"
 	"burp/repo/repotest"
 	"context"
 	"errors"
+	"github.com/google/go-cmp/cmp"
 	"testing"
+	"time"
 )
 
 func TestSaveBeer(t *testing.T) {
@@ -53,8 +57,10 @@ func TestRemoveBeer(t *testing.T) {
 	beer := burptest.RandBeer()
 	spy := &repotest.BeerRemoverSpy{}
 	repo := repotest.Repo{BeerRemover: spy}
+	clock := burptest.Clock{Time: burptest.RandTime()}
 	brewer := &burp.Brewer{
 		BeerRepo: repo,
+		Clock:    clock,
 	}
 
 	err := brewer.RemoveBeer(context.Background(), beer.ID)
@@ -65,6 +71,10 @@ func TestRemoveBeer(t *testing.T) {
 	if spy.RemovedID != beer.ID {
 		t.Errorf("RemoveBeer(ctx, %+v) has not removed beer from repository", beer.ID)
 	}
+
+	if !spy.RemovedAt.Equal(clock.Time) {
+		t.Errorf("RemoveBeer(ctx, %+v) removed beer at %s, want %s", beer.ID, spy.RemovedAt, clock.Time)
+	}
 }
 
 func TestSelectBeerThatDoesNotExist(t *testing.T) {
@@ -93,3 +103,481 @@ func TestSelectBeer(t *testing.T) {
 		t.Errorf("SelectBeer(ctx, %+v) returned unexpected beer:\ngot %+v want %+v", stub.Beer.ID, got, stub.Beer)
 	}
 }
+
+func TestListBeers(t *testing.T) {
+	beers := []*burp.Beer{burptest.RandBeer(), burptest.RandBeer(), burptest.RandBeer()}
+	spy := &repotest.BeerListerSpy{Beers: beers}
+	repo := repotest.Repo{BeerLister: spy}
+	brewer := &burp.Brewer{BeerRepo: repo}
+	q := burp.BeerQuery{Sort: burp.SortByCreatedAt, Desc: true, Limit: 2}
+
+	page, err := brewer.ListBeers(context.Background(), q)
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, %+v) returned unexpected error:\ngot %v want nil", q, err)
+	}
+
+	if len(page.Beers) != 2 || page.Beers[0] != beers[0] || page.Beers[1] != beers[1] {
+		t.Errorf("ListBeers(ctx, %+v) returned unexpected beers:\ngot %+v want %+v", q, page.Beers, beers[:2])
+	}
+
+	want := burp.NewCursor(beers[1], q.Sort, q.Desc)
+	if page.Next == nil || *page.Next != *want {
+		t.Errorf("ListBeers(ctx, %+v) returned unexpected next cursor:\ngot %+v want %+v", q, page.Next, want)
+	}
+}
+
+func TestListBeersLastPage(t *testing.T) {
+	beers := []*burp.Beer{burptest.RandBeer(), burptest.RandBeer()}
+	spy := &repotest.BeerListerSpy{Beers: beers}
+	repo := repotest.Repo{BeerLister: spy}
+	brewer := &burp.Brewer{BeerRepo: repo}
+
+	page, err := brewer.ListBeers(context.Background(), burp.BeerQuery{})
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, {}) returned unexpected error:\ngot %v want nil", err)
+	}
+
+	if len(page.Beers) != len(beers) || page.Next != nil {
+		t.Errorf("ListBeers(ctx, {}) returned page %+v, want all beers and no next cursor", page)
+	}
+
+	if spy.Query.Sort != burp.SortByName || spy.Query.Limit != burp.DefaultPageSize+1 {
+		t.Errorf("ListBeers(ctx, {}) queried repository with %+v, want default sort and page size", spy.Query)
+	}
+}
+
+func TestListBeersOnRepoFailure(t *testing.T) {
+	stub := repotest.BeerListerErrStub
+	repo := repotest.Repo{BeerLister: stub}
+	brewer := &burp.Brewer{BeerRepo: repo}
+
+	_, err := brewer.ListBeers(context.Background(), burp.BeerQuery{})
+	if !errors.Is(err, stub.Err) {
+		t.Errorf("ListBeers(ctx, {}) returned unexpected error:\ngot %v want %v", err, stub.Err)
+	}
+}
+
+func TestSearchBeers(t *testing.T) {
+	matches := []*burp.BeerMatch{{Beer: burptest.RandBeer(), Score: 1}}
+	spy := &repotest.BeerSearcherSpy{Matches: matches}
+	repo := repotest.Repo{BeerSearcher: spy}
+	brewer := &burp.Brewer{BeerRepo: repo}
+	s := burp.BeerSearch{Text: " stout "}
+
+	got, err := brewer.SearchBeers(context.Background(), s)
+	if err != nil {
+		t.Fatalf("SearchBeers(ctx, %+v) returned unexpected error:\ngot %v want nil", s, err)
+	}
+
+	if len(got) != 1 || got[0] != matches[0] {
+		t.Errorf("SearchBeers(ctx, %+v) returned unexpected matches:\ngot %+v want %+v", s, got, matches)
+	}
+
+	want := burp.BeerSearch{Text: "stout", Limit: burp.DefaultPageSize}
+	if spy.Search != want {
+		t.Errorf("SearchBeers(ctx, %+v) searched repository with %+v, want %+v", s, spy.Search, want)
+	}
+}
+
+func TestSearchBeersWithoutText(t *testing.T) {
+	spy := &repotest.BeerSearcherSpy{}
+	repo := repotest.Repo{BeerSearcher: spy}
+	brewer := &burp.Brewer{BeerRepo: repo}
+	s := burp.BeerSearch{Text: "  "}
+
+	_, err := brewer.SearchBeers(context.Background(), s)
+	if !errors.Is(err, burp.ErrSearchTextMissing) {
+		t.Errorf("SearchBeers(ctx, %+v) returned unexpected error:\ngot %v want %v", s, err, burp.ErrSearchTextMissing)
+	}
+}
+
+func TestSearchBeersOnRepoFailure(t *testing.T) {
+	stub := repotest.BeerSearcherErrStub
+	repo := repotest.Repo{BeerSearcher: stub}
+	brewer := &burp.Brewer{BeerRepo: repo}
+	s := burp.BeerSearch{Text: burptest.RandString(5)}
+
+	_, err := brewer.SearchBeers(context.Background(), s)
+	if !errors.Is(err, stub.Err) {
+		t.Errorf("SearchBeers(ctx, %+v) returned unexpected error:\ngot %v want %v", s, err, stub.Err)
+	}
+}
+
+func TestRestoreBeer(t *testing.T) {
+	beer := burptest.RandBeer()
+	spy := &repotest.BeerRestorerSpy{}
+	repo := repotest.Repo{BeerRestorer: spy}
+	brewer := &burp.Brewer{BeerRepo: repo}
+
+	err := brewer.RestoreBeer(context.Background(), beer.ID)
+	if err != nil {
+		t.Errorf("RestoreBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer.ID, err)
+	}
+
+	if spy.RestoredID != beer.ID {
+		t.Errorf("RestoreBeer(ctx, %+v) has not restored beer in repository", beer.ID)
+	}
+}
+
+func TestRestoreBeerNotInTrash(t *testing.T) {
+	beer := burptest.RandBeer()
+	stub := repotest.BeerRestorerNotFoundStub
+	repo := repotest.Repo{BeerRestorer: stub}
+	brewer := &burp.Brewer{BeerRepo: repo}
+
+	err := brewer.RestoreBeer(context.Background(), beer.ID)
+	if !errors.Is(err, stub.Err) {
+		t.Errorf("RestoreBeer(ctx, %q) returned unexpected error:\ngot %v want %v", beer.ID, err, stub.Err)
+	}
+}
+
+func TestPurgeBeer(t *testing.T) {
+	beer := burptest.RandBeer()
+	spy := &repotest.BeerPurgerSpy{}
+	repo := repotest.Repo{BeerPurger: spy}
+	brewer := &burp.Brewer{BeerRepo: repo}
+
+	err := brewer.PurgeBeer(context.Background(), beer.ID)
+	if err != nil {
+		t.Errorf("PurgeBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer.ID, err)
+	}
+
+	if spy.PurgedID != beer.ID {
+		t.Errorf("PurgeBeer(ctx, %+v) has not purged beer from repository", beer.ID)
+	}
+}
+
+func TestPurgeBeerNotInTrash(t *testing.T) {
+	beer := burptest.RandBeer()
+	stub := repotest.BeerPurgerNotFoundStub
+	repo := repotest.Repo{BeerPurger: stub}
+	brewer := &burp.Brewer{BeerRepo: repo}
+
+	err := brewer.PurgeBeer(context.Background(), beer.ID)
+	if !errors.Is(err, stub.Err) {
+		t.Errorf("PurgeBeer(ctx, %q) returned unexpected error:\ngot %v want %v", beer.ID, err, stub.Err)
+	}
+}
+
+func TestSaveBeerStampsTimestamps(t *testing.T) {
+	beer := burptest.RandBeer()
+	clock := burptest.Clock{Time: burptest.RandTime()}
+	spy := &repotest.BeerSaverSpy{}
+	repo := repotest.Repo{BeerSaver: spy}
+	brewer := &burp.Brewer{BeerRepo: repo, Clock: clock}
+
+	err := brewer.SaveBeer(context.Background(), beer)
+	if err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer, err)
+	}
+
+	if !spy.BeerSaved.CreatedAt.Equal(clock.Time) || !spy.BeerSaved.UpdatedAt.Equal(clock.Time) {
+		t.Errorf("SaveBeer(ctx, %+v) did not stamp beer with clock time %s", beer, clock.Time)
+	}
+}
+
+func TestSaveInvalidBeer(t *testing.T) {
+	beer := burptest.RandBeer()
+	beer.Name = ""
+	spy := &repotest.BeerSaverSpy{}
+	repo := repotest.Repo{BeerSaver: spy}
+	brewer := &burp.Brewer{BeerRepo: repo}
+
+	err := brewer.SaveBeer(context.Background(), beer)
+	if !errors.Is(err, burp.ErrBeerNameMissing) {
+		t.Errorf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want %v", beer, err, burp.ErrBeerNameMissing)
+	}
+
+	if spy.BeerSaved != nil {
+		t.Errorf("SaveBeer(ctx, %+v) saved invalid beer in repo", beer)
+	}
+}
+
+func TestSaveBeerRecordsEvents(t *testing.T) {
+	beer := burptest.RandBeer()
+	before := *beer
+	before.Version = 1
+	events := &repotest.BeerEventRepoSpy{}
+	repo := repotest.Repo{
+		BeerSaver:    &repotest.BeerSaverSpy{},
+		BeerSelector: &repotest.BeerSelectorSpy{Beer: &before},
+	}
+	brewer := &burp.Brewer{BeerRepo: repo, EventRepo: events}
+	ctx := burp.WithActor(context.Background(), "brewmaster")
+
+	if err := brewer.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer, err)
+	}
+
+	beer.Version = 1
+	if err := brewer.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer, err)
+	}
+
+	if len(events.Events) != 2 {
+		t.Fatalf("SaveBeer(ctx, %+v) twice recorded %d events, want 2", beer, len(events.Events))
+	}
+
+	created, updated := events.Events[0], events.Events[1]
+	if created.Kind != burp.BeerCreated || created.Before != nil || created.After == nil || created.BeerID != beer.ID {
+		t.Errorf("SaveBeer(ctx, %+v) of a new beer recorded unexpected event %+v", beer, created)
+	}
+
+	if updated.Kind != burp.BeerUpdated || !cmp.Equal(updated.Before, &before) || !cmp.Equal(updated.After, beer) {
+		t.Errorf("SaveBeer(ctx, %+v) of an existing beer recorded unexpected event %+v", beer, updated)
+	}
+
+	if updated.Actor != "brewmaster" {
+		t.Errorf("SaveBeer(ctx, %+v) recorded actor %q, want %q", beer, updated.Actor, "brewmaster")
+	}
+}
+
+func TestRemoveBeerRecordsEvent(t *testing.T) {
+	beer := burptest.RandBeer()
+	events := &repotest.BeerEventRepoSpy{}
+	repo := repotest.Repo{
+		BeerRemover:  &repotest.BeerRemoverSpy{},
+		BeerSelector: &repotest.BeerSelectorSpy{Beer: beer},
+	}
+	brewer := &burp.Brewer{BeerRepo: repo, EventRepo: events}
+
+	if err := brewer.RemoveBeer(context.Background(), beer.ID); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer.ID, err)
+	}
+
+	if len(events.Events) != 1 {
+		t.Fatalf("RemoveBeer(ctx, %+v) recorded %d events, want 1", beer.ID, len(events.Events))
+	}
+
+	if e := events.Events[0]; e.Kind != burp.BeerDeleted || !cmp.Equal(e.Before, beer) || e.After != nil {
+		t.Errorf("RemoveBeer(ctx, %+v) recorded unexpected event %+v", beer.ID, e)
+	}
+}
+
+func TestSaveBeerOnEventRepoFailure(t *testing.T) {
+	beer := burptest.RandBeer()
+	stub := repotest.BeerEventRepoErrStub
+	repo := repotest.Repo{BeerSaver: &repotest.BeerSaverSpy{}}
+	brewer := &burp.Brewer{BeerRepo: repo, EventRepo: stub}
+
+	err := brewer.SaveBeer(context.Background(), beer)
+	if !errors.Is(err, stub.Err) {
+		t.Errorf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want %v", beer, err, stub.Err)
+	}
+}
+
+func TestSaveBeerInTxOnEventRepoFailure(t *testing.T) {
+	ctx := context.Background()
+	beer := burptest.RandBeer()
+	stub := repotest.BeerEventRepoErrStub
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo, EventRepo: stub, Tx: memRepo}
+
+	err := brewer.SaveBeer(ctx, beer)
+	if !errors.Is(err, stub.Err) {
+		t.Errorf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want %v", beer, err, stub.Err)
+	}
+
+	if _, err := memRepo.SelectBeer(ctx, beer.ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("SelectBeer(ctx, %s) after failed SaveBeer returned error %v, want %v", beer.ID, err, repo.ErrNotFound)
+	}
+}
+
+func TestBeerHistory(t *testing.T) {
+	beer := burptest.RandBeer()
+	events := &repotest.BeerEventRepoSpy{Events: []*burp.BeerEvent{
+		{BeerID: beer.ID, Kind: burp.BeerCreated},
+		{BeerID: burptest.RandBeer().ID, Kind: burp.BeerCreated},
+	}}
+	brewer := &burp.Brewer{EventRepo: events}
+
+	got, err := brewer.BeerHistory(context.Background(), beer.ID)
+	if err != nil {
+		t.Fatalf("BeerHistory(ctx, %+v) returned unexpected error:\ngot %v want nil", beer.ID, err)
+	}
+
+	if len(got) != 1 || got[0] != events.Events[0] {
+		t.Errorf("BeerHistory(ctx, %+v) returned unexpected events %+v", beer.ID, got)
+	}
+}
+
+func TestSaveBeerRecordsPriceChanges(t *testing.T) {
+	beer := burptest.RandBeer()
+	before := *beer
+	before.Version = 1
+	prices := &repotest.PriceRepoSpy{}
+	repo := repotest.Repo{
+		BeerSaver:    &repotest.BeerSaverSpy{},
+		BeerSelector: &repotest.BeerSelectorSpy{Beer: &before},
+	}
+	brewer := &burp.Brewer{BeerRepo: repo, PriceRepo: prices}
+
+	if err := brewer.SaveBeer(context.Background(), beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer, err)
+	}
+
+	beer.Version = 1
+	if err := brewer.SaveBeer(context.Background(), beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer, err)
+	}
+
+	if len(prices.Changes) != 1 {
+		t.Fatalf("SaveBeer(ctx, %+v) with an unchanged price recorded %d price changes, want 1", beer, len(prices.Changes))
+	}
+
+	beer.Price.Amount++
+	if err := brewer.SaveBeer(context.Background(), beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want nil", beer, err)
+	}
+
+	if len(prices.Changes) != 2 || prices.Changes[1].Price != beer.Price {
+		t.Errorf("SaveBeer(ctx, %+v) with a new price recorded unexpected price changes %+v", beer, prices.Changes)
+	}
+}
+
+func TestSchedulePrice(t *testing.T) {
+	now := burptest.RandTime()
+	beer := burptest.RandBeer()
+	prices := &repotest.PriceRepoSpy{Changes: []*burp.PriceChange{{BeerID: beer.ID, Price: beer.Price, From: beer.UpdatedAt}}}
+	repo := repotest.Repo{BeerSelector: &repotest.BeerSelectorSpy{Beer: beer}}
+	brewer := &burp.Brewer{BeerRepo: repo, PriceRepo: prices, Clock: burptest.Clock{Time: now}}
+
+	tests := []struct {
+		description string
+		from        time.Time
+		err         error
+	}{
+		{description: "in the future", from: now.Add(time.Hour)},
+		{description: "now", from: now, err: burp.ErrPriceChangeNotInFuture},
+		{description: "in the past", from: now.Add(-time.Hour), err: burp.ErrPriceChangeNotInFuture},
+		{description: "without date", err: burp.ErrPriceChangeDateMissing},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			change := &burp.PriceChange{BeerID: beer.ID, Price: beer.Price, From: test.from}
+
+			err := brewer.SchedulePrice(context.Background(), change)
+			if !errors.Is(err, test.err) {
+				t.Errorf("SchedulePrice(ctx, %+v) returned unexpected error:\ngot %v want %v", change, err, test.err)
+			}
+		})
+	}
+
+	if len(prices.Changes) != 2 {
+		t.Errorf("SchedulePrice saved %d price changes besides the recorded one, want 1", len(prices.Changes)-1)
+	}
+}
+
+func TestSchedulePriceWithoutPriceHistory(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	now := burptest.RandTime()
+	brewer := &burp.Brewer{BeerRepo: memRepo, PriceRepo: memRepo, OrderRepo: memRepo, Tx: memRepo, Clock: burptest.Clock{Time: now}}
+
+	// saved straight to the repo, as beers were before prices were recorded
+	beer := burptest.RandBeer()
+	beer.UpdatedAt = now.Add(-time.Hour)
+	if err := memRepo.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	scheduled := burp.Price{Currency: beer.Price.Currency, Amount: beer.Price.Amount + 1}
+	change := &burp.PriceChange{BeerID: beer.ID, Price: scheduled, From: now.Add(time.Hour)}
+	if err := brewer.SchedulePrice(ctx, change); err != nil {
+		t.Fatalf("SchedulePrice(ctx, %+v) returned unexpected error %s", change, err)
+	}
+
+	got, err := brewer.PriceAt(ctx, beer.ID, now)
+	if err != nil || *got != beer.Price {
+		t.Errorf("PriceAt(ctx, %s, now) before a scheduled price returned %+v, %v, want %+v", beer.ID, got, err, beer.Price)
+	}
+
+	got, err = brewer.PriceAt(ctx, beer.ID, change.From)
+	if err != nil || *got != scheduled {
+		t.Errorf("PriceAt(ctx, %s, from) of a scheduled price returned %+v, %v, want %+v", beer.ID, got, err, scheduled)
+	}
+
+	cart := &burp.Cart{Customer: "Ann", Items: []burp.CartItem{{BeerID: beer.ID, Quantity: 1}}}
+	order, err := brewer.Checkout(ctx, cart)
+	if err != nil {
+		t.Fatalf("Checkout(ctx, %+v) before a scheduled price returned unexpected error %s", cart, err)
+	}
+
+	if price := order.Lines[0].Price; price != beer.Price {
+		t.Errorf("Checkout(ctx, %+v) before a scheduled price ordered at %+v, want %+v", cart, price, beer.Price)
+	}
+}
+
+func TestSchedulePriceOfMissingBeer(t *testing.T) {
+	now := burptest.RandTime()
+	stub := repotest.BeerSelectorNotFoundStub
+	repo := repotest.Repo{BeerSelector: stub}
+	brewer := &burp.Brewer{BeerRepo: repo, PriceRepo: &repotest.PriceRepoSpy{}, Clock: burptest.Clock{Time: now}}
+	change := &burp.PriceChange{BeerID: burptest.RandBeer().ID, Price: burptest.RandBeer().Price, From: now.Add(time.Hour)}
+
+	err := brewer.SchedulePrice(context.Background(), change)
+	if !errors.Is(err, stub.Err) {
+		t.Errorf("SchedulePrice(ctx, %+v) returned unexpected error:\ngot %v want %v", change, err, stub.Err)
+	}
+}
+
+func TestPriceHistory(t *testing.T) {
+	beer := burptest.RandBeer()
+	from := burptest.RandTime()
+	until := from.Add(time.Hour)
+	prices := &repotest.PriceRepoSpy{Changes: []*burp.PriceChange{
+		{BeerID: beer.ID, Price: burp.Price{Currency: burp.EUR, Amount: 100}, From: from},
+		{BeerID: beer.ID, Price: burp.Price{Currency: burp.EUR, Amount: 200}, From: until},
+	}}
+	brewer := &burp.Brewer{PriceRepo: prices}
+
+	got, err := brewer.PriceHistory(context.Background(), beer.ID)
+	if err != nil {
+		t.Fatalf("PriceHistory(ctx, %+v) returned unexpected error:\ngot %v want nil", beer.ID, err)
+	}
+
+	want := []*burp.PricePeriod{
+		{Price: burp.Price{Currency: burp.EUR, Amount: 100}, From: from, Until: &until},
+		{Price: burp.Price{Currency: burp.EUR, Amount: 200}, From: until},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("PriceHistory(ctx, %+v) returned unexpected periods, (-want/+got):\n%s", beer.ID, diff)
+	}
+
+	at, err := brewer.PriceAt(context.Background(), beer.ID, until.Add(-time.Second))
+	if err != nil || *at != want[0].Price {
+		t.Errorf("PriceAt(ctx, %+v, %s) returned %+v, %v, want %+v", beer.ID, until, at, err, want[0].Price)
+	}
+}
+
+func TestPriceAtWithoutPriceHistory(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo, PriceRepo: memRepo}
+
+	// saved straight to the repo, as beers were before prices were recorded
+	beer := burptest.RandBeer()
+	if err := memRepo.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	got, err := brewer.PriceAt(ctx, beer.ID, burptest.RandTime())
+	if err != nil || *got != beer.Price {
+		t.Errorf("PriceAt(ctx, %s, at) of a beer without price history returned %+v, %v, want %+v", beer.ID, got, err, beer.Price)
+	}
+
+	from := time.Now().UTC().Add(time.Hour)
+	change := &burp.PriceChange{BeerID: beer.ID, Price: beer.Price, From: from}
+	if err := memRepo.SavePriceChange(ctx, change); err != nil {
+		t.Fatalf("SavePriceChange(ctx, %+v) returned unexpected error %s", change, err)
+	}
+
+	if _, err := brewer.PriceAt(ctx, beer.ID, from.Add(-time.Second)); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("PriceAt(ctx, %s, at) before its first price returned unexpected error:\ngot %v want %v", beer.ID, err, repo.ErrNotFound)
+	}
+
+	if _, err := brewer.PriceAt(ctx, burptest.RandBeer().ID, from); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("PriceAt(ctx, id, at) of a missing beer returned unexpected error:\ngot %v want %v", err, repo.ErrNotFound)
+	}
+}
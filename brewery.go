@@ -0,0 +1,234 @@
+package burp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrBreweryNameMissing    = Error("brewery name is missing")
+	ErrBreweryNameTooLong    = Errorf("brewery name exceed %d character", MaxBreweryLength)
+	ErrBreweryPlaceTooLong   = Errorf("brewery country and city must not exceed %d character", MaxBreweryLength)
+	ErrBreweryNotFound       = Error("brewery not found")
+	ErrBreweryHasBeers       = Error("brewery still has beers, in trash included")
+	ErrBreweriesUnavailable  = Error("breweries are not recorded")
+	ErrBreweryCursorInvalid  = Error("brewery cursor must sort by name")
+	ErrBreweryNotInBeerQuery = Error("brewery of listed beers is set by path")
+)
+
+// Brewery brews beers, which refer to it by BreweryID.
+type Brewery struct {
+	ID        ID        `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version is incremented on every save, as for beers.
+	Version uint `json:"version"`
+
+	Name    string `json:"name"`
+	Country string `json:"country,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+type BreweryRepo interface {
+	BrewerySaver
+	BrewerySelector
+	BreweryLister
+	BreweryRemover
+}
+
+// BrewerySaver saves a brewery as BeerSaver does a beer,
+// at the version it was read.
+type BrewerySaver interface {
+	SaveBrewery(ctx context.Context, brewery *Brewery) error
+}
+
+type BrewerySelector interface {
+	SelectBrewery(ctx context.Context, id ID) (*Brewery, error)
+}
+
+// BreweryLister lists at most q.Limit breweries whose name contains
+// q.Name, ordered by name then id, following q.After when set.
+type BreweryLister interface {
+	ListBreweries(ctx context.Context, q BreweryQuery) ([]*Brewery, error)
+}
+
+// BreweryRemover deletes a brewery permanently.
+type BreweryRemover interface {
+	RemoveBrewery(ctx context.Context, id ID) error
+}
+
+// BreweryQuery describes a page of breweries to list.
+type BreweryQuery struct {
+	// Name matches breweries whose name contains it, case-insensitively.
+	Name  string
+	Limit int
+
+	// After is the cursor of the previous page, nil for the first one.
+	// Breweries are sorted by name, as beers with SortByName.
+	After *Cursor
+}
+
+type BreweryPage struct {
+	Breweries []*Brewery `json:"items"`
+	Next      *Cursor    `json:"next,omitempty"`
+}
+
+// Less reports whether brewery a is listed before brewery b.
+func (q *BreweryQuery) Less(a, b *Brewery) bool {
+	return breweryBefore(a.Name, a.ID, b.Name, b.ID)
+}
+
+// Follows reports whether brewery is listed after the query cursor.
+func (q *BreweryQuery) Follows(b *Brewery) bool {
+	if q.After == nil {
+		return true
+	}
+	return breweryBefore(q.After.Key, q.After.ID, b.Name, b.ID)
+}
+
+func breweryBefore(nameA string, idA ID, nameB string, idB ID) bool {
+	if nameA == nameB {
+		return idA.String() < idB.String()
+	}
+	return nameA < nameB
+}
+
+func (b *Brewer) SaveBrewery(ctx context.Context, brewery *Brewery) error {
+	if b.BreweryRepo == nil {
+		return ErrBreweriesUnavailable
+	}
+
+	now := b.now()
+	brewery.CreatedAt = now
+	brewery.UpdatedAt = now
+
+	if err := brewery.Validate(); err != nil {
+		return err
+	}
+
+	if err := b.BreweryRepo.SaveBrewery(ctx, brewery); err != nil {
+		return fmt.Errorf("unable to save brewery %+v: %w", brewery, err)
+	}
+
+	return nil
+}
+
+func (b *Brewer) SelectBrewery(ctx context.Context, id ID) (*Brewery, error) {
+	if b.BreweryRepo == nil {
+		return nil, ErrBreweriesUnavailable
+	}
+
+	brewery, err := b.BreweryRepo.SelectBrewery(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select brewery %q: %w", id, err)
+	}
+
+	return brewery, nil
+}
+
+// ListBreweries returns a page of breweries sorted by name, along
+// with the cursor of the next page when there are breweries left.
+func (b *Brewer) ListBreweries(ctx context.Context, q BreweryQuery) (*BreweryPage, error) {
+	if b.BreweryRepo == nil {
+		return nil, ErrBreweriesUnavailable
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	// fetch one more brewery than asked to know if a next page exists
+	limit := q.Limit
+	q.Limit++
+
+	breweries, err := b.BreweryRepo.ListBreweries(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list breweries with query %+v: %w", q, err)
+	}
+
+	page := &BreweryPage{Breweries: breweries}
+	if breweries == nil {
+		page.Breweries = []*Brewery{}
+	}
+
+	if len(breweries) > limit {
+		page.Breweries = breweries[:limit]
+		last := page.Breweries[limit-1]
+		page.Next = &Cursor{Sort: SortByName, Key: last.Name, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// RemoveBrewery deletes a brewery, which must no longer have beers.
+func (b *Brewer) RemoveBrewery(ctx context.Context, id ID) error {
+	if b.BreweryRepo == nil {
+		return ErrBreweriesUnavailable
+	}
+
+	return b.inTx(ctx, func(ctx context.Context) error {
+		for _, deleted := range []bool{false, true} {
+			q := BeerQuery{Filter: BeerFilter{BreweryID: id, Deleted: deleted}, Sort: SortByName, Limit: 1}
+			beers, err := b.BeerRepo.ListBeers(ctx, q)
+			if err != nil {
+				return fmt.Errorf("unable to list beers of brewery %q: %w", id, err)
+			}
+
+			if len(beers) > 0 {
+				return ErrBreweryHasBeers
+			}
+		}
+
+		if err := b.BreweryRepo.RemoveBrewery(ctx, id); err != nil {
+			return fmt.Errorf("unable to remove brewery %q: %w", id, err)
+		}
+
+		return nil
+	})
+}
+
+// ListBreweryBeers returns a page of beers of a brewery, as ListBeers
+// does. q.Filter.BreweryID must be unset, as it is set to id.
+func (b *Brewer) ListBreweryBeers(ctx context.Context, id ID, q BeerQuery) (*BeerPage, error) {
+	if q.Filter.BreweryID.UUID != uuid.Nil {
+		return nil, ErrBreweryNotInBeerQuery
+	}
+
+	if _, err := b.SelectBrewery(ctx, id); err != nil {
+		return nil, err
+	}
+
+	q.Filter.BreweryID = id
+	return b.ListBeers(ctx, q)
+}
+
+// checkBrewery fails with ErrBreweryNotFound
+// unless beer has no brewery or a stored one.
+func (b *Brewer) checkBrewery(ctx context.Context, beer *Beer) error {
+	if beer.BreweryID == nil {
+		return nil
+	}
+
+	if b.BreweryRepo == nil {
+		return ErrBreweriesUnavailable
+	}
+
+	_, err := b.BreweryRepo.SelectBrewery(ctx, *beer.BreweryID)
+	if errors.Is(err, ErrNotFound) {
+		return Errorf("invalid brewery id %q: %w", beer.BreweryID, ErrBreweryNotFound)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to select brewery %q of beer: %w", beer.BreweryID, err)
+	}
+
+	return nil
+}
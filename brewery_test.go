@@ -0,0 +1,140 @@
+package burp_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo"
+	"burp/repo/memory"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSaveBeerOfUnknownBrewery(t *testing.T) {
+	beer := burptest.RandBeer()
+	beer.BreweryID = &burptest.RandBrewery().ID
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo, BreweryRepo: memRepo}
+
+	err := brewer.SaveBeer(context.Background(), beer)
+	if !errors.Is(err, burp.ErrBreweryNotFound) || !errors.As(err, &burp.Err{}) {
+		t.Errorf("SaveBeer(ctx, %+v) returned unexpected error:\ngot %v want %v", beer, err, burp.ErrBreweryNotFound)
+	}
+}
+
+func TestSaveBeerOfStoredBreweryClearsBreweryName(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo, BreweryRepo: memRepo}
+
+	brewery := burptest.RandBrewery()
+	if err := brewer.SaveBrewery(ctx, brewery); err != nil {
+		t.Fatalf("SaveBrewery(ctx, %+v) returned unexpected error %s", brewery, err)
+	}
+
+	beer := burptest.RandBeer()
+	beer.BreweryID = &brewery.ID
+	if err := brewer.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	got, err := brewer.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if got.Brewery != "" {
+		t.Errorf("SelectBeer(ctx, %s) returned beer of brewery %q, want none as it refers to brewery %s", beer.ID, got.Brewery, brewery.ID)
+	}
+}
+
+func TestRemoveBreweryWithBeers(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo, BreweryRepo: memRepo}
+
+	brewery := burptest.RandBrewery()
+	if err := brewer.SaveBrewery(ctx, brewery); err != nil {
+		t.Fatalf("SaveBrewery(ctx, %+v) returned unexpected error %s", brewery, err)
+	}
+
+	beer := burptest.RandBeer()
+	beer.BreweryID = &brewery.ID
+	if err := brewer.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	// a beer in trash still belongs to the brewery
+	if err := brewer.RemoveBeer(ctx, beer.ID); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	err := brewer.RemoveBrewery(ctx, brewery.ID)
+	if !errors.Is(err, burp.ErrBreweryHasBeers) {
+		t.Errorf("RemoveBrewery(ctx, %s) with beers returned unexpected error:\ngot %v want %v", brewery.ID, err, burp.ErrBreweryHasBeers)
+	}
+
+	if err := brewer.PurgeBeer(ctx, beer.ID); err != nil {
+		t.Fatalf("PurgeBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if err := brewer.RemoveBrewery(ctx, brewery.ID); err != nil {
+		t.Errorf("RemoveBrewery(ctx, %s) without beers returned unexpected error %s", brewery.ID, err)
+	}
+}
+
+func TestListBreweries(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BreweryRepo: memRepo}
+
+	breweries := make([]*burp.Brewery, 3)
+	for i := range breweries {
+		breweries[i] = burptest.RandBrewery()
+		breweries[i].Name = fmt.Sprintf("brewery %d", i)
+		if err := brewer.SaveBrewery(ctx, breweries[i]); err != nil {
+			t.Fatalf("SaveBrewery(ctx, %+v) returned unexpected error %s", breweries[i], err)
+		}
+	}
+
+	q := burp.BreweryQuery{Limit: 2}
+	page, err := brewer.ListBreweries(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBreweries(ctx, %+v) returned unexpected error %s", q, err)
+	}
+
+	if len(page.Breweries) != 2 || page.Breweries[1].ID != breweries[1].ID {
+		t.Errorf("ListBreweries(ctx, %+v) returned unexpected breweries %+v", q, page.Breweries)
+	}
+
+	q.After = page.Next
+	page, err = brewer.ListBreweries(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBreweries(ctx, %+v) returned unexpected error %s", q, err)
+	}
+
+	if len(page.Breweries) != 1 || page.Breweries[0].ID != breweries[2].ID || page.Next != nil {
+		t.Errorf("ListBreweries(ctx, %+v) returned unexpected last page %+v", q, page)
+	}
+}
+
+func TestListBreweryBeersOfMissingBrewery(t *testing.T) {
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo, BreweryRepo: memRepo}
+	id := burptest.RandBrewery().ID
+
+	_, err := brewer.ListBreweryBeers(context.Background(), id, burp.BeerQuery{})
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("ListBreweryBeers(ctx, %s, {}) of a missing brewery returned unexpected error:\ngot %v want %v", id, err, repo.ErrNotFound)
+	}
+}
+
+func TestBreweriesUnavailable(t *testing.T) {
+	brewer := &burp.Brewer{}
+
+	_, err := brewer.SelectBrewery(context.Background(), burptest.RandBrewery().ID)
+	if !errors.Is(err, burp.ErrBreweriesUnavailable) {
+		t.Errorf("SelectBrewery(ctx, id) without BreweryRepo returned unexpected error:\ngot %v want %v", err, burp.ErrBreweriesUnavailable)
+	}
+}
@@ -0,0 +1,221 @@
+package burp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+)
+
+// MaxImportRows bounds beers imported with ImportAll,
+// which are all held in memory until saved.
+const MaxImportRows = 10000
+
+var (
+	ErrImportModeNotSupported = Error("import mode not supported")
+	ErrImportTooLarge         = Errorf("import of all beers at once exceed %d rows", MaxImportRows)
+)
+
+// BeerDecoder decodes beers one at a time, until io.EOF. A beer which
+// cannot be decoded is reported by a *RowError, after which decoding
+// goes on with the next one. Any other error stops decoding.
+type BeerDecoder interface {
+	Decode() (*Beer, error)
+
+	// Row returns the row of the last decoded beer, counting from 1.
+	Row() int
+}
+
+// BeerEncoder encodes beers one at a time. Flush
+// writes encoded beers, along with any header.
+type BeerEncoder interface {
+	Encode(beer *Beer) error
+	Flush() error
+}
+
+// ImportMode tells what becomes of valid beers when others are not.
+type ImportMode string
+
+const (
+	// ImportEach saves every valid beer.
+	ImportEach ImportMode = "each"
+
+	// ImportAll saves all beers at once, none if one is invalid.
+	ImportAll ImportMode = "all"
+)
+
+func (m ImportMode) Validate() error {
+	if m != ImportEach && m != ImportAll {
+		return ErrImportModeNotSupported
+	}
+	return nil
+}
+
+// RowError tells why the beer of a row was not imported.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string { return fmt.Sprintf("row %d: %s", e.Row, e.Err) }
+func (e *RowError) Unwrap() error { return e.Err }
+
+func (e *RowError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Row   int    `json:"row"`
+		Error string `json:"error"`
+	}{e.Row, e.Err.Error()})
+}
+
+type ImportReport struct {
+	Imported int         `json:"imported"`
+	Errors   []*RowError `json:"errors"`
+}
+
+// ImportBeers saves beers decoded by dec as SaveBeer does. Beers without
+// id are created with a new one, those with a version update stored ones.
+//
+// Invalid beers, or those in conflict with stored ones, are reported
+// by row. With ImportEach, other beers are still saved. With ImportAll,
+// none is unless all are valid, and a conflict fails the whole import.
+func (b *Brewer) ImportBeers(ctx context.Context, dec BeerDecoder, mode ImportMode) (*ImportReport, error) {
+	if err := mode.Validate(); err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Errors: []*RowError{}}
+	var beers []*Beer
+
+	for {
+		beer, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			report.Errors = append(report.Errors, rowErr)
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode beers: %w", err)
+		}
+
+		if beer.ID.UUID == uuid.Nil {
+			beer.ID = ID{UUID: uuid.New()}
+		}
+
+		if mode == ImportAll {
+			if len(beers) == MaxImportRows {
+				return nil, ErrImportTooLarge
+			}
+
+			b.stamp(beer)
+			if err := beer.Validate(); err != nil {
+				report.Errors = append(report.Errors, &RowError{Row: dec.Row(), Err: err})
+				continue
+			}
+
+			err := b.checkBrewery(ctx, beer)
+			if errors.As(err, &Err{}) {
+				report.Errors = append(report.Errors, &RowError{Row: dec.Row(), Err: err})
+				continue
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("unable to import row %d: %w", dec.Row(), err)
+			}
+
+			beers = append(beers, beer)
+			continue
+		}
+
+		err = b.SaveBeer(ctx, beer)
+		if errors.As(err, &Err{}) {
+			report.Errors = append(report.Errors, &RowError{Row: dec.Row(), Err: err})
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to import row %d: %w", dec.Row(), err)
+		}
+
+		report.Imported++
+	}
+
+	if mode == ImportAll && len(report.Errors) == 0 {
+		if err := b.saveAll(ctx, beers); err != nil {
+			return nil, err
+		}
+		report.Imported = len(beers)
+	}
+
+	return report, nil
+}
+
+// saveAll saves stamped and valid beers at once,
+// then records their changes, in a single transaction.
+func (b *Brewer) saveAll(ctx context.Context, beers []*Beer) error {
+	return b.inTx(ctx, func(ctx context.Context) error {
+		kinds := make([]BeerEventKind, len(beers))
+		befores := make([]*Beer, len(beers))
+
+		for i, beer := range beers {
+			if err := b.checkBrewery(ctx, beer); err != nil {
+				return err
+			}
+
+			var err error
+			if kinds[i], befores[i], err = b.prior(ctx, beer); err != nil {
+				return fmt.Errorf("unable to save beer %+v: %w", beer, err)
+			}
+		}
+
+		if err := b.BeerRepo.SaveBeers(ctx, beers); err != nil {
+			return fmt.Errorf("unable to save %d beers: %w", len(beers), err)
+		}
+
+		for i, beer := range beers {
+			if err := b.recordSave(ctx, kinds[i], befores[i], beer); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ExportBeers encodes beers matching f, sorted by name. They are
+// listed and flushed page by page, so that any number is streamed.
+func (b *Brewer) ExportBeers(ctx context.Context, f BeerFilter, enc BeerEncoder) error {
+	q := BeerQuery{Filter: f, Sort: SortByName, Limit: MaxPageSize}
+	if err := q.Validate(); err != nil {
+		return err
+	}
+
+	for {
+		beers, err := b.BeerRepo.ListBeers(ctx, q)
+		if err != nil {
+			return fmt.Errorf("unable to list beers with query %+v: %w", q, err)
+		}
+
+		for _, beer := range beers {
+			if err := enc.Encode(beer); err != nil {
+				return fmt.Errorf("unable to export beer %q: %w", beer.ID, err)
+			}
+		}
+
+		if err := enc.Flush(); err != nil {
+			return fmt.Errorf("unable to export beers: %w", err)
+		}
+
+		if len(beers) < q.Limit {
+			return nil
+		}
+
+		q.After = NewCursor(beers[len(beers)-1], q.Sort, q.Desc)
+	}
+}
@@ -0,0 +1,154 @@
+package burp_test
+
+import (
+	"burp"
+	"burp/beerio"
+	"burp/burptest"
+	"burp/repo/memory"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestImportBeers(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: repo}
+	stored := burptest.RandBeer()
+	if err := repo.SaveBeer(ctx, stored); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", stored, err)
+	}
+
+	input := "id,version,name,currency,amount\n" +
+		",,Kwak,Euro,350\n" +
+		fmt.Sprintf("%s,1,Renamed,Euro,300\n", stored.ID) +
+		",,Name exceeding fifteen,Euro,300\n" +
+		fmt.Sprintf("%s,1,Stale,Euro,300\n", stored.ID) +
+		",,Chimay,Euro,ten\n"
+
+	report, err := brewer.ImportBeers(ctx, beerio.NewCSVDecoder(strings.NewReader(input)), burp.ImportEach)
+	if err != nil {
+		t.Fatalf("ImportBeers(ctx, dec, each) returned unexpected error %s", err)
+	}
+
+	if report.Imported != 2 {
+		t.Errorf("ImportBeers(ctx, dec, each) imported %d beers, want 2", report.Imported)
+	}
+
+	var rows []int
+	for _, err := range report.Errors {
+		rows = append(rows, err.Row)
+	}
+	if diff := cmp.Diff([]int{4, 5, 6}, rows); diff != "" {
+		t.Errorf("ImportBeers(ctx, dec, each) reported errors of unexpected rows, (-want/+got):\n%s", diff)
+	}
+
+	if !errors.Is(report.Errors[1], burp.ErrVersionConflict) {
+		t.Errorf("ImportBeers(ctx, dec, each) reported error %v of a stale row, want %v", report.Errors[1], burp.ErrVersionConflict)
+	}
+
+	got, err := repo.SelectBeer(ctx, stored.ID)
+	if err != nil || got.Name != "Renamed" || got.Version != 2 {
+		t.Errorf("SelectBeer(ctx, %s) after import returned %+v, %v, want it renamed at version 2", stored.ID, got, err)
+	}
+}
+
+func TestImportAllBeers(t *testing.T) {
+	ctx := context.Background()
+	brewer := &burp.Brewer{BeerRepo: memory.New()}
+	valid := "name,currency,amount\nKwak,Euro,350\nChimay,Euro,300\n"
+
+	report, err := brewer.ImportBeers(ctx, beerio.NewCSVDecoder(strings.NewReader(valid+"Orval,Yen,400\n")), burp.ImportAll)
+	if err != nil {
+		t.Fatalf("ImportBeers(ctx, dec, all) returned unexpected error %s", err)
+	}
+
+	if report.Imported != 0 || len(report.Errors) != 1 || report.Errors[0].Row != 4 {
+		t.Errorf("ImportBeers(ctx, dec, all) with an invalid row returned report %+v, want only row 4 in error", report)
+	}
+
+	page, err := brewer.ListBeers(ctx, burp.BeerQuery{})
+	if err != nil || len(page.Beers) != 0 {
+		t.Fatalf("ListBeers(ctx, q) after a rejected import returned %+v, %v, want no beers", page, err)
+	}
+
+	report, err = brewer.ImportBeers(ctx, beerio.NewCSVDecoder(strings.NewReader(valid)), burp.ImportAll)
+	if err != nil || report.Imported != 2 || len(report.Errors) != 0 {
+		t.Fatalf("ImportBeers(ctx, dec, all) returned %+v, %v, want 2 beers imported", report, err)
+	}
+
+	page, err = brewer.ListBeers(ctx, burp.BeerQuery{})
+	if err != nil || len(page.Beers) != 2 {
+		t.Errorf("ListBeers(ctx, q) after import returned %+v, %v, want 2 beers", page, err)
+	}
+}
+
+func TestImportBeersWithUnsupportedMode(t *testing.T) {
+	brewer := &burp.Brewer{BeerRepo: memory.New()}
+
+	_, err := brewer.ImportBeers(context.Background(), beerio.NewCSVDecoder(strings.NewReader("")), "some")
+	if !errors.Is(err, burp.ErrImportModeNotSupported) {
+		t.Errorf("ImportBeers(ctx, dec, some) returned error %v, want %v", err, burp.ErrImportModeNotSupported)
+	}
+}
+
+// encoderSpy records encoded beers, and how many were at each flush.
+type encoderSpy struct {
+	beers   []*burp.Beer
+	flushes []int
+}
+
+func (e *encoderSpy) Encode(beer *burp.Beer) error {
+	e.beers = append(e.beers, beer)
+	return nil
+}
+
+func (e *encoderSpy) Flush() error {
+	e.flushes = append(e.flushes, len(e.beers))
+	return nil
+}
+
+func TestExportBeers(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: repo}
+
+	for i := 0; i < burp.MaxPageSize+1; i++ {
+		beer := burptest.RandBeer()
+		beer.Price.Currency = burp.USD
+		if i%2 == 0 {
+			beer.Price.Currency = burp.EUR
+		}
+
+		if err := repo.SaveBeer(ctx, beer); err != nil {
+			t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+		}
+	}
+
+	enc := &encoderSpy{}
+	if err := brewer.ExportBeers(ctx, burp.BeerFilter{}, enc); err != nil {
+		t.Fatalf("ExportBeers(ctx, f, enc) returned unexpected error %s", err)
+	}
+
+	if diff := cmp.Diff([]int{burp.MaxPageSize, burp.MaxPageSize + 1}, enc.flushes); diff != "" {
+		t.Errorf("ExportBeers(ctx, f, enc) flushed unexpected counts of beers, (-want/+got):\n%s", diff)
+	}
+
+	for i := 1; i < len(enc.beers); i++ {
+		if enc.beers[i-1].Name > enc.beers[i].Name {
+			t.Fatalf("ExportBeers(ctx, f, enc) encoded %q before %q, want beers sorted by name", enc.beers[i-1].Name, enc.beers[i].Name)
+		}
+	}
+
+	enc = &encoderSpy{}
+	if err := brewer.ExportBeers(ctx, burp.BeerFilter{Currency: burp.EUR}, enc); err != nil {
+		t.Fatalf("ExportBeers(ctx, f, enc) returned unexpected error %s", err)
+	}
+
+	if len(enc.beers) != burp.MaxPageSize/2+1 {
+		t.Errorf("ExportBeers(ctx, f, enc) of beers priced in euro encoded %d beers, want %d", len(enc.beers), burp.MaxPageSize/2+1)
+	}
+}
@@ -3,6 +3,7 @@ package burptest
 import (
 	"burp"
 	"github.com/google/uuid"
+	"math"
 	"math/rand"
 )
 
@@ -17,5 +18,29 @@ func RandBeer() *burp.Beer {
 			Currency: burp.EUR,
 			Amount:   uint(rand.Intn(1000-10) + 10),
 		},
+
+		Brewery:     RandString(20),
+		Style:       SliceItem([]string{"Tripel", "Dubbel", "IPA", "Stout", "Lambic"}),
+		ABV:         math.Round(rand.Float64()*150) / 10,
+		IBU:         uint(rand.Intn(120)),
+		Volume:      SliceItem([]uint{250, 330, 500, 750}),
+		Description: RandString(40),
+
+		Tags: burp.NormalizeTags([]string{
+			SliceItem([]string{"seasonal", "ipa", "gluten-free", "trappist"}),
+			SliceItem([]string{"organic", "sour", "craft", "low alcohol"}),
+		}),
+	}
+}
+
+func RandBrewery() *burp.Brewery {
+	return &burp.Brewery{
+		ID:        burp.ID{UUID: uuid.New()},
+		CreatedAt: RandTime(),
+		UpdatedAt: RandTime(),
+
+		Name:    RandString(20),
+		Country: SliceItem([]string{"Belgium", "Germany", "Ireland", "Czechia"}),
+		City:    RandString(10),
 	}
 }
@@ -9,3 +9,8 @@ func RandTime() time.Time {
 	randomTime := rand.Int63n(time.Now().Unix()-94608000) + 94608000
 	return time.Unix(randomTime, 0).UTC()
 }
+
+// Clock is a clock stopped at Time.
+type Clock struct{ Time time.Time }
+
+func (c Clock) Now() time.Time { return c.Time }
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	memoryRepo   = "memory"
+	fileRepo     = "file"
+	sqliteRepo   = "sqlite"
+	postgresRepo = "postgres"
+)
+
+// envPrefix prefixes environment variables read by loadConfig,
+// e.g. BURP_ADDR sets the listen address.
+const envPrefix = "BURP_"
+
+// config is how the server runs. Each setting is read, by increasing
+// precedence, from its default, the config file, the environment,
+// then the command line.
+type config struct {
+	Addr        string   `json:"addr"`
+	Repo        string   `json:"repo"`
+	DataFile    string   `json:"dataFile"`
+	DatabaseURL string   `json:"databaseUrl"`
+	RatesFile   string   `json:"ratesFile"`
+	Timeouts    timeouts `json:"timeouts"`
+	Pool        pool     `json:"pool"`
+}
+
+type timeouts struct {
+	Read     duration `json:"read"`
+	Write    duration `json:"write"`
+	Idle     duration `json:"idle"`
+	Shutdown duration `json:"shutdown"`
+}
+
+// pool sizes postgres connection pool, zero values keep driver defaults.
+type pool struct {
+	MaxConns          count    `json:"maxConns"`
+	MinConns          count    `json:"minConns"`
+	MaxConnLifetime   duration `json:"maxConnLifetime"`
+	MaxConnIdleTime   duration `json:"maxConnIdleTime"`
+	HealthCheckPeriod duration `json:"healthCheckPeriod"`
+}
+
+// count is a number of connections.
+type count int32
+
+func (c *count) Set(s string) error {
+	v, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return err
+	}
+	*c = count(v)
+	return nil
+}
+
+func (c *count) String() string {
+	return strconv.Itoa(int(*c))
+}
+
+// duration is a time.Duration written as in "10s" in config file.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\": %w", err)
+	}
+	return d.Set(s)
+}
+
+func (d *duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+func (d *duration) String() string {
+	return time.Duration(*d).String()
+}
+
+func defaultConfig() config {
+	return config{
+		Addr: "localhost:8080",
+		Repo: memoryRepo,
+		Timeouts: timeouts{
+			Read:     duration(10 * time.Second),
+			Write:    duration(10 * time.Second),
+			Idle:     duration(time.Minute),
+			Shutdown: duration(10 * time.Second),
+		},
+	}
+}
+
+// setting is a config value settable from environment and command line.
+type setting struct {
+	name  string
+	usage string
+	value flag.Value
+}
+
+func (c *config) settings() []setting {
+	return []setting{
+		{"addr", "address to listen on", (*stringValue)(&c.Addr)},
+		{"repo", "repository backend, memory, file, sqlite or postgres", (*stringValue)(&c.Repo)},
+		{"data-file", "path of the file storing beers of file and sqlite repos", (*stringValue)(&c.DataFile)},
+		{"database-url", "postgres connection string", (*stringValue)(&c.DatabaseURL)},
+		{"rates-file", "path of a JSON file of exchange rates to convert prices", (*stringValue)(&c.RatesFile)},
+		{"read-timeout", "maximum duration to read a request", &c.Timeouts.Read},
+		{"write-timeout", "maximum duration to write a response", &c.Timeouts.Write},
+		{"idle-timeout", "maximum duration to keep an idle connection", &c.Timeouts.Idle},
+		{"shutdown-timeout", "maximum duration to drain connections on shutdown", &c.Timeouts.Shutdown},
+		{"db-max-conns", "maximum size of postgres connection pool", &c.Pool.MaxConns},
+		{"db-min-conns", "minimum size of postgres connection pool", &c.Pool.MinConns},
+		{"db-max-conn-lifetime", "duration after which a postgres connection is closed", &c.Pool.MaxConnLifetime},
+		{"db-max-conn-idle-time", "duration after which an idle postgres connection is closed", &c.Pool.MaxConnIdleTime},
+		{"db-health-check-period", "duration between health checks of idle postgres connections", &c.Pool.HealthCheckPeriod},
+	}
+}
+
+type stringValue string
+
+func (s *stringValue) Set(v string) error {
+	*s = stringValue(v)
+	return nil
+}
+
+func (s *stringValue) String() string {
+	return string(*s)
+}
+
+// envName returns the environment variable of a setting,
+// e.g. BURP_DATABASE_URL for database-url.
+func envName(setting string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(setting, "-", "_"))
+}
+
+// loadConfig reads config from command line args, environment
+// looked up with getenv and config file, then validates it.
+// The config file is given by flag -config or BURP_CONFIG.
+// Args left after flags are returned.
+func loadConfig(args []string, getenv func(string) string, output io.Writer) (config, []string, error) {
+	cfg := defaultConfig()
+
+	// flags are parsed into a throwaway config first, as they
+	// must be applied last while giving the config file path
+	var flags config
+	fs := flag.NewFlagSet("burp", flag.ContinueOnError)
+	fs.SetOutput(output)
+	file := fs.String("config", getenv(envName("config")), "path of a JSON config file")
+	for _, s := range flags.settings() {
+		fs.Var(s.value, s.name, fmt.Sprintf("%s (env %s)", s.usage, envName(s.name)))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, nil, err
+	}
+
+	if *file != "" {
+		if err := cfg.readFile(*file); err != nil {
+			return config{}, nil, err
+		}
+	}
+
+	for _, s := range cfg.settings() {
+		name := envName(s.name)
+		if v := getenv(name); v != "" {
+			if err := s.value.Set(v); err != nil {
+				return config{}, nil, fmt.Errorf("invalid value %q for environment variable %s: %w", v, name, err)
+			}
+		}
+	}
+
+	settings := cfg.settings()
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.name == f.Name {
+				// values were already validated while parsing
+				_ = s.value.Set(f.Value.String())
+			}
+		}
+	})
+
+	if err := cfg.validate(); err != nil {
+		return config{}, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+func (c *config) readFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("unable to open config file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("unable to read config file %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func (c *config) validate() error {
+	if c.Addr == "" {
+		return errors.New("listen address is missing")
+	}
+
+	switch c.Repo {
+	case memoryRepo:
+	case fileRepo, sqliteRepo:
+		if c.DataFile == "" {
+			return fmt.Errorf("data file is required by %s repo, set %s or -data-file", c.Repo, envName("data-file"))
+		}
+	case postgresRepo:
+		if c.DatabaseURL == "" {
+			return fmt.Errorf("database url is required by %s repo, set %s or -database-url", postgresRepo, envName("database-url"))
+		}
+	default:
+		return fmt.Errorf("unknown repo %q, want %s, %s, %s or %s", c.Repo, memoryRepo, fileRepo, sqliteRepo, postgresRepo)
+	}
+
+	if c.Pool.MaxConns < 0 || c.Pool.MinConns < 0 {
+		return errors.New("connection pool size must not be negative")
+	}
+
+	if c.Pool.MaxConns != 0 && c.Pool.MinConns > c.Pool.MaxConns {
+		return fmt.Errorf("connection pool min size %d exceeds max size %d", c.Pool.MinConns, c.Pool.MaxConns)
+	}
+
+	if c.Pool.MaxConnLifetime < 0 || c.Pool.MaxConnIdleTime < 0 || c.Pool.HealthCheckPeriod < 0 {
+		return errors.New("connection pool durations must not be negative")
+	}
+
+	for _, t := range []struct {
+		name  string
+		value duration
+	}{
+		{"read", c.Timeouts.Read},
+		{"write", c.Timeouts.Write},
+		{"idle", c.Timeouts.Idle},
+		{"shutdown", c.Timeouts.Shutdown},
+	} {
+		if t.value <= 0 {
+			return fmt.Errorf("%s timeout must be positive, got %s", t.name, &t.value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "burp.json")
+	content := `{"addr": ":80", "repo": "postgres", "databaseUrl": "postgres://file", "timeouts": {"read": "5s"}}`
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile := defaultConfig()
+	fromFile.Addr = ":80"
+	fromFile.Repo = postgresRepo
+	fromFile.DatabaseURL = "postgres://file"
+	fromFile.Timeouts.Read = duration(5 * time.Second)
+
+	fromEnv := fromFile
+	fromEnv.DatabaseURL = "postgres://env"
+
+	fromFlags := fromEnv
+	fromFlags.Addr = ":8000"
+	fromFlags.Timeouts.Read = duration(time.Second)
+	fromFlags.Pool.MaxConns = 16
+
+	tests := []struct {
+		description string
+		args        []string
+		env         map[string]string
+		want        config
+	}{
+		{
+			description: "defaults",
+			want:        defaultConfig(),
+		},
+		{
+			description: "file",
+			env:         map[string]string{"BURP_CONFIG": file},
+			want:        fromFile,
+		},
+		{
+			description: "environment over file",
+			args:        []string{"-config", file},
+			env:         map[string]string{"BURP_DATABASE_URL": "postgres://env"},
+			want:        fromEnv,
+		},
+		{
+			description: "flags over environment",
+			args:        []string{"-config", file, "-addr", ":8000", "-read-timeout", "1s", "-db-max-conns", "16"},
+			env:         map[string]string{"BURP_DATABASE_URL": "postgres://env", "BURP_ADDR": ":9000"},
+			want:        fromFlags,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, _, err := loadConfig(test.args, getenv(test.env), io.Discard)
+			if err != nil {
+				t.Fatalf("loadConfig(%q) returned unexpected error %s", test.args, err)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("loadConfig(%q) returned unexpected config, (-want/+got):\n%s", test.args, diff)
+			}
+		})
+	}
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	tests := []struct {
+		description string
+		args        []string
+		env         map[string]string
+		err         string
+	}{
+		{
+			description: "unknown repo",
+			args:        []string{"-repo", "mongo"},
+			err:         `unknown repo "mongo"`,
+		},
+		{
+			description: "postgres without database url",
+			env:         map[string]string{"BURP_REPO": "postgres"},
+			err:         "database url is required",
+		},
+		{
+			description: "file without data file",
+			args:        []string{"-repo", "file"},
+			err:         "data file is required",
+		},
+		{
+			description: "negative timeout",
+			args:        []string{"-idle-timeout", "-1s"},
+			err:         "idle timeout must be positive",
+		},
+		{
+			description: "malformed environment duration",
+			env:         map[string]string{"BURP_WRITE_TIMEOUT": "soon"},
+			err:         "BURP_WRITE_TIMEOUT",
+		},
+		{
+			description: "pool min size over max size",
+			args:        []string{"-db-min-conns", "8", "-db-max-conns", "4"},
+			err:         "min size 8 exceeds max size 4",
+		},
+		{
+			description: "missing config file",
+			args:        []string{"-config", filepath.Join(t.TempDir(), "missing.json")},
+			err:         "unable to open config file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			_, _, err := loadConfig(test.args, getenv(test.env), io.Discard)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("loadConfig(%q) returned error %v, want error containing %q", test.args, err, test.err)
+			}
+		})
+	}
+}
+
+func getenv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
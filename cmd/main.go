@@ -2,27 +2,181 @@ package main
 
 import (
 	"burp"
-	"burp/repo/repotest"
+	"burp/rates"
+	"burp/repo/file"
+	"burp/repo/memory"
+	"burp/repo/psql"
+	"burp/repo/sqlite"
 	"burp/rest/chi"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
+// repository gathers what brewer needs from a backend.
+type repository interface {
+	burp.BeerRepo
+	burp.BeerEventRepo
+	burp.PriceRepo
+	burp.BreweryRepo
+	burp.StockRepo
+	burp.OrderRepo
+	burp.TxRunner
+}
+
 func main() {
-	addr := "localhost:8080"
-	repo := repotest.FakeRepo
-	brewer := &burp.Brewer{BeerRepo: repo}
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, command, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run runs command with args. Import and export talk to a
+// running server, other commands are configured by loadConfig.
+func run(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "import":
+		return importBeers(ctx, args, os.Getenv, os.Stdin, os.Stdout, os.Stderr)
+	case "export":
+		return exportBeers(ctx, args, os.Getenv, os.Stdout, os.Stderr)
+	}
+
+	cfg, args, err := loadConfig(args, os.Getenv, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "serve":
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected arguments %q", args)
+		}
+		return serve(ctx, cfg)
+	case "migrate":
+		return migrate(ctx, cfg, args, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q, want serve, migrate, import or export", command)
+	}
+}
+
+// serve handles http requests until ctx is done.
+func serve(ctx context.Context, cfg config) error {
+	repo, closeRepo, err := openRepo(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	brewer := &burp.Brewer{
+		BeerRepo:    repo,
+		EventRepo:   repo,
+		PriceRepo:   repo,
+		BreweryRepo: repo,
+		StockRepo:   repo,
+		OrderRepo:   repo,
+		Tx:          repo,
+	}
+	if cfg.RatesFile != "" {
+		if brewer.Rates, err = rates.LoadFile(cfg.RatesFile); err != nil {
+			return err
+		}
+	}
 	handler := chi.Handler(brewer)
 
 	server := &http.Server{
-		Addr:    addr,
-		Handler: handler,
+		Addr:         cfg.Addr,
+		Handler:      handler,
+		ReadTimeout:  time.Duration(cfg.Timeouts.Read),
+		WriteTimeout: time.Duration(cfg.Timeouts.Write),
+		IdleTimeout:  time.Duration(cfg.Timeouts.Idle),
 	}
 
-	fmt.Println("Starting server at", addr)
+	fmt.Printf("Starting server at %s with %s repo\n", cfg.Addr, cfg.Repo)
 
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatal(err)
+	l, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	return runServer(ctx, server, l, time.Duration(cfg.Timeouts.Shutdown))
+}
+
+// runServer serves requests accepted by l until ctx is done, then shuts
+// server down, returning once requests are drained or timeout is over.
+func runServer(ctx context.Context, server *http.Server, l net.Listener, timeout time.Duration) error {
+	// Serve returns as soon as Shutdown is called, which
+	// is then waited for as it drains requests
+	shutdown := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		shutdown <- server.Shutdown(shutdownCtx)
+	}()
+
+	err := server.Serve(l)
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	if err := <-shutdown; err != nil {
+		return fmt.Errorf("unable to shut down server: %w", err)
+	}
+
+	return nil
+}
+
+// openRepo returns the repository selected by cfg
+// along with a function to release its resources.
+func openRepo(ctx context.Context, cfg config) (repository, func(), error) {
+	switch cfg.Repo {
+	case postgresRepo:
+		pool, err := psql.NewPool(ctx, psql.PoolConfig{
+			URL:               cfg.DatabaseURL,
+			MaxConns:          int32(cfg.Pool.MaxConns),
+			MinConns:          int32(cfg.Pool.MinConns),
+			MaxConnLifetime:   time.Duration(cfg.Pool.MaxConnLifetime),
+			MaxConnIdleTime:   time.Duration(cfg.Pool.MaxConnIdleTime),
+			HealthCheckPeriod: time.Duration(cfg.Pool.HealthCheckPeriod),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return &psql.Repo{DB: pool}, pool.Close, nil
+	case sqliteRepo:
+		db, err := openSQLite(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &sqlite.Repo{DB: db}, func() { db.Close() }, nil
+	case fileRepo:
+		repo, err := file.Open(cfg.DataFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, func() {}, nil
+	default:
+		return memory.New(), func() {}, nil
 	}
 }
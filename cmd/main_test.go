@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// startServer runs a server with handler until the returned context
+// is cancelled, sending runServer result on the returned channel.
+func startServer(t *testing.T, handler http.Handler, timeout time.Duration) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned unexpected error %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runServer(ctx, &http.Server{Handler: handler}, l, timeout)
+	}()
+
+	return "http://" + l.Addr().String(), cancel, done
+}
+
+func TestRunServerDrainsRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "drained")
+	})
+
+	url, cancel, done := startServer(t, handler, time.Minute)
+
+	body := make(chan string, 1)
+	go func() {
+		response, err := http.Get(url)
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer response.Body.Close()
+		b, _ := io.ReadAll(response.Body)
+		body <- string(b)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		t.Fatalf("runServer returned %v while a request was in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	if got := <-body; got != "drained" {
+		t.Errorf("Request in flight on shutdown returned body %q, want %q", got, "drained")
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("runServer returned unexpected error %s", err)
+	}
+}
+
+func TestRunServerShutdownTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	url, cancel, done := startServer(t, handler, 10*time.Millisecond)
+
+	go http.Get(url)
+
+	<-started
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("runServer past shutdown timeout returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"burp/repo/psql"
+	"burp/repo/sqlite"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const migrateUsage = `usage: burp migrate [flags] [command]
+
+commands:
+  up        apply all pending migrations (default)
+  down      revert the last applied migration
+  to <v>    migrate up or down to schema version v
+  status    print current and latest schema versions`
+
+// sqliteBusyTimeout is how long sqlite waits for a concurrent write.
+const sqliteBusyTimeout = 5 * time.Second
+
+// migrator migrates the schema of a database.
+type migrator struct {
+	latest  int
+	version func(ctx context.Context) (int, error)
+	migrate func(ctx context.Context, to int) error
+	close   func()
+}
+
+// openMigrator returns the migrator of sqlite repo when selected
+// by cfg, of postgres database otherwise.
+func openMigrator(ctx context.Context, cfg config) (*migrator, error) {
+	if cfg.Repo == sqliteRepo {
+		latest, err := sqlite.LatestVersion()
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := sqlite.Open(cfg.DataFile, sqliteBusyTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		return &migrator{
+			latest:  latest,
+			version: func(ctx context.Context) (int, error) { return sqlite.SchemaVersion(ctx, db) },
+			migrate: func(ctx context.Context, to int) error { return sqlite.Migrate(ctx, db, to) },
+			close:   func() { db.Close() },
+		}, nil
+	}
+
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("database url is required to migrate, set %s or -database-url", envName("database-url"))
+	}
+
+	latest, err := psql.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := psql.NewPool(ctx, psql.PoolConfig{URL: cfg.DatabaseURL, MaxConns: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	// migrations hold a lock on their connection
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("unable to acquire connection: %w", err)
+	}
+
+	return &migrator{
+		latest:  latest,
+		version: func(ctx context.Context) (int, error) { return psql.SchemaVersion(ctx, conn.Conn()) },
+		migrate: func(ctx context.Context, to int) error { return psql.Migrate(ctx, conn.Conn(), to) },
+		close: func() {
+			conn.Release()
+			pool.Close()
+		},
+	}, nil
+}
+
+// migrate runs a migration command against sqlite or postgres database of cfg.
+func migrate(ctx context.Context, cfg config, args []string, output io.Writer) error {
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	m, err := openMigrator(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer m.close()
+
+	current, err := m.version(ctx)
+	if err != nil {
+		return err
+	}
+
+	to := m.latest
+	switch {
+	case command == "up" && len(args) == 0:
+	case command == "down" && len(args) == 0:
+		if current == 0 {
+			return errors.New("no migration to revert")
+		}
+		to = current - 1
+	case command == "to" && len(args) == 1:
+		if to, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid schema version %q", args[0])
+		}
+	case command == "status" && len(args) == 0:
+		_, err := fmt.Fprintf(output, "schema version %d, latest %d\n", current, m.latest)
+		return err
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	if err := m.migrate(ctx, to); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(output, "migrated schema from version %d to %d\n", current, to)
+	return err
+}
+
+// openSQLite opens sqlite database of cfg, applying pending
+// migrations as it is meant to run without operations.
+func openSQLite(ctx context.Context, cfg config) (*sql.DB, error) {
+	db, err := sqlite.Open(cfg.DataFile, sqliteBusyTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sqlite.MigrateUp(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
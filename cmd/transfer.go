@@ -0,0 +1,213 @@
+package main
+
+import (
+	"burp"
+	"burp/beerio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	importUsage = "usage: burp import [flags] [file]\n\nuploads beers of file, standard input when missing or \"-\", to a running server."
+	exportUsage = "usage: burp export [flags] [file]\n\ndownloads beers of a running server to file, standard output when missing or \"-\"."
+)
+
+// serverEnv names the environment variable of the server
+// url which import and export commands talk to.
+const serverEnv = envPrefix + "SERVER"
+
+const defaultServer = "http://localhost:8080"
+
+// transferFlags are flags shared by import and export commands.
+type transferFlags struct {
+	fs     *flag.FlagSet
+	server string
+	format string
+}
+
+func newTransferFlags(name, usage string, getenv func(string) string, output io.Writer) *transferFlags {
+	f := &transferFlags{fs: flag.NewFlagSet("burp "+name, flag.ContinueOnError)}
+	f.fs.SetOutput(output)
+	f.fs.Usage = func() {
+		fmt.Fprintf(output, "%s\n\nflags:\n", usage)
+		f.fs.PrintDefaults()
+	}
+
+	server := getenv(serverEnv)
+	if server == "" {
+		server = defaultServer
+	}
+
+	f.fs.StringVar(&f.server, "server", server, "url of burp server, also set by "+serverEnv)
+	f.fs.StringVar(&f.format, "format", "", "csv or ndjson, guessed from file extension, csv by default")
+	return f
+}
+
+// parse parses args, returning the file name, "-" when missing,
+// and its format.
+func (f *transferFlags) parse(args []string) (string, beerio.Format, error) {
+	if err := f.fs.Parse(args); err != nil {
+		return "", "", err
+	}
+
+	if f.fs.NArg() > 1 {
+		return "", "", fmt.Errorf("unexpected arguments %q", f.fs.Args()[1:])
+	}
+
+	name := f.fs.Arg(0)
+	if name == "" {
+		name = "-"
+	}
+
+	format := beerio.Format(f.format)
+	if format == "" {
+		format = beerio.CSV
+		switch filepath.Ext(name) {
+		case ".ndjson", ".jsonl":
+			format = beerio.NDJSON
+		}
+	}
+
+	return name, format, format.Validate()
+}
+
+// importBeers uploads beers of a file to burp server, then prints
+// rows which could not be imported. It fails if there are some.
+func importBeers(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout, stderr io.Writer) error {
+	f := newTransferFlags("import", importUsage, getenv, stderr)
+	mode := f.fs.String("mode", string(burp.ImportEach), "each to save every valid beer, all to save none unless all are valid")
+
+	name, format, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+
+	body := stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		body = file
+	}
+
+	endpoint := f.server + "/api/v1/beers/import?" + url.Values{"mode": {*mode}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", format.ContentType())
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusUnprocessableEntity {
+		return responseError(res)
+	}
+
+	var report struct {
+		Imported int `json:"imported"`
+		Errors   []struct {
+			Row   int    `json:"row"`
+			Error string `json:"error"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+		return fmt.Errorf("unable to decode import report: %w", err)
+	}
+
+	for _, e := range report.Errors {
+		fmt.Fprintf(stdout, "row %d: %s\n", e.Row, e.Error)
+	}
+	fmt.Fprintf(stdout, "imported %d beers\n", report.Imported)
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d rows not imported", len(report.Errors))
+	}
+
+	return nil
+}
+
+// exportBeers downloads beers of burp server to a file.
+func exportBeers(ctx context.Context, args []string, getenv func(string) string, stdout, stderr io.Writer) error {
+	f := newTransferFlags("export", exportUsage, getenv, stderr)
+	name := f.fs.String("name", "", "export only beers whose name contains it")
+	currency := f.fs.String("currency", "", "export only beers priced in it")
+
+	path, format, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{"format": {string(format)}}
+	if *name != "" {
+		params.Set("name", *name)
+	}
+	if *currency != "" {
+		params.Set("currency", *currency)
+	}
+
+	endpoint := f.server + "/api/v1/beers/export?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+
+	if path == "-" {
+		_, err := io.Copy(stdout, res.Body)
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, res.Body); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// responseError returns the error of a failed response.
+func responseError(res *http.Response) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+
+	b, _ := io.ReadAll(res.Body)
+	if err := json.Unmarshal(b, &body); err != nil || body.Error == "" {
+		body.Error = strings.TrimSpace(string(b))
+	}
+
+	if body.Error == "" {
+		return errors.New(res.Status)
+	}
+
+	return fmt.Errorf("%s: %s", res.Status, body.Error)
+}
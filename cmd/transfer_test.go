@@ -0,0 +1,80 @@
+package main
+
+import (
+	"burp"
+	"burp/repo/memory"
+	"burp/rest/chi"
+	"bytes"
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportExportBeers(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(chi.Handler(&burp.Brewer{BeerRepo: memory.New()}))
+	defer server.Close()
+	env := getenv(map[string]string{serverEnv: server.URL})
+	dir := t.TempDir()
+
+	beers := filepath.Join(dir, "beers.csv")
+	if err := os.WriteFile(beers, []byte("name,currency,amount\nKwak,Euro,350\nChimay,Euro,ten\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	err := importBeers(ctx, []string{"-mode", "all", beers}, env, nil, &stdout, io.Discard)
+	if err == nil || stdout.String() != "row 3: invalid amount \"ten\"\nimported 0 beers\n" {
+		t.Errorf("import -mode all of an invalid row returned error %v, output %q, want nothing imported", err, stdout.String())
+	}
+
+	stdout.Reset()
+	err = importBeers(ctx, []string{"-format", "ndjson"}, env, strings.NewReader(`{"name":"Duvel","price":{"currency":"Euro","amount":420}}`), &stdout, io.Discard)
+	if err != nil || stdout.String() != "imported 1 beers\n" {
+		t.Errorf("import of standard input returned error %v, output %q, want a beer imported", err, stdout.String())
+	}
+
+	stdout.Reset()
+	err = importBeers(ctx, []string{beers}, env, nil, &stdout, io.Discard)
+	if err == nil || !strings.HasSuffix(stdout.String(), "imported 1 beers\n") {
+		t.Errorf("import of a file with an invalid row returned error %v, output %q, want a beer imported", err, stdout.String())
+	}
+
+	exported := filepath.Join(dir, "export.ndjson")
+	if err := exportBeers(ctx, []string{"-name", "u", exported}, env, io.Discard, io.Discard); err != nil {
+		t.Fatalf("export returned unexpected error %s", err)
+	}
+
+	b, err := os.ReadFile(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"name":"Duvel"`) {
+		t.Errorf("export -name u returned unexpected beers:\n%s", b)
+	}
+
+	stdout.Reset()
+	if err := exportBeers(ctx, nil, env, &stdout, io.Discard); err != nil {
+		t.Fatalf("export to standard output returned unexpected error %s", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n")[1:] {
+		names = append(names, strings.Split(line, ",")[2])
+	}
+	if diff := cmp.Diff([]string{"Duvel", "Kwak"}, names); diff != "" {
+		t.Errorf("export to standard output returned unexpected beers, (-want/+got):\n%s", diff)
+	}
+
+	err = exportBeers(ctx, []string{"-currency", "Yen"}, env, io.Discard, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("export -currency Yen returned error %v, want a bad request", err)
+	}
+}
@@ -0,0 +1,184 @@
+package burp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrCurrencyCodeInvalid       = Error("currency code must be 3 uppercase letters")
+	ErrCurrencyMinorUnitsInvalid = Error("currency minor units must be between 0 and 4")
+	ErrExchangeRateNotFound      = Error("exchange rate not found")
+	ErrConversionUnavailable     = Error("exchange rates are not provided")
+)
+
+// Currency is an ISO 4217 code, such as EUR.
+type Currency string
+
+var (
+	EUR Currency = "EUR"
+	USD Currency = "USD"
+)
+
+// legacyCurrencies maps names once used as currencies, upper-cased,
+// to their code.
+var legacyCurrencies = map[string]Currency{
+	"EURO":   EUR,
+	"DOLLAR": USD,
+}
+
+// ParseCurrency returns the currency of code s, case-insensitively.
+// Legacy names Euro and Dollar are read as EUR and USD, whatever
+// their case.
+func ParseCurrency(s string) Currency {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if c, ok := legacyCurrencies[code]; ok {
+		return c
+	}
+	return Currency(code)
+}
+
+// UnmarshalText reads c as ParseCurrency does, so that
+// prices stored or sent with legacy names remain valid.
+func (c *Currency) UnmarshalText(text []byte) error {
+	*c = ParseCurrency(string(text))
+	return nil
+}
+
+// CurrencyInfo describes a registered currency. Amounts of prices in it
+// count its minor unit, e.g. cents of EUR whose MinorUnits is 2.
+type CurrencyInfo struct {
+	Code       Currency `json:"code"`
+	Name       string   `json:"name"`
+	MinorUnits int      `json:"minorUnits"`
+}
+
+func (i CurrencyInfo) Validate() error {
+	if len(i.Code) != 3 || strings.Trim(string(i.Code), "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return ErrCurrencyCodeInvalid
+	}
+
+	if i.MinorUnits < 0 || i.MinorUnits > 4 {
+		return ErrCurrencyMinorUnitsInvalid
+	}
+
+	return nil
+}
+
+var (
+	currenciesMu sync.RWMutex
+	currencies   = map[Currency]CurrencyInfo{}
+)
+
+func init() {
+	for _, info := range []CurrencyInfo{
+		{Code: "AUD", Name: "Australian dollar", MinorUnits: 2},
+		{Code: "BHD", Name: "Bahraini dinar", MinorUnits: 3},
+		{Code: "CAD", Name: "Canadian dollar", MinorUnits: 2},
+		{Code: "CHF", Name: "Swiss franc", MinorUnits: 2},
+		{Code: "CNY", Name: "Renminbi", MinorUnits: 2},
+		{Code: "CZK", Name: "Czech koruna", MinorUnits: 2},
+		{Code: "DKK", Name: "Danish krone", MinorUnits: 2},
+		{Code: EUR, Name: "Euro", MinorUnits: 2},
+		{Code: "GBP", Name: "Pound sterling", MinorUnits: 2},
+		{Code: "JPY", Name: "Japanese yen", MinorUnits: 0},
+		{Code: "KWD", Name: "Kuwaiti dinar", MinorUnits: 3},
+		{Code: "NOK", Name: "Norwegian krone", MinorUnits: 2},
+		{Code: "PLN", Name: "Polish zloty", MinorUnits: 2},
+		{Code: "SEK", Name: "Swedish krona", MinorUnits: 2},
+		{Code: USD, Name: "United States dollar", MinorUnits: 2},
+	} {
+		if err := RegisterCurrency(info); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterCurrency makes prices in info.Code valid,
+// replacing any currency registered with the same code.
+func RegisterCurrency(info CurrencyInfo) error {
+	if err := info.Validate(); err != nil {
+		return err
+	}
+
+	currenciesMu.Lock()
+	defer currenciesMu.Unlock()
+
+	currencies[info.Code] = info
+	return nil
+}
+
+// LookupCurrency returns the registered currency c, if any.
+func LookupCurrency(c Currency) (CurrencyInfo, bool) {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+
+	info, ok := currencies[c]
+	return info, ok
+}
+
+// Currencies returns registered currencies sorted by code.
+func Currencies() []CurrencyInfo {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+
+	infos := make([]CurrencyInfo, 0, len(currencies))
+	for _, info := range currencies {
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Code < infos[j].Code })
+	return infos
+}
+
+// RateProvider provides exchange rates between currencies.
+type RateProvider interface {
+	// Rate returns how many units of to a unit of from is worth,
+	// or ErrExchangeRateNotFound.
+	Rate(ctx context.Context, from, to Currency) (float64, error)
+}
+
+// Convert returns p in currency to at given rate, from p currency
+// to the other. Amount is rounded to the nearest minor unit of to.
+func (p Price) Convert(to Currency, rate float64) (Price, error) {
+	from, ok := LookupCurrency(p.Currency)
+	if !ok {
+		return Price{}, ErrCurrencyNotSupported
+	}
+
+	target, ok := LookupCurrency(to)
+	if !ok {
+		return Price{}, ErrCurrencyNotSupported
+	}
+
+	scale := math.Pow10(target.MinorUnits - from.MinorUnits)
+	amount := math.Round(float64(p.Amount) * rate * scale)
+
+	return Price{Currency: to, Amount: uint(amount)}, nil
+}
+
+// ConvertPrice returns p in currency to, at the rate given by Rates.
+func (b *Brewer) ConvertPrice(ctx context.Context, p Price, to Currency) (Price, error) {
+	if err := (Price{Currency: to}).Validate(); err != nil {
+		return Price{}, err
+	}
+
+	if p.Currency == to {
+		return p, nil
+	}
+
+	if b.Rates == nil {
+		return Price{}, ErrConversionUnavailable
+	}
+
+	rate, err := b.Rates.Rate(ctx, p.Currency, to)
+	if err != nil {
+		return Price{}, fmt.Errorf("unable to convert %s to %s: %w", p.Currency, to, err)
+	}
+
+	return p.Convert(to, rate)
+}
@@ -0,0 +1,106 @@
+package burp_test
+
+import (
+	"burp"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseCurrency(t *testing.T) {
+	tests := map[string]burp.Currency{
+		"EUR":    burp.EUR,
+		"usd":    burp.USD,
+		" gbp ":  "GBP",
+		"Euro":   burp.EUR,
+		"Dollar": burp.USD,
+		"EURO":   burp.EUR,
+		"dollar": burp.USD,
+		" euro ": burp.EUR,
+		"":       "",
+	}
+
+	for s, want := range tests {
+		if got := burp.ParseCurrency(s); got != want {
+			t.Errorf("ParseCurrency(%q) returned %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestUnmarshalLegacyCurrency(t *testing.T) {
+	var p burp.Price
+	if err := json.Unmarshal([]byte(`{"currency": "Euro", "amount": 350}`), &p); err != nil {
+		t.Fatalf("Unmarshal returned unexpected error %s", err)
+	}
+
+	if want := (burp.Price{Currency: burp.EUR, Amount: 350}); p != want {
+		t.Errorf("Unmarshal of a price in Euro returned %+v, want %+v", p, want)
+	}
+}
+
+func TestRegisterCurrency(t *testing.T) {
+	tests := []struct {
+		info burp.CurrencyInfo
+		want error
+	}{
+		{info: burp.CurrencyInfo{Code: "XBT", Name: "Bitcoin", MinorUnits: 4}},
+		{info: burp.CurrencyInfo{Code: "xbt", MinorUnits: 2}, want: burp.ErrCurrencyCodeInvalid},
+		{info: burp.CurrencyInfo{Code: "BTC1", MinorUnits: 2}, want: burp.ErrCurrencyCodeInvalid},
+		{info: burp.CurrencyInfo{Code: "XBT", MinorUnits: 8}, want: burp.ErrCurrencyMinorUnitsInvalid},
+	}
+
+	for _, test := range tests {
+		if err := burp.RegisterCurrency(test.info); !errors.Is(err, test.want) {
+			t.Errorf("RegisterCurrency(%+v) returned error %v, want %v", test.info, err, test.want)
+		}
+	}
+
+	if err := (burp.Price{Currency: "XBT", Amount: 1}).Validate(); err != nil {
+		t.Errorf("Validate() of a price in a registered currency returned error %s", err)
+	}
+}
+
+func TestConvertPrice(t *testing.T) {
+	tests := []struct {
+		price burp.Price
+		to    burp.Currency
+		rate  float64
+		want  burp.Price
+	}{
+		{price: burp.Price{Currency: burp.EUR, Amount: 350}, to: burp.USD, rate: 1.08, want: burp.Price{Currency: burp.USD, Amount: 378}},
+		{price: burp.Price{Currency: burp.EUR, Amount: 350}, to: "JPY", rate: 160.4, want: burp.Price{Currency: "JPY", Amount: 561}},
+		{price: burp.Price{Currency: "JPY", Amount: 561}, to: burp.EUR, rate: 0.00625, want: burp.Price{Currency: burp.EUR, Amount: 351}},
+		{price: burp.Price{Currency: burp.USD, Amount: 1000}, to: "KWD", rate: 0.307, want: burp.Price{Currency: "KWD", Amount: 3070}},
+	}
+
+	for _, test := range tests {
+		got, err := test.price.Convert(test.to, test.rate)
+		if err != nil {
+			t.Fatalf("Convert(%s, %v) of %+v returned unexpected error %s", test.to, test.rate, test.price, err)
+		}
+
+		if got != test.want {
+			t.Errorf("Convert(%s, %v) of %+v returned %+v, want %+v", test.to, test.rate, test.price, got, test.want)
+		}
+	}
+}
+
+func TestConvertPriceWithoutRates(t *testing.T) {
+	ctx := context.Background()
+	brewer := &burp.Brewer{}
+	price := burp.Price{Currency: burp.EUR, Amount: 350}
+
+	got, err := brewer.ConvertPrice(ctx, price, burp.EUR)
+	if err != nil || got != price {
+		t.Errorf("ConvertPrice(ctx, %+v, %s) returned %+v, %v, want the same price", price, burp.EUR, got, err)
+	}
+
+	if _, err := brewer.ConvertPrice(ctx, price, burp.USD); !errors.Is(err, burp.ErrConversionUnavailable) {
+		t.Errorf("ConvertPrice(ctx, %+v, %s) returned error %v, want %v", price, burp.USD, err, burp.ErrConversionUnavailable)
+	}
+
+	if _, err := brewer.ConvertPrice(ctx, price, "Yen"); !errors.Is(err, burp.ErrCurrencyNotSupported) {
+		t.Errorf("ConvertPrice(ctx, %+v, Yen) returned error %v, want %v", price, err, burp.ErrCurrencyNotSupported)
+	}
+}
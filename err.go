@@ -11,11 +11,43 @@ var (
 	ErrBeerCreateDateMissing = Error("creation date is missing")
 	ErrBeerUpdateDateMissing = Error("update date is missing")
 
+	ErrBeerBreweryTooLong     = Errorf("brewery exceed %d character", MaxBreweryLength)
+	ErrBeerStyleTooLong       = Errorf("style exceed %d character", MaxStyleLength)
+	ErrBeerDescriptionTooLong = Errorf("description exceed %d character", MaxDescriptionLength)
+	ErrBeerABVOutOfRange      = Errorf("abv must be between 0 and %v percent", MaxABV)
+	ErrBeerIBUOutOfRange      = Errorf("ibu must be between 0 and %d", MaxIBU)
+	ErrBeerVolumeOutOfRange   = Errorf("volume must be between 0 and %d milliliters", MaxVolume)
+
+	ErrBreweryCreateDateMissing = Error("brewery creation date is missing")
+	ErrBreweryUpdateDateMissing = Error("brewery update date is missing")
+
 	ErrIDEmpty = Error("id cannot be empty")
 
+	ErrVersionConflict = Error("beer has been modified since it was read")
+
 	ErrCurrencyNotSupported = Error("currency not supported")
+
+	ErrPriceChangeDateMissing = Error("price change date is missing")
+	ErrPriceChangeNotInFuture = Error("price change must be scheduled in the future")
+	ErrPricingUnavailable     = Error("price history is not recorded")
+
+	ErrSortNotSupported   = Error("sort not supported")
+	ErrPageSizeOutOfRange = Errorf("page size must be between 1 and %d", MaxPageSize)
+	ErrCursorInvalid      = Error("invalid cursor")
+	ErrCursorMismatch     = Error("cursor does not match requested sort")
+
+	ErrPriceRangeInvalid         = Error("minimum price exceed maximum price")
+	ErrPriceRangeWithoutCurrency = Error("price range requires a currency")
+	ErrPriceSortWithoutCurrency  = Error("price sort requires a currency")
+
+	ErrSearchTextMissing = Error("search text is missing")
+	ErrSearchTextTooLong = Errorf("search text exceed %d character", MaxSearchTextLength)
 )
 
+// ErrNotFound is wrapped by errors of what is looked
+// for but not stored, such as a beer of unknown id.
+var ErrNotFound = errors.New("resource not found")
+
 type Err struct {
 	err error
 }
@@ -0,0 +1,43 @@
+package burp
+
+import (
+	"context"
+	"time"
+)
+
+type BeerEventKind string
+
+var (
+	BeerCreated  BeerEventKind = "created"
+	BeerUpdated  BeerEventKind = "updated"
+	BeerDeleted  BeerEventKind = "deleted"
+	BeerRestored BeerEventKind = "restored"
+	BeerPurged   BeerEventKind = "purged"
+)
+
+// BeerEvent records a change made to a beer, by whom and when.
+// Before and After are snapshots of the beer around the change,
+// nil when the beer was not selectable, e.g. when in trash.
+type BeerEvent struct {
+	ID     ID            `json:"id"`
+	BeerID ID            `json:"beerId"`
+	Kind   BeerEventKind `json:"kind"`
+	Actor  string        `json:"actor,omitempty"`
+	Time   time.Time     `json:"time"`
+
+	Before *Beer `json:"before,omitempty"`
+	After  *Beer `json:"after,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying who acts on beers.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns who acts on beers, empty when unknown.
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -0,0 +1,209 @@
+package burp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Bounds of beers stock.
+const (
+	MaxLocationLength = 50
+	MaxStockQuantity  = 1000000
+)
+
+var (
+	ErrLocationMissing       = Error("stock location is missing")
+	ErrLocationTooLong       = Errorf("stock location exceed %d character", MaxLocationLength)
+	ErrStockDeltaMissing     = Error("stock movement must add or remove units")
+	ErrStockDeltaTooLarge    = Errorf("stock movement exceed %d units", MaxStockQuantity)
+	ErrStockThresholdTooHigh = Errorf("stock threshold exceed %d units", MaxStockQuantity)
+	ErrStockInsufficient     = Error("stock is insufficient")
+	ErrStockOverflow         = Errorf("stock would exceed %d units", MaxStockQuantity)
+	ErrInventoryUnavailable  = Error("stock is not tracked")
+)
+
+// Stock is the quantity of a beer at a location, such as a bar or
+// a cellar. It is low below Threshold, zero never being low.
+type Stock struct {
+	BeerID    ID        `json:"beerId"`
+	Location  string    `json:"location"`
+	Quantity  uint      `json:"quantity"`
+	Threshold uint      `json:"threshold"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Low reports whether quantity is below threshold.
+func (s *Stock) Low() bool {
+	return s.Quantity < s.Threshold
+}
+
+func (s Stock) MarshalJSON() ([]byte, error) {
+	type stock Stock
+	return json.Marshal(struct {
+		stock
+		Low bool `json:"low"`
+	}{stock(s), s.Low()})
+}
+
+// Apply adds m.Delta units to s, or removes them when negative. It fails
+// with ErrStockInsufficient rather than going below zero, leaving s as is.
+func (s *Stock) Apply(m *StockMovement) error {
+	quantity := int(s.Quantity) + m.Delta
+	if quantity < 0 {
+		return ErrStockInsufficient
+	}
+
+	if quantity > MaxStockQuantity {
+		return ErrStockOverflow
+	}
+
+	s.Quantity = uint(quantity)
+	s.UpdatedAt = m.Time
+	return nil
+}
+
+// StockMovement moves units of a beer in or out of a location.
+type StockMovement struct {
+	BeerID   ID     `json:"beerId"`
+	Location string `json:"location"`
+
+	// Delta is positive when units come in, negative when they go out.
+	Delta int       `json:"delta"`
+	Time  time.Time `json:"time"`
+}
+
+// StockThreshold sets the quantity below which stock is low.
+type StockThreshold struct {
+	BeerID    ID        `json:"beerId"`
+	Location  string    `json:"location"`
+	Threshold uint      `json:"threshold"`
+	Time      time.Time `json:"time"`
+}
+
+// StockQuery filters stock to list, each filter matching any when zero.
+type StockQuery struct {
+	BeerID   ID
+	Location string
+
+	// Low lists stock below its threshold only.
+	Low bool
+}
+
+type StockRepo interface {
+	StockMover
+	StockThresholdSetter
+	StockLister
+}
+
+// StockMover moves stock atomically: concurrent movements of the same
+// stock apply one after the other, so that it never goes negative.
+// Stock of a beer at a location starts empty.
+type StockMover interface {
+	MoveStock(ctx context.Context, m *StockMovement) (*Stock, error)
+}
+
+// StockThresholdSetter sets the threshold of stock, empty if new.
+type StockThresholdSetter interface {
+	SetStockThreshold(ctx context.Context, t *StockThreshold) (*Stock, error)
+}
+
+// StockLister lists stock matching q, by beer id then location.
+type StockLister interface {
+	ListStock(ctx context.Context, q StockQuery) ([]*Stock, error)
+}
+
+// MoveStock moves units of a stored beer in or out of a location,
+// and returns the stock there. Stock never goes below zero, the
+// movement failing with ErrStockInsufficient instead.
+func (b *Brewer) MoveStock(ctx context.Context, m *StockMovement) (*Stock, error) {
+	if b.StockRepo == nil {
+		return nil, ErrInventoryUnavailable
+	}
+
+	m.Location = strings.TrimSpace(m.Location)
+	m.Time = b.now()
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	var stock *Stock
+	err := b.inTx(ctx, func(ctx context.Context) error {
+		if _, err := b.BeerRepo.SelectBeer(ctx, m.BeerID); err != nil {
+			return fmt.Errorf("unable to move stock %+v: %w", m, err)
+		}
+
+		var err error
+		if stock, err = b.StockRepo.MoveStock(ctx, m); err != nil {
+			return fmt.Errorf("unable to move stock %+v: %w", m, err)
+		}
+
+		return nil
+	})
+
+	return stock, err
+}
+
+// SetStockThreshold sets the threshold of a stored beer stock at a
+// location, and returns the stock there.
+func (b *Brewer) SetStockThreshold(ctx context.Context, t *StockThreshold) (*Stock, error) {
+	if b.StockRepo == nil {
+		return nil, ErrInventoryUnavailable
+	}
+
+	t.Location = strings.TrimSpace(t.Location)
+	t.Time = b.now()
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	var stock *Stock
+	err := b.inTx(ctx, func(ctx context.Context) error {
+		if _, err := b.BeerRepo.SelectBeer(ctx, t.BeerID); err != nil {
+			return fmt.Errorf("unable to set stock threshold %+v: %w", t, err)
+		}
+
+		var err error
+		if stock, err = b.StockRepo.SetStockThreshold(ctx, t); err != nil {
+			return fmt.Errorf("unable to set stock threshold %+v: %w", t, err)
+		}
+
+		return nil
+	})
+
+	return stock, err
+}
+
+// BeerStock returns the stock of a stored beer at every location.
+func (b *Brewer) BeerStock(ctx context.Context, id ID) ([]*Stock, error) {
+	if b.StockRepo == nil {
+		return nil, ErrInventoryUnavailable
+	}
+
+	if _, err := b.BeerRepo.SelectBeer(ctx, id); err != nil {
+		return nil, fmt.Errorf("unable to select stock of beer %q: %w", id, err)
+	}
+
+	return b.ListStock(ctx, StockQuery{BeerID: id})
+}
+
+// ListStock returns stock matching q, e.g. low stock at a location.
+func (b *Brewer) ListStock(ctx context.Context, q StockQuery) ([]*Stock, error) {
+	if b.StockRepo == nil {
+		return nil, ErrInventoryUnavailable
+	}
+
+	q.Location = strings.TrimSpace(q.Location)
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	stock, err := b.StockRepo.ListStock(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list stock with query %+v: %w", q, err)
+	}
+
+	return stock, nil
+}
@@ -0,0 +1,109 @@
+package burp_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo"
+	"burp/repo/memory"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStockMovementValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		delta    int
+		want     error
+	}{
+		{name: "In", location: "cellar", delta: 12},
+		{name: "Out", location: "cellar", delta: -12},
+		{name: "NoLocation", location: "", delta: 1, want: burp.ErrLocationMissing},
+		{name: "LocationTooLong", location: strings.Repeat("a", burp.MaxLocationLength+1), delta: 1, want: burp.ErrLocationTooLong},
+		{name: "Zero", location: "cellar", delta: 0, want: burp.ErrStockDeltaMissing},
+		{name: "TooLarge", location: "cellar", delta: -burp.MaxStockQuantity - 1, want: burp.ErrStockDeltaTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := &burp.StockMovement{BeerID: burptest.RandBeer().ID, Location: test.location, Delta: test.delta}
+
+			if err := m.Validate(); !errors.Is(err, test.want) {
+				t.Errorf("movement %+v Validate() returned error %v, want %v", m, err, test.want)
+			}
+		})
+	}
+}
+
+func TestStockJSON(t *testing.T) {
+	stock := burp.Stock{Location: "bar", Quantity: 2, Threshold: 3}
+
+	b, err := json.Marshal(stock)
+	if err != nil {
+		t.Fatalf("json.Marshal(%+v) returned unexpected error %s", stock, err)
+	}
+
+	if !strings.Contains(string(b), `"low":true`) {
+		t.Errorf("json.Marshal(%+v) returned %s, want it low", stock, b)
+	}
+}
+
+func TestMoveStock(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	brewer := &burp.Brewer{BeerRepo: memRepo, StockRepo: memRepo, Tx: memRepo, Clock: burptest.Clock{Time: now}}
+
+	beer := burptest.RandBeer()
+	if err := brewer.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	m := &burp.StockMovement{BeerID: beer.ID, Location: " cellar ", Delta: 4}
+	stock, err := brewer.MoveStock(ctx, m)
+	if err != nil {
+		t.Fatalf("MoveStock(ctx, %+v) returned unexpected error %s", m, err)
+	}
+
+	want := burp.Stock{BeerID: beer.ID, Location: "cellar", Quantity: 4, UpdatedAt: now}
+	if *stock != want {
+		t.Errorf("MoveStock(ctx, m) returned stock %+v, want %+v", stock, want)
+	}
+
+	m = &burp.StockMovement{BeerID: beer.ID, Location: "cellar", Delta: -5}
+	if _, err := brewer.MoveStock(ctx, m); !errors.Is(err, burp.ErrStockInsufficient) {
+		t.Errorf("MoveStock(ctx, %+v) returned unexpected error:\ngot %v want %v", m, err, burp.ErrStockInsufficient)
+	}
+
+	m = &burp.StockMovement{BeerID: burptest.RandBeer().ID, Location: "cellar", Delta: 1}
+	if _, err := brewer.MoveStock(ctx, m); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("MoveStock(ctx, %+v) of a missing beer returned unexpected error:\ngot %v want %v", m, err, repo.ErrNotFound)
+	}
+
+	th := &burp.StockThreshold{BeerID: beer.ID, Location: "cellar", Threshold: 5}
+	if _, err := brewer.SetStockThreshold(ctx, th); err != nil {
+		t.Fatalf("SetStockThreshold(ctx, %+v) returned unexpected error %s", th, err)
+	}
+
+	low, err := brewer.ListStock(ctx, burp.StockQuery{Low: true})
+	if err != nil {
+		t.Fatalf("ListStock(ctx, low) returned unexpected error %s", err)
+	}
+
+	if len(low) != 1 || low[0].Quantity != 4 || low[0].Threshold != 5 {
+		t.Errorf("ListStock(ctx, low) returned %+v, want the cellar stock only", low)
+	}
+}
+
+func TestStockUnavailable(t *testing.T) {
+	brewer := &burp.Brewer{BeerRepo: memory.New()}
+
+	m := &burp.StockMovement{BeerID: burptest.RandBeer().ID, Location: "cellar", Delta: 1}
+	if _, err := brewer.MoveStock(context.Background(), m); !errors.Is(err, burp.ErrInventoryUnavailable) {
+		t.Errorf("MoveStock(ctx, m) without stock repo returned unexpected error:\ngot %v want %v", err, burp.ErrInventoryUnavailable)
+	}
+}
@@ -0,0 +1,198 @@
+package burp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// BeerSort is the field beers are ordered by when listed.
+// Ties are always broken by beer ID so that ordering is total.
+type BeerSort string
+
+var (
+	SortByName      BeerSort = "name"
+	SortByCreatedAt BeerSort = "createdAt"
+	// SortByPrice orders beers by amount, which requires
+	// filtering them by currency for amounts to compare.
+	SortByPrice BeerSort = "price"
+)
+
+// BeerFilter restricts listed beers. Zero values match every beer.
+type BeerFilter struct {
+	// Name matches beers whose name contains it, case-insensitively.
+	Name string
+
+	// Currency matches beers priced in it. It is required to
+	// bound prices with MinAmount or MaxAmount, both inclusive.
+	Currency  Currency
+	MinAmount uint
+	MaxAmount uint
+
+	// BreweryID matches beers of this brewery, unless zero.
+	BreweryID ID
+
+	// Tags matches beers having all of them.
+	Tags []string
+
+	// Deleted lists beers in trash instead of active ones.
+	Deleted bool
+}
+
+// BeerQuery describes a page of beers to list.
+type BeerQuery struct {
+	Filter BeerFilter
+	Sort   BeerSort
+	Desc   bool
+	Limit  int
+
+	// After is the cursor of the previous page, nil for the first one.
+	After *Cursor
+}
+
+type BeerPage struct {
+	Beers []*Beer `json:"items"`
+	Next  *Cursor `json:"next,omitempty"`
+}
+
+// Cursor marks the position of a beer in a given ordering.
+// It is sent to clients as an opaque page token.
+type Cursor struct {
+	Sort BeerSort `json:"s"`
+	Desc bool     `json:"d,omitempty"`
+	Key  string   `json:"k"`
+	ID   ID       `json:"i"`
+}
+
+// NewCursor returns the cursor positioned on given beer.
+func NewCursor(b *Beer, sort BeerSort, desc bool) *Cursor {
+	return &Cursor{
+		Sort: sort,
+		Desc: desc,
+		Key:  b.sortKey(sort),
+		ID:   b.ID,
+	}
+}
+
+// ParseCursor decodes a page token produced by Cursor.String.
+func ParseCursor(token string) (*Cursor, error) {
+	var c Cursor
+	if err := c.UnmarshalText([]byte(token)); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c Cursor) String() string {
+	b, _ := c.MarshalText()
+	return string(b)
+}
+
+func (c Cursor) MarshalText() ([]byte, error) {
+	type cursor Cursor
+	b, err := json.Marshal(cursor(c))
+	if err != nil {
+		return nil, err
+	}
+
+	token := make([]byte, base64.RawURLEncoding.EncodedLen(len(b)))
+	base64.RawURLEncoding.Encode(token, b)
+	return token, nil
+}
+
+func (c *Cursor) UnmarshalText(token []byte) error {
+	type cursor Cursor
+	b := make([]byte, base64.RawURLEncoding.DecodedLen(len(token)))
+	n, err := base64.RawURLEncoding.Decode(b, token)
+	if err != nil {
+		return ErrCursorInvalid
+	}
+
+	if err := json.Unmarshal(b[:n], (*cursor)(c)); err != nil {
+		return ErrCursorInvalid
+	}
+
+	return nil
+}
+
+// Match reports whether beer passes the filter.
+func (f BeerFilter) Match(b *Beer) bool {
+	if f.Deleted != (b.DeletedAt != nil) {
+		return false
+	}
+
+	if f.Name != "" && !strings.Contains(strings.ToLower(b.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+
+	if f.Currency != "" && b.Price.Currency != f.Currency {
+		return false
+	}
+
+	if f.MinAmount != 0 && b.Price.Amount < f.MinAmount {
+		return false
+	}
+
+	if f.MaxAmount != 0 && b.Price.Amount > f.MaxAmount {
+		return false
+	}
+
+	if f.BreweryID.UUID != uuid.Nil && (b.BreweryID == nil || *b.BreweryID != f.BreweryID) {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !b.HasTag(tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Less reports whether beer a is listed before beer b.
+func (q *BeerQuery) Less(a, b *Beer) bool {
+	return q.before(a.sortKey(q.Sort), a.ID, b.sortKey(q.Sort), b.ID)
+}
+
+// Follows reports whether beer is listed after the query cursor.
+func (q *BeerQuery) Follows(b *Beer) bool {
+	if q.After == nil {
+		return true
+	}
+	return q.before(q.After.Key, q.After.ID, b.sortKey(q.Sort), b.ID)
+}
+
+func (q *BeerQuery) before(keyA string, idA ID, keyB string, idB ID) bool {
+	if keyA == keyB {
+		keyA, keyB = idA.String(), idB.String()
+	}
+	if q.Desc {
+		return keyA > keyB
+	}
+	return keyA < keyB
+}
+
+// sortKeyTimeLayout formats time sort keys so that they order lexically.
+// It is also understood by databases as a timestamp literal.
+const sortKeyTimeLayout = "2006-01-02T15:04:05.000000"
+
+// sortKey returns the value beer is ordered by, encoded
+// so that comparing keys as strings matches the sort order.
+func (b *Beer) sortKey(sort BeerSort) string {
+	switch sort {
+	case SortByCreatedAt:
+		return b.CreatedAt.UTC().Format(sortKeyTimeLayout)
+	case SortByPrice:
+		return fmt.Sprintf("%020d", b.Price.Amount)
+	default:
+		return b.Name
+	}
+}
@@ -10,21 +10,46 @@ type Beer struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 
+	// Version is incremented on every save. A beer is only saved
+	// when its version matches the stored one, zero if it is new.
+	Version uint `json:"version"`
+
+	// DeletedAt is set once beer is moved to trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+
 	Name  string `json:"name"`
 	Price Price  `json:"price"`
+
+	// BreweryID is the stored brewery of beer, if any.
+	BreweryID *ID `json:"breweryId,omitempty"`
+
+	// Catalogue details are optional, zero values being unknown.
+	// Brewery names a brewery which is not stored. It is cleared
+	// once saved when BreweryID is set, the stored brewery having
+	// the name.
+	Brewery string `json:"brewery,omitempty"`
+	Style   string `json:"style,omitempty"`
+
+	// ABV is alcohol by volume, in percent.
+	ABV float64 `json:"abv,omitempty"`
+
+	// IBU is bitterness, in international bitterness units.
+	IBU uint `json:"ibu,omitempty"`
+
+	// Volume is the container volume, in milliliters.
+	Volume uint `json:"volume,omitempty"`
+
+	Description string `json:"description,omitempty"`
+
+	// Tags group beers, e.g. "seasonal" or "gluten-free".
+	// They are normalized, sorted and unique once saved.
+	Tags []string `json:"tags,omitempty"`
 }
 
 type ID struct {
 	uuid.UUID
 }
 
-type Currency string
-
-var (
-	EUR Currency = "Euro"
-	USD Currency = "Dollar"
-)
-
 type Price struct {
 	Currency Currency `json:"currency"`
 	Amount   uint     `json:"amount"`
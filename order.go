@@ -0,0 +1,307 @@
+package burp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bounds of customer orders.
+const (
+	MaxCustomerLength = 100
+	MaxOrderLines     = 100
+	MaxOrderQuantity  = 1000
+)
+
+var (
+	ErrCartEmpty              = Error("cart has no beer")
+	ErrCartTooLarge           = Errorf("cart exceed %d beers", MaxOrderLines)
+	ErrCartBeerDuplicate      = Error("cart has the same beer twice")
+	ErrCustomerMissing        = Error("customer is missing")
+	ErrCustomerTooLong        = Errorf("customer exceed %d character", MaxCustomerLength)
+	ErrOrderQuantityInvalid   = Errorf("ordered quantity must be between 1 and %d", MaxOrderQuantity)
+	ErrOrderBeerNotFound      = Error("ordered beer not found")
+	ErrOrderStatusInvalid     = Error("order status must be pending, paid or cancelled")
+	ErrOrderTransitionInvalid = Error("order status cannot change so")
+	ErrOrdersUnavailable      = Error("orders are not recorded")
+)
+
+// OrderStatus is the state of an order. Orders are placed pending,
+// then paid or cancelled once and for all.
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderPaid      OrderStatus = "paid"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions lists the statuses an order can move to from each status.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending: {OrderPaid, OrderCancelled},
+}
+
+// CanBecome reports whether an order can move from status s to status to.
+func (s OrderStatus) CanBecome(to OrderStatus) bool {
+	for _, next := range orderTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Order is a customer purchase of beers.
+type Order struct {
+	ID        ID        `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version is incremented on every save, as for beers.
+	Version uint `json:"version"`
+
+	Customer string      `json:"customer"`
+	Status   OrderStatus `json:"status"`
+
+	// Lines never change once the order is placed.
+	Lines []OrderLine `json:"lines"`
+}
+
+// OrderLine is a beer of an order, named and priced as it was
+// when ordered, so that later changes of the beer leave it as is.
+type OrderLine struct {
+	BeerID   ID     `json:"beerId"`
+	Name     string `json:"name"`
+	Quantity uint   `json:"quantity"`
+
+	// Price is that of one unit.
+	Price Price `json:"price"`
+}
+
+// Total returns the price of all units of the line.
+func (l OrderLine) Total() Price {
+	return Price{Currency: l.Price.Currency, Amount: l.Price.Amount * l.Quantity}
+}
+
+// Totals returns the total of order lines in each currency, by
+// currency code, as prices in distinct currencies do not add up.
+func (o *Order) Totals() []Price {
+	amounts := make(map[Currency]uint)
+	for _, l := range o.Lines {
+		amounts[l.Price.Currency] += l.Total().Amount
+	}
+
+	totals := make([]Price, 0, len(amounts))
+	for currency, amount := range amounts {
+		totals = append(totals, Price{Currency: currency, Amount: amount})
+	}
+
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Currency < totals[j].Currency })
+	return totals
+}
+
+func (o Order) MarshalJSON() ([]byte, error) {
+	type order Order
+	return json.Marshal(struct {
+		order
+		Totals []Price `json:"totals"`
+	}{order(o), o.Totals()})
+}
+
+// Transition moves order to status to at given time, failing with
+// ErrOrderTransitionInvalid unless its status can become to.
+func (o *Order) Transition(to OrderStatus, at time.Time) error {
+	if !o.Status.CanBecome(to) {
+		return Errorf("unable to move order from %s to %s: %w", o.Status, to, ErrOrderTransitionInvalid)
+	}
+
+	o.Status = to
+	o.UpdatedAt = at
+	return nil
+}
+
+// Cart lists beers a customer orders.
+type Cart struct {
+	Customer string     `json:"customer"`
+	Items    []CartItem `json:"items"`
+}
+
+type CartItem struct {
+	BeerID   ID   `json:"beerId"`
+	Quantity uint `json:"quantity"`
+}
+
+type OrderRepo interface {
+	OrderSaver
+	OrderSelector
+	OrderLister
+}
+
+// OrderSaver inserts order when its version is zero, otherwise updates
+// its status if its version still matches the stored one, failing with
+// ErrVersionConflict if not. Version is then incremented.
+type OrderSaver interface {
+	SaveOrder(ctx context.Context, order *Order) error
+}
+
+type OrderSelector interface {
+	SelectOrder(ctx context.Context, id ID) (*Order, error)
+}
+
+// OrderLister lists at most q.Limit orders having q.Status,
+// any when empty, newest first then by id.
+type OrderLister interface {
+	ListOrders(ctx context.Context, q OrderQuery) ([]*Order, error)
+}
+
+// OrderQuery describes orders to list.
+type OrderQuery struct {
+	Status OrderStatus
+	Limit  int
+}
+
+// Checkout places a pending order of the beers of cart, at their
+// current price. Beers must be stored and out of trash.
+func (b *Brewer) Checkout(ctx context.Context, cart *Cart) (*Order, error) {
+	if b.OrderRepo == nil {
+		return nil, ErrOrdersUnavailable
+	}
+
+	cart.Customer = strings.TrimSpace(cart.Customer)
+	if err := cart.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := b.now()
+	order := &Order{
+		ID:        ID{UUID: uuid.New()},
+		CreatedAt: now,
+		UpdatedAt: now,
+		Customer:  cart.Customer,
+		Status:    OrderPending,
+		Lines:     make([]OrderLine, len(cart.Items)),
+	}
+
+	err := b.inTx(ctx, func(ctx context.Context) error {
+		for i, item := range cart.Items {
+			line, err := b.orderLine(ctx, item, now)
+			if err != nil {
+				return err
+			}
+			order.Lines[i] = *line
+		}
+
+		if err := b.OrderRepo.SaveOrder(ctx, order); err != nil {
+			return fmt.Errorf("unable to save order %+v: %w", order, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// orderLine snapshots the beer of item as it is at given time.
+func (b *Brewer) orderLine(ctx context.Context, item CartItem, at time.Time) (*OrderLine, error) {
+	beer, err := b.BeerRepo.SelectBeer(ctx, item.BeerID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, Errorf("invalid beer id %q: %w", item.BeerID, ErrOrderBeerNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to select ordered beer %q: %w", item.BeerID, err)
+	}
+
+	price, err := b.priceOf(ctx, beer, at)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select price of ordered beer %q: %w", beer.ID, err)
+	}
+
+	return &OrderLine{BeerID: beer.ID, Name: beer.Name, Quantity: item.Quantity, Price: *price}, nil
+}
+
+func (b *Brewer) SelectOrder(ctx context.Context, id ID) (*Order, error) {
+	if b.OrderRepo == nil {
+		return nil, ErrOrdersUnavailable
+	}
+
+	order, err := b.OrderRepo.SelectOrder(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select order %q: %w", id, err)
+	}
+
+	return order, nil
+}
+
+// ListOrders returns orders newest first, at most q.Limit.
+func (b *Brewer) ListOrders(ctx context.Context, q OrderQuery) ([]*Order, error) {
+	if b.OrderRepo == nil {
+		return nil, ErrOrdersUnavailable
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	orders, err := b.OrderRepo.ListOrders(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list orders with query %+v: %w", q, err)
+	}
+
+	if orders == nil {
+		orders = []*Order{}
+	}
+
+	return orders, nil
+}
+
+// PayOrder marks a pending order as paid, and returns it.
+func (b *Brewer) PayOrder(ctx context.Context, id ID) (*Order, error) {
+	return b.transitionOrder(ctx, id, OrderPaid)
+}
+
+// CancelOrder cancels a pending order, and returns it.
+func (b *Brewer) CancelOrder(ctx context.Context, id ID) (*Order, error) {
+	return b.transitionOrder(ctx, id, OrderCancelled)
+}
+
+func (b *Brewer) transitionOrder(ctx context.Context, id ID, to OrderStatus) (*Order, error) {
+	if b.OrderRepo == nil {
+		return nil, ErrOrdersUnavailable
+	}
+
+	var order *Order
+	err := b.inTx(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = b.OrderRepo.SelectOrder(ctx, id); err != nil {
+			return fmt.Errorf("unable to select order %q: %w", id, err)
+		}
+
+		if err := order.Transition(to, b.now()); err != nil {
+			return err
+		}
+
+		if err := b.OrderRepo.SaveOrder(ctx, order); err != nil {
+			return fmt.Errorf("unable to save order %+v: %w", order, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
@@ -0,0 +1,132 @@
+package burp_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo/memory"
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestOrderTotals(t *testing.T) {
+	order := &burp.Order{Lines: []burp.OrderLine{
+		{Quantity: 2, Price: burp.Price{Currency: burp.USD, Amount: 400}},
+		{Quantity: 3, Price: burp.Price{Currency: burp.EUR, Amount: 250}},
+		{Quantity: 1, Price: burp.Price{Currency: burp.USD, Amount: 150}},
+	}}
+
+	want := []burp.Price{{Currency: burp.EUR, Amount: 750}, {Currency: burp.USD, Amount: 950}}
+	if diff := cmp.Diff(want, order.Totals()); diff != "" {
+		t.Errorf("Totals() returned unexpected totals, (-want/+got):\n%s", diff)
+	}
+}
+
+func TestOrderTransition(t *testing.T) {
+	tests := []struct {
+		from, to burp.OrderStatus
+		ok       bool
+	}{
+		{from: burp.OrderPending, to: burp.OrderPaid, ok: true},
+		{from: burp.OrderPending, to: burp.OrderCancelled, ok: true},
+		{from: burp.OrderPending, to: burp.OrderPending},
+		{from: burp.OrderPaid, to: burp.OrderCancelled},
+		{from: burp.OrderCancelled, to: burp.OrderPaid},
+	}
+
+	for _, test := range tests {
+		order := &burp.Order{Status: test.from}
+		err := order.Transition(test.to, burptest.RandTime())
+
+		if test.ok && err != nil || !test.ok && !errors.Is(err, burp.ErrOrderTransitionInvalid) {
+			t.Errorf("Transition(%s, at) of a %s order returned unexpected error %v", test.to, test.from, err)
+		}
+	}
+}
+
+func TestCartValidate(t *testing.T) {
+	id := burptest.RandBeer().ID
+	tests := []struct {
+		name string
+		cart burp.Cart
+		want error
+	}{
+		{name: "Valid", cart: burp.Cart{Customer: "Ann", Items: []burp.CartItem{{BeerID: id, Quantity: 2}}}},
+		{name: "NoCustomer", cart: burp.Cart{Items: []burp.CartItem{{BeerID: id, Quantity: 2}}}, want: burp.ErrCustomerMissing},
+		{name: "Empty", cart: burp.Cart{Customer: "Ann"}, want: burp.ErrCartEmpty},
+		{name: "NoQuantity", cart: burp.Cart{Customer: "Ann", Items: []burp.CartItem{{BeerID: id}}}, want: burp.ErrOrderQuantityInvalid},
+		{
+			name: "Duplicate",
+			cart: burp.Cart{Customer: "Ann", Items: []burp.CartItem{{BeerID: id, Quantity: 1}, {BeerID: id, Quantity: 1}}},
+			want: burp.ErrCartBeerDuplicate,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.cart.Validate(); !errors.Is(err, test.want) {
+				t.Errorf("cart %+v Validate() returned error %v, want %v", test.cart, err, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := &burptest.Clock{Time: now}
+	brewer := &burp.Brewer{BeerRepo: memRepo, PriceRepo: memRepo, OrderRepo: memRepo, Tx: memRepo, Clock: clock}
+
+	beer := burptest.RandBeer()
+	if err := brewer.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	cart := &burp.Cart{Customer: " Ann ", Items: []burp.CartItem{{BeerID: beer.ID, Quantity: 3}}}
+	order, err := brewer.Checkout(ctx, cart)
+	if err != nil {
+		t.Fatalf("Checkout(ctx, %+v) returned unexpected error %s", cart, err)
+	}
+
+	want := &burp.Order{
+		ID:        order.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+		Customer:  "Ann",
+		Status:    burp.OrderPending,
+		Lines:     []burp.OrderLine{{BeerID: beer.ID, Name: beer.Name, Quantity: 3, Price: beer.Price}},
+	}
+	if diff := cmp.Diff(want, order); diff != "" {
+		t.Errorf("Checkout(ctx, cart) returned unexpected order, (-want/+got):\n%s", diff)
+	}
+
+	// orders keep the price beers had when ordered
+	beer.Price.Amount++
+	if err := brewer.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	clock.Time = now.Add(time.Hour)
+	paid, err := brewer.PayOrder(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("PayOrder(ctx, %s) returned unexpected error %s", order.ID, err)
+	}
+
+	want.Status, want.UpdatedAt, want.Version = burp.OrderPaid, clock.Time, 2
+	if diff := cmp.Diff(want, paid); diff != "" {
+		t.Errorf("PayOrder(ctx, id) returned unexpected order, (-want/+got):\n%s", diff)
+	}
+
+	if _, err := brewer.CancelOrder(ctx, order.ID); !errors.Is(err, burp.ErrOrderTransitionInvalid) {
+		t.Errorf("CancelOrder(ctx, %s) of a paid order returned unexpected error:\ngot %v want %v", order.ID, err, burp.ErrOrderTransitionInvalid)
+	}
+
+	cart = &burp.Cart{Customer: "Ann", Items: []burp.CartItem{{BeerID: burptest.RandBeer().ID, Quantity: 1}}}
+	if _, err := brewer.Checkout(ctx, cart); !errors.Is(err, burp.ErrOrderBeerNotFound) {
+		t.Errorf("Checkout(ctx, %+v) of a missing beer returned unexpected error:\ngot %v want %v", cart, err, burp.ErrOrderBeerNotFound)
+	}
+}
@@ -0,0 +1,34 @@
+package burp
+
+import "time"
+
+// PriceChange sets the price of a beer from a given instant
+// until the next change. Changes are recorded whenever a beer
+// is saved with a new price, and can be scheduled in the future.
+// Beer.Price remains the price set by the beer last save.
+type PriceChange struct {
+	BeerID ID        `json:"beerId"`
+	Price  Price     `json:"price"`
+	From   time.Time `json:"from"`
+}
+
+// PricePeriod is the price of a beer over a validity range.
+// Until is exclusive, and nil for the last known price.
+type PricePeriod struct {
+	Price Price      `json:"price"`
+	From  time.Time  `json:"from"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// PricePeriods turns changes of a beer, oldest first,
+// into consecutive validity ranges.
+func PricePeriods(changes []*PriceChange) []*PricePeriod {
+	periods := make([]*PricePeriod, len(changes))
+	for i, c := range changes {
+		periods[i] = &PricePeriod{Price: c.Price, From: c.From}
+		if i > 0 {
+			periods[i-1].Until = &periods[i].From
+		}
+	}
+	return periods
+}
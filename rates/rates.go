@@ -0,0 +1,99 @@
+// Package rates provides exchange rates to convert beer prices,
+// set statically or loaded from a JSON file.
+package rates
+
+import (
+	"burp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Static provides fixed rates relative to a base currency.
+type Static struct {
+	Base burp.Currency `json:"base"`
+
+	// Rates tells how many units of each currency
+	// a unit of Base is worth. Base is worth 1.
+	Rates map[burp.Currency]float64 `json:"rates"`
+}
+
+func (s *Static) Validate() error {
+	if _, ok := burp.LookupCurrency(s.Base); !ok {
+		return fmt.Errorf("base currency %q not supported", s.Base)
+	}
+
+	for c, rate := range s.Rates {
+		if _, ok := burp.LookupCurrency(c); !ok {
+			return fmt.Errorf("currency %q not supported", c)
+		}
+
+		if rate <= 0 {
+			return fmt.Errorf("rate of %s must be positive, got %v", c, rate)
+		}
+	}
+
+	return nil
+}
+
+// Rate returns how many units of to a unit of from is worth,
+// crossing rates of both relative to the base currency.
+func (s *Static) Rate(ctx context.Context, from, to burp.Currency) (float64, error) {
+	fromRate, ok := s.rate(from)
+	if !ok {
+		return 0, burp.ErrExchangeRateNotFound
+	}
+
+	toRate, ok := s.rate(to)
+	if !ok {
+		return 0, burp.ErrExchangeRateNotFound
+	}
+
+	return toRate / fromRate, nil
+}
+
+func (s *Static) rate(c burp.Currency) (float64, bool) {
+	if c == s.Base {
+		return 1, true
+	}
+
+	rate, ok := s.Rates[c]
+	return rate, ok
+}
+
+// Load reads static rates written as JSON, such as:
+//
+//	{"base": "EUR", "rates": {"USD": 1.08, "GBP": 0.86}}
+func Load(r io.Reader) (*Static, error) {
+	var s Static
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("unable to decode rates: %w", err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rates: %w", err)
+	}
+
+	return &s, nil
+}
+
+// LoadFile reads static rates from the JSON file at path, as Load does.
+func LoadFile(path string) (*Static, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open rates: %w", err)
+	}
+	defer f.Close()
+
+	s, err := Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load %q: %w", path, err)
+	}
+
+	return s, nil
+}
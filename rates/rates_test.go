@@ -0,0 +1,58 @@
+package rates_test
+
+import (
+	"burp"
+	"burp/rates"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	s, err := rates.Load(strings.NewReader(`{"base": "EUR", "rates": {"USD": 1.25, "gbp": 0.8}}`))
+	if err != nil {
+		t.Fatalf("Load returned unexpected error %s", err)
+	}
+
+	tests := []struct {
+		from, to burp.Currency
+		want     float64
+	}{
+		{from: burp.EUR, to: burp.USD, want: 1.25},
+		{from: burp.USD, to: burp.EUR, want: 0.8},
+		{from: burp.USD, to: "GBP", want: 0.64},
+		{from: burp.EUR, to: burp.EUR, want: 1},
+	}
+
+	for _, test := range tests {
+		got, err := s.Rate(context.Background(), test.from, test.to)
+		if err != nil {
+			t.Fatalf("Rate(ctx, %s, %s) returned unexpected error %s", test.from, test.to, err)
+		}
+
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("Rate(ctx, %s, %s) returned %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+
+	if _, err := s.Rate(context.Background(), burp.EUR, "JPY"); !errors.Is(err, burp.ErrExchangeRateNotFound) {
+		t.Errorf("Rate(ctx, EUR, JPY) returned error %v, want %v", err, burp.ErrExchangeRateNotFound)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown base":     `{"base": "XXX", "rates": {}}`,
+		"unknown currency": `{"base": "EUR", "rates": {"XXX": 1}}`,
+		"negative rate":    `{"base": "EUR", "rates": {"USD": -1}}`,
+		"unknown field":    `{"base": "EUR", "rate": {"USD": 1}}`,
+	}
+
+	for description, input := range tests {
+		if _, err := rates.Load(strings.NewReader(input)); err == nil {
+			t.Errorf("Load with %s returned no error", description)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package repo
 
 import (
+	"burp"
 	"errors"
 	"fmt"
 )
 
-var ErrNotFound = errors.New("resource not found")
+// ErrNotFound is wrapped by repositories errors when what is
+// selected, updated or removed is not stored. It is that of burp,
+// for domain code to tell missing resources without importing repo.
+var ErrNotFound = burp.ErrNotFound
 
 type Err struct {
 	err error
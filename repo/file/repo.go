@@ -0,0 +1,351 @@
+package file
+
+import (
+	"burp"
+	"burp/repo"
+	"burp/repo/memory"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// formatVersion is the version of file content layout,
+// to be incremented on incompatible changes.
+const formatVersion = 1
+
+type content struct {
+	Version int `json:"version"`
+	memory.State
+}
+
+// Repo stores beers in memory and persists them to a JSON file
+// after each change, before the change returns. The whole file is
+// rewritten each time, which suits small deployments.
+//
+// Writes are atomic: content goes to a temporary file which then
+// replaces the previous one, so a crash leaves either the previous
+// or the new content, never a partial one. A failed write is undone
+// in memory, and reads wait for changes to be persisted or undone,
+// so that what is read is what is persisted.
+//
+// A file must not be opened by several repos at once.
+type Repo struct {
+	path string
+
+	// mu serializes changes, so that they are persisted in order,
+	// and holds reads back until changes are persisted or undone
+	mu        sync.RWMutex
+	mem       *memory.Repo
+	persisted memory.State
+}
+
+// Open loads repo from file at path, created when missing.
+// Temporary files left by a crash while writing are removed.
+func Open(path string) (*Repo, error) {
+	r := &Repo{path: path, mem: memory.New()}
+
+	if err := removeTempFiles(path); err != nil {
+		return nil, fmt.Errorf("unable to recover %q: %w", path, err)
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		r.persisted = r.mem.State()
+		if err := write(path, r.persisted); err != nil {
+			return nil, fmt.Errorf("unable to create %q: %w", path, err)
+		}
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %q: %w", path, err)
+	}
+
+	var c content
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("unable to decode %q: %w", path, err)
+	}
+
+	if c.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported version %d of %q, want %d", c.Version, path, formatVersion)
+	}
+
+	r.mem.SetState(c.State)
+	r.persisted = r.mem.State()
+	return r, nil
+}
+
+// tempPattern returns the pattern of temporary files written
+// before replacing file at path.
+func tempPattern(path string) string {
+	return "." + filepath.Base(path) + ".tmp-*"
+}
+
+func removeTempFiles(path string) error {
+	names, err := filepath.Glob(filepath.Join(filepath.Dir(path), tempPattern(path)))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := os.Remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write replaces file at path with s atomically.
+func write(path string, s memory.State) error {
+	b, err := json.MarshalIndent(content{Version: formatVersion, State: s}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, tempPattern(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// sync directory so that the rename itself survives a crash
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// txKey keys the repo whose transaction a context carries.
+type txKey struct{}
+
+// RunInTx runs fn in a transaction of memory, then persists its
+// changes at once. They are undone when fn fails or they cannot be
+// persisted. Other changes wait until fn returns, unless made with
+// the context given to fn.
+func (r *Repo) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.mem.RunInTx(ctx, func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, txKey{}, r))
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.persist()
+}
+
+func (r *Repo) inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) == r
+}
+
+// rlock read locks r, unless ctx carries its transaction whose
+// changes are read, and returns the function to unlock it.
+func (r *Repo) rlock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
+// change applies fn to memory then persists the result,
+// undoing fn when it cannot be persisted. Within a transaction,
+// it is persisted with other changes on commit.
+func (r *Repo) change(ctx context.Context, fn func(m *memory.Repo) error) error {
+	if r.inTx(ctx) {
+		return fn(r.mem)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := fn(r.mem); err != nil {
+		return err
+	}
+
+	return r.persist()
+}
+
+// persist writes memory to file, undoing changes since
+// last write when it cannot. Caller holds r.mu.
+func (r *Repo) persist() error {
+	s := r.mem.State()
+	if err := write(r.path, s); err != nil {
+		r.mem.SetState(r.persisted)
+		return repo.Errorf("unable to persist beers to %q: %w", r.path, err)
+	}
+
+	r.persisted = s
+	return nil
+}
+
+func (r *Repo) SaveBeer(ctx context.Context, beer *burp.Beer) error {
+	before := *beer
+	err := r.change(ctx, func(m *memory.Repo) error { return m.SaveBeer(ctx, beer) })
+	if err != nil {
+		*beer = before
+	}
+	return err
+}
+
+func (r *Repo) SaveBeers(ctx context.Context, beers []*burp.Beer) error {
+	given := make([]burp.Beer, len(beers))
+	for i, beer := range beers {
+		given[i] = *beer
+	}
+
+	err := r.change(ctx, func(m *memory.Repo) error { return m.SaveBeers(ctx, beers) })
+	if err != nil {
+		for i, beer := range beers {
+			*beer = given[i]
+		}
+	}
+	return err
+}
+
+func (r *Repo) RemoveBeer(ctx context.Context, id burp.ID, at time.Time) error {
+	return r.change(ctx, func(m *memory.Repo) error { return m.RemoveBeer(ctx, id, at) })
+}
+
+func (r *Repo) RestoreBeer(ctx context.Context, id burp.ID) error {
+	return r.change(ctx, func(m *memory.Repo) error { return m.RestoreBeer(ctx, id) })
+}
+
+func (r *Repo) PurgeBeer(ctx context.Context, id burp.ID) error {
+	return r.change(ctx, func(m *memory.Repo) error { return m.PurgeBeer(ctx, id) })
+}
+
+func (r *Repo) SaveBeerEvent(ctx context.Context, e *burp.BeerEvent) error {
+	return r.change(ctx, func(m *memory.Repo) error { return m.SaveBeerEvent(ctx, e) })
+}
+
+func (r *Repo) SavePriceChange(ctx context.Context, c *burp.PriceChange) error {
+	return r.change(ctx, func(m *memory.Repo) error { return m.SavePriceChange(ctx, c) })
+}
+
+func (r *Repo) SaveBrewery(ctx context.Context, brewery *burp.Brewery) error {
+	before := *brewery
+	err := r.change(ctx, func(m *memory.Repo) error { return m.SaveBrewery(ctx, brewery) })
+	if err != nil {
+		*brewery = before
+	}
+	return err
+}
+
+func (r *Repo) RemoveBrewery(ctx context.Context, id burp.ID) error {
+	return r.change(ctx, func(m *memory.Repo) error { return m.RemoveBrewery(ctx, id) })
+}
+
+func (r *Repo) MoveStock(ctx context.Context, m *burp.StockMovement) (stock *burp.Stock, err error) {
+	err = r.change(ctx, func(mem *memory.Repo) error {
+		stock, err = mem.MoveStock(ctx, m)
+		return err
+	})
+	return stock, err
+}
+
+func (r *Repo) SetStockThreshold(ctx context.Context, t *burp.StockThreshold) (stock *burp.Stock, err error) {
+	err = r.change(ctx, func(m *memory.Repo) error {
+		stock, err = m.SetStockThreshold(ctx, t)
+		return err
+	})
+	return stock, err
+}
+
+func (r *Repo) SaveOrder(ctx context.Context, order *burp.Order) error {
+	before := *order
+	err := r.change(ctx, func(m *memory.Repo) error { return m.SaveOrder(ctx, order) })
+	if err != nil {
+		*order = before
+	}
+	return err
+}
+
+func (r *Repo) SelectBeer(ctx context.Context, id burp.ID) (*burp.Beer, error) {
+	defer r.rlock(ctx)()
+	return r.mem.SelectBeer(ctx, id)
+}
+
+func (r *Repo) ListBeers(ctx context.Context, q burp.BeerQuery) ([]*burp.Beer, error) {
+	defer r.rlock(ctx)()
+	return r.mem.ListBeers(ctx, q)
+}
+
+func (r *Repo) SearchBeers(ctx context.Context, s burp.BeerSearch) ([]*burp.BeerMatch, error) {
+	defer r.rlock(ctx)()
+	return r.mem.SearchBeers(ctx, s)
+}
+
+func (r *Repo) SelectBeerHistory(ctx context.Context, id burp.ID) ([]*burp.BeerEvent, error) {
+	defer r.rlock(ctx)()
+	return r.mem.SelectBeerHistory(ctx, id)
+}
+
+func (r *Repo) SelectPriceChanges(ctx context.Context, id burp.ID) ([]*burp.PriceChange, error) {
+	defer r.rlock(ctx)()
+	return r.mem.SelectPriceChanges(ctx, id)
+}
+
+func (r *Repo) SelectPriceAt(ctx context.Context, id burp.ID, at time.Time) (*burp.PriceChange, error) {
+	defer r.rlock(ctx)()
+	return r.mem.SelectPriceAt(ctx, id, at)
+}
+
+func (r *Repo) SelectBrewery(ctx context.Context, id burp.ID) (*burp.Brewery, error) {
+	defer r.rlock(ctx)()
+	return r.mem.SelectBrewery(ctx, id)
+}
+
+func (r *Repo) ListBreweries(ctx context.Context, q burp.BreweryQuery) ([]*burp.Brewery, error) {
+	defer r.rlock(ctx)()
+	return r.mem.ListBreweries(ctx, q)
+}
+
+func (r *Repo) ListStock(ctx context.Context, q burp.StockQuery) ([]*burp.Stock, error) {
+	defer r.rlock(ctx)()
+	return r.mem.ListStock(ctx, q)
+}
+
+func (r *Repo) SelectOrder(ctx context.Context, id burp.ID) (*burp.Order, error) {
+	defer r.rlock(ctx)()
+	return r.mem.SelectOrder(ctx, id)
+}
+
+func (r *Repo) ListOrders(ctx context.Context, q burp.OrderQuery) ([]*burp.Order, error) {
+	defer r.rlock(ctx)()
+	return r.mem.ListOrders(ctx, q)
+}
@@ -0,0 +1,241 @@
+package file_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo"
+	"burp/repo/file"
+	"burp/repo/repotest"
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConformance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "beers.json")
+
+	r, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q) returned unexpected error %s", path, err)
+	}
+
+	repotest.TestBeerRepo(t, r)
+	repotest.TestTxRunner(t, r)
+	repotest.TestBreweryRepo(t, r)
+	repotest.TestStockRepo(t, r)
+	repotest.TestOrderRepo(t, r)
+}
+
+func TestTxPersistsOnCommit(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "beers.json")
+
+	r, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q) returned unexpected error %s", path, err)
+	}
+
+	beer := burptest.RandBeer()
+	err = r.RunInTx(ctx, func(ctx context.Context) error {
+		if err := r.SaveBeer(ctx, beer); err != nil {
+			return err
+		}
+
+		// changes are written on commit only
+		persisted, err := file.Open(path)
+		if err != nil {
+			return err
+		}
+		if _, err := persisted.SelectBeer(ctx, beer.ID); !errors.Is(err, repo.ErrNotFound) {
+			t.Errorf("SelectBeer(ctx, %s) of a file reopened before commit returned error %v, want %v", beer.ID, err, repo.ErrNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunInTx(ctx, fn) returned unexpected error %s", err)
+	}
+
+	reopened, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q) again returned unexpected error %s", path, err)
+	}
+
+	if _, err := reopened.SelectBeer(ctx, beer.ID); err != nil {
+		t.Errorf("SelectBeer(ctx, %s) of a file reopened after commit returned error %s", beer.ID, err)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "beers.json")
+
+	r, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q) returned unexpected error %s", path, err)
+	}
+
+	beer := burptest.RandBeer()
+	if err := r.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	removed := burptest.RandBeer()
+	if err := r.SaveBeer(ctx, removed); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", removed, err)
+	}
+	if err := r.RemoveBeer(ctx, removed.ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", removed.ID, err)
+	}
+
+	event := &burp.BeerEvent{BeerID: beer.ID, Kind: burp.BeerCreated, Time: time.Now().UTC(), After: beer}
+	if err := r.SaveBeerEvent(ctx, event); err != nil {
+		t.Fatalf("SaveBeerEvent(ctx, %+v) returned unexpected error %s", event, err)
+	}
+
+	change := &burp.PriceChange{BeerID: beer.ID, Price: beer.Price, From: time.Now().UTC()}
+	if err := r.SavePriceChange(ctx, change); err != nil {
+		t.Fatalf("SavePriceChange(ctx, %+v) returned unexpected error %s", change, err)
+	}
+
+	reopened, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q) again returned unexpected error %s", path, err)
+	}
+
+	got, err := reopened.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) after reopening returned unexpected error %s", beer.ID, err)
+	}
+
+	if diff := cmp.Diff(beer, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) after reopening returned unexpected beer, (-want/+got):\n%s", beer.ID, diff)
+	}
+
+	if _, err := reopened.SelectBeer(ctx, removed.ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("SelectBeer(ctx, %s) of removed beer after reopening returned error %v, want %v", removed.ID, err, repo.ErrNotFound)
+	}
+
+	history, err := reopened.SelectBeerHistory(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeerHistory(ctx, %s) after reopening returned unexpected error %s", beer.ID, err)
+	}
+
+	if diff := cmp.Diff([]*burp.BeerEvent{event}, history); diff != "" {
+		t.Errorf("SelectBeerHistory(ctx, %s) after reopening returned unexpected events, (-want/+got):\n%s", beer.ID, diff)
+	}
+
+	changes, err := reopened.SelectPriceChanges(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectPriceChanges(ctx, %s) after reopening returned unexpected error %s", beer.ID, err)
+	}
+
+	if diff := cmp.Diff([]*burp.PriceChange{change}, changes); diff != "" {
+		t.Errorf("SelectPriceChanges(ctx, %s) after reopening returned unexpected changes, (-want/+got):\n%s", beer.ID, diff)
+	}
+}
+
+func TestOpenRemovesTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "beers.json")
+	tmp := filepath.Join(dir, ".beers.json.tmp-123")
+
+	if err := os.WriteFile(tmp, []byte(`{"version": 1, "beers": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.Open(path); err != nil {
+		t.Fatalf("Open(%q) returned unexpected error %s", path, err)
+	}
+
+	if _, err := os.Stat(tmp); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Open(%q) kept temporary file %q left by a crash", path, tmp)
+	}
+}
+
+func TestOpenCorruptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "beers.json")
+
+	if err := os.WriteFile(path, []byte(`{"version": 1, "beers": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.Open(path); err == nil {
+		t.Errorf("Open(%q) of a corrupted file returned no error", path)
+	}
+}
+
+func TestSaveBeerUndoneWhenNotPersisted(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "beers.json")
+
+	r, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q) returned unexpected error %s", path, err)
+	}
+
+	// a non-empty directory cannot be replaced by the written file
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocker"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	beer := burptest.RandBeer()
+	if err := r.SaveBeer(ctx, beer); err == nil {
+		t.Fatalf("SaveBeer(ctx, %+v) not persisted returned no error", beer)
+	}
+
+	if beer.Version != 0 {
+		t.Errorf("SaveBeer(ctx, %+v) not persisted changed beer version to %d, want 0", beer, beer.Version)
+	}
+
+	if _, err := r.SelectBeer(ctx, beer.ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("SelectBeer(ctx, %s) of beer not persisted returned error %v, want %v", beer.ID, err, repo.ErrNotFound)
+	}
+}
+
+func TestReadDuringFailedWrite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "beers.json")
+
+	r, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q) returned unexpected error %s", path, err)
+	}
+
+	// a non-empty directory cannot be replaced by the written file
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocker"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	beer := burptest.RandBeer()
+	read := make(chan error)
+	err = r.RunInTx(ctx, func(txCtx context.Context) error {
+		if err := r.SaveBeer(txCtx, beer); err != nil {
+			return err
+		}
+
+		// read outside of the transaction while it is being committed
+		go func() {
+			_, err := r.SelectBeer(ctx, beer.ID)
+			read <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("RunInTx(ctx, fn) not persisted returned no error")
+	}
+
+	if err := <-read; !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("SelectBeer(ctx, %s) during a write not persisted returned error %v, want %v", beer.ID, err, repo.ErrNotFound)
+	}
+}
@@ -0,0 +1,738 @@
+package memory
+
+import (
+	"burp"
+	"burp/repo"
+	"context"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repo stores beers in memory. It is safe for concurrent use, and
+// never shares its values: saved ones are copied, selected ones are
+// copies, so callers are free to modify them.
+type Repo struct {
+	mu     sync.RWMutex
+	beers  map[burp.ID]*burp.Beer
+	events map[burp.ID][]*burp.BeerEvent
+	prices map[burp.ID][]*burp.PriceChange
+
+	breweries map[burp.ID]*burp.Brewery
+	stock     map[burp.ID]map[string]*burp.Stock
+	orders    map[burp.ID]*burp.Order
+
+	// undo restores, newest first, what the running
+	// transaction changed, nil outside of one
+	undo []func()
+}
+
+func New() *Repo {
+	return &Repo{
+		beers:     make(map[burp.ID]*burp.Beer),
+		events:    make(map[burp.ID][]*burp.BeerEvent),
+		prices:    make(map[burp.ID][]*burp.PriceChange),
+		breweries: make(map[burp.ID]*burp.Brewery),
+		stock:     make(map[burp.ID]map[string]*burp.Stock),
+		orders:    make(map[burp.ID]*burp.Order),
+	}
+}
+
+// txKey keys the repo whose transaction a context carries.
+type txKey struct{}
+
+// RunInTx runs fn with exclusive access to repo, undoing its changes
+// when it fails or panics. Other calls wait until fn returns, unless
+// made with the context given to fn, which must not be used
+// concurrently. Within fn, RunInTx runs nested functions as part of
+// the same transaction.
+//
+// Changes are undone from entries kept as they were before being
+// changed, so that a transaction costs what it changes only.
+func (r *Repo) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.undo = []func(){}
+	committed := false
+	defer func() {
+		if !committed {
+			for i := len(r.undo) - 1; i >= 0; i-- {
+				r.undo[i]()
+			}
+		}
+		r.undo = nil
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, r)); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+func (r *Repo) inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) == r
+}
+
+// keep records how to restore entry key of m as it is, for the running
+// transaction to be undone, if any. Its value is kept as copied by
+// clone, as it may be changed in place.
+func keep[K comparable, V any](r *Repo, m map[K]V, key K, clone func(V) V) {
+	if r.undo == nil {
+		return
+	}
+
+	v, ok := m[key]
+	if ok {
+		v = clone(v)
+	}
+
+	r.undo = append(r.undo, func() {
+		if ok {
+			m[key] = v
+			return
+		}
+		delete(m, key)
+	})
+}
+
+// same returns v, for values of entries only ever replaced.
+func same[V any](v V) V { return v }
+
+func copySlice[T any](s []T) []T { return append([]T(nil), s...) }
+
+// lock locks repo for writing, unless ctx carries its transaction
+// which already does. It returns the function to unlock it.
+func (r *Repo) lock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// rlock locks repo for reading, as lock does for writing.
+func (r *Repo) rlock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
+// SaveBeer inserts beer when its version is zero, otherwise updates it
+// if its version still matches the stored one. Version is then incremented.
+func (r *Repo) SaveBeer(ctx context.Context, beer *burp.Beer) error {
+	defer r.lock(ctx)()
+
+	return r.saveBeer(beer)
+}
+
+// SaveBeers saves beers in order as SaveBeer does. When one cannot be
+// saved, stored beers and given ones are left as they were.
+func (r *Repo) SaveBeers(ctx context.Context, beers []*burp.Beer) error {
+	defer r.lock(ctx)()
+
+	given := make([]burp.Beer, len(beers))
+	stored := make(map[burp.ID]*burp.Beer, len(beers))
+	for i, beer := range beers {
+		given[i] = *beer
+		if _, ok := stored[beer.ID]; !ok {
+			stored[beer.ID] = r.beers[beer.ID]
+		}
+	}
+
+	for _, beer := range beers {
+		if err := r.saveBeer(beer); err != nil {
+			for i, beer := range beers {
+				*beer = given[i]
+			}
+			for id, beer := range stored {
+				if beer == nil {
+					delete(r.beers, id)
+					continue
+				}
+				r.beers[id] = beer
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Repo) saveBeer(beer *burp.Beer) error {
+	stored, ok := r.beers[beer.ID]
+	if ok && (stored.Version != beer.Version || stored.DeletedAt != nil) || !ok && beer.Version != 0 {
+		return repo.Errorf("unable to save beer %q at version %d: %w", beer.ID, beer.Version, burp.ErrVersionConflict)
+	}
+
+	if ok {
+		beer.CreatedAt = stored.CreatedAt
+	}
+
+	keep(r, r.beers, beer.ID, same[*burp.Beer])
+	beer.Version++
+	beer.DeletedAt = nil
+	r.beers[beer.ID] = copyBeer(beer)
+	return nil
+}
+
+func (r *Repo) SelectBeer(ctx context.Context, id burp.ID) (*burp.Beer, error) {
+	defer r.rlock(ctx)()
+
+	beer, ok := r.beers[id]
+	if !ok || beer.DeletedAt != nil {
+		return nil, repo.Errorf("beer with id %q not found: %w", id, repo.ErrNotFound)
+	}
+	return copyBeer(beer), nil
+}
+
+func (r *Repo) RemoveBeer(ctx context.Context, id burp.ID, at time.Time) error {
+	defer r.lock(ctx)()
+
+	beer, ok := r.beers[id]
+	if !ok || beer.DeletedAt != nil {
+		return repo.Errorf("beer with id %q not found: %w", id, repo.ErrNotFound)
+	}
+
+	keep(r, r.beers, id, copyBeer)
+	beer.DeletedAt = &at
+	beer.Version++
+	return nil
+}
+
+func (r *Repo) RestoreBeer(ctx context.Context, id burp.ID) error {
+	defer r.lock(ctx)()
+
+	beer, ok := r.beers[id]
+	if !ok || beer.DeletedAt == nil {
+		return repo.Errorf("beer with id %q not found in trash: %w", id, repo.ErrNotFound)
+	}
+
+	keep(r, r.beers, id, copyBeer)
+	beer.DeletedAt = nil
+	beer.Version++
+	return nil
+}
+
+// PurgeBeer deletes a beer in trash along with its price history
+// and stock.
+// Its events are kept, as they tell it once existed.
+func (r *Repo) PurgeBeer(ctx context.Context, id burp.ID) error {
+	defer r.lock(ctx)()
+
+	beer, ok := r.beers[id]
+	if !ok || beer.DeletedAt == nil {
+		return repo.Errorf("beer with id %q not found in trash: %w", id, repo.ErrNotFound)
+	}
+
+	keep(r, r.beers, id, same[*burp.Beer])
+	keep(r, r.prices, id, same[[]*burp.PriceChange])
+	keep(r, r.stock, id, same[map[string]*burp.Stock])
+	delete(r.beers, id)
+	delete(r.prices, id)
+	delete(r.stock, id)
+	return nil
+}
+
+func (r *Repo) ListBeers(ctx context.Context, q burp.BeerQuery) ([]*burp.Beer, error) {
+	defer r.rlock(ctx)()
+
+	var beers []*burp.Beer
+	for _, beer := range r.beers {
+		if q.Filter.Match(beer) && q.Follows(beer) {
+			beers = append(beers, beer)
+		}
+	}
+
+	sort.Slice(beers, func(i, j int) bool { return q.Less(beers[i], beers[j]) })
+
+	if len(beers) > q.Limit {
+		beers = beers[:q.Limit]
+	}
+
+	for i, beer := range beers {
+		beers[i] = copyBeer(beer)
+	}
+	return beers, nil
+}
+
+func (r *Repo) SearchBeers(ctx context.Context, s burp.BeerSearch) ([]*burp.BeerMatch, error) {
+	defer r.rlock(ctx)()
+
+	var matches []*burp.BeerMatch
+	for _, beer := range r.beers {
+		if beer.DeletedAt != nil {
+			continue
+		}
+
+		if score := burp.Score(beer, s.Text); score > 0 {
+			matches = append(matches, &burp.BeerMatch{Beer: beer, Score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Beer.Name != b.Beer.Name {
+			return a.Beer.Name < b.Beer.Name
+		}
+		return a.Beer.ID.String() < b.Beer.ID.String()
+	})
+
+	if len(matches) > s.Limit {
+		matches = matches[:s.Limit]
+	}
+
+	for _, m := range matches {
+		m.Beer = copyBeer(m.Beer)
+	}
+	return matches, nil
+}
+
+func (r *Repo) SaveBeerEvent(ctx context.Context, e *burp.BeerEvent) error {
+	defer r.lock(ctx)()
+
+	keep(r, r.events, e.BeerID, same[[]*burp.BeerEvent])
+	r.events[e.BeerID] = append(r.events[e.BeerID], copyEvent(e))
+	return nil
+}
+
+func (r *Repo) SelectBeerHistory(ctx context.Context, id burp.ID) ([]*burp.BeerEvent, error) {
+	defer r.rlock(ctx)()
+
+	events := make([]*burp.BeerEvent, len(r.events[id]))
+	for i, e := range r.events[id] {
+		events[i] = copyEvent(e)
+	}
+	return events, nil
+}
+
+func (r *Repo) SavePriceChange(ctx context.Context, c *burp.PriceChange) error {
+	defer r.lock(ctx)()
+
+	// changes are shifted and replaced in place
+	keep(r, r.prices, c.BeerID, copySlice[*burp.PriceChange])
+
+	stored := *c
+	changes := r.prices[c.BeerID]
+	i := sort.Search(len(changes), func(i int) bool { return !changes[i].From.Before(c.From) })
+	if i < len(changes) && changes[i].From.Equal(c.From) {
+		changes[i] = &stored
+		return nil
+	}
+
+	changes = append(changes, nil)
+	copy(changes[i+1:], changes[i:])
+	changes[i] = &stored
+	r.prices[c.BeerID] = changes
+	return nil
+}
+
+func (r *Repo) SelectPriceChanges(ctx context.Context, id burp.ID) ([]*burp.PriceChange, error) {
+	defer r.rlock(ctx)()
+
+	changes := make([]*burp.PriceChange, len(r.prices[id]))
+	for i, c := range r.prices[id] {
+		change := *c
+		changes[i] = &change
+	}
+	return changes, nil
+}
+
+func (r *Repo) SelectPriceAt(ctx context.Context, id burp.ID, at time.Time) (*burp.PriceChange, error) {
+	defer r.rlock(ctx)()
+
+	changes := r.prices[id]
+	i := sort.Search(len(changes), func(i int) bool { return changes[i].From.After(at) })
+	if i == 0 {
+		return nil, repo.Errorf("price of beer %q not found at %s: %w", id, at, repo.ErrNotFound)
+	}
+
+	change := *changes[i-1]
+	return &change, nil
+}
+
+// SaveBrewery inserts or updates brewery as SaveBeer does a beer.
+func (r *Repo) SaveBrewery(ctx context.Context, brewery *burp.Brewery) error {
+	defer r.lock(ctx)()
+
+	stored, ok := r.breweries[brewery.ID]
+	if ok && stored.Version != brewery.Version || !ok && brewery.Version != 0 {
+		return repo.Errorf("unable to save brewery %q at version %d: %w", brewery.ID, brewery.Version, burp.ErrVersionConflict)
+	}
+
+	if ok {
+		brewery.CreatedAt = stored.CreatedAt
+	}
+
+	keep(r, r.breweries, brewery.ID, same[*burp.Brewery])
+	brewery.Version++
+	c := *brewery
+	r.breweries[brewery.ID] = &c
+	return nil
+}
+
+func (r *Repo) SelectBrewery(ctx context.Context, id burp.ID) (*burp.Brewery, error) {
+	defer r.rlock(ctx)()
+
+	brewery, ok := r.breweries[id]
+	if !ok {
+		return nil, repo.Errorf("brewery with id %q not found: %w", id, repo.ErrNotFound)
+	}
+
+	c := *brewery
+	return &c, nil
+}
+
+func (r *Repo) ListBreweries(ctx context.Context, q burp.BreweryQuery) ([]*burp.Brewery, error) {
+	defer r.rlock(ctx)()
+
+	var breweries []*burp.Brewery
+	for _, brewery := range r.breweries {
+		if strings.Contains(strings.ToLower(brewery.Name), strings.ToLower(q.Name)) && q.Follows(brewery) {
+			c := *brewery
+			breweries = append(breweries, &c)
+		}
+	}
+
+	sort.Slice(breweries, func(i, j int) bool { return q.Less(breweries[i], breweries[j]) })
+
+	if len(breweries) > q.Limit {
+		breweries = breweries[:q.Limit]
+	}
+	return breweries, nil
+}
+
+func (r *Repo) RemoveBrewery(ctx context.Context, id burp.ID) error {
+	defer r.lock(ctx)()
+
+	if _, ok := r.breweries[id]; !ok {
+		return repo.Errorf("brewery with id %q not found: %w", id, repo.ErrNotFound)
+	}
+
+	keep(r, r.breweries, id, same[*burp.Brewery])
+	delete(r.breweries, id)
+	return nil
+}
+
+// MoveStock moves stock under the repo lock, so that concurrent
+// movements apply one after the other.
+func (r *Repo) MoveStock(ctx context.Context, m *burp.StockMovement) (*burp.Stock, error) {
+	defer r.lock(ctx)()
+
+	keep(r, r.stock, m.BeerID, copyStocks)
+	stock := r.stockAt(m.BeerID, m.Location)
+	if err := stock.Apply(m); err != nil {
+		return nil, repo.Errorf("unable to move stock of beer %q at %q by %d: %w", m.BeerID, m.Location, m.Delta, err)
+	}
+
+	r.stock[m.BeerID][m.Location] = stock
+	c := *stock
+	return &c, nil
+}
+
+func (r *Repo) SetStockThreshold(ctx context.Context, t *burp.StockThreshold) (*burp.Stock, error) {
+	defer r.lock(ctx)()
+
+	keep(r, r.stock, t.BeerID, copyStocks)
+	stock := r.stockAt(t.BeerID, t.Location)
+	stock.Threshold = t.Threshold
+	stock.UpdatedAt = t.Time
+
+	r.stock[t.BeerID][t.Location] = stock
+	c := *stock
+	return &c, nil
+}
+
+// stockAt returns a copy of the stock of beer id at location, empty
+// if none is stored.
+func (r *Repo) stockAt(id burp.ID, location string) *burp.Stock {
+	if r.stock[id] == nil {
+		r.stock[id] = make(map[string]*burp.Stock)
+	}
+
+	if stock, ok := r.stock[id][location]; ok {
+		c := *stock
+		return &c
+	}
+	return &burp.Stock{BeerID: id, Location: location}
+}
+
+func (r *Repo) ListStock(ctx context.Context, q burp.StockQuery) ([]*burp.Stock, error) {
+	defer r.rlock(ctx)()
+
+	stock := []*burp.Stock{}
+	for _, id := range sortedIDs(r.stock) {
+		if q.BeerID.UUID != uuid.Nil && id != q.BeerID {
+			continue
+		}
+
+		for _, location := range sortedLocations(r.stock[id]) {
+			s := r.stock[id][location]
+			if q.Location != "" && location != q.Location || q.Low && !s.Low() {
+				continue
+			}
+
+			c := *s
+			stock = append(stock, &c)
+		}
+	}
+	return stock, nil
+}
+
+func copyStocks(m map[string]*burp.Stock) map[string]*burp.Stock {
+	c := make(map[string]*burp.Stock, len(m))
+	for location, stock := range m {
+		c[location] = stock
+	}
+	return c
+}
+
+func sortedLocations(m map[string]*burp.Stock) []string {
+	locations := make([]string, 0, len(m))
+	for location := range m {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+	return locations
+}
+
+// SaveOrder inserts order, or updates its status as SaveBeer does a
+// beer. Stored lines and customer are kept on update.
+func (r *Repo) SaveOrder(ctx context.Context, order *burp.Order) error {
+	defer r.lock(ctx)()
+
+	stored, ok := r.orders[order.ID]
+	if ok && stored.Version != order.Version || !ok && order.Version != 0 {
+		return repo.Errorf("unable to save order %q at version %d: %w", order.ID, order.Version, burp.ErrVersionConflict)
+	}
+
+	keep(r, r.orders, order.ID, copyOrder)
+
+	if ok {
+		stored.Status = order.Status
+		stored.UpdatedAt = order.UpdatedAt
+		stored.Version++
+		order.CreatedAt = stored.CreatedAt
+		order.Version = stored.Version
+		return nil
+	}
+
+	order.Version++
+	r.orders[order.ID] = copyOrder(order)
+	return nil
+}
+
+func (r *Repo) SelectOrder(ctx context.Context, id burp.ID) (*burp.Order, error) {
+	defer r.rlock(ctx)()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, repo.Errorf("order with id %q not found: %w", id, repo.ErrNotFound)
+	}
+	return copyOrder(order), nil
+}
+
+func (r *Repo) ListOrders(ctx context.Context, q burp.OrderQuery) ([]*burp.Order, error) {
+	defer r.rlock(ctx)()
+
+	var orders []*burp.Order
+	for _, order := range r.orders {
+		if q.Status == "" || order.Status == q.Status {
+			orders = append(orders, order)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		a, b := orders[i], orders[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
+	if len(orders) > q.Limit {
+		orders = orders[:q.Limit]
+	}
+
+	for i, order := range orders {
+		orders[i] = copyOrder(order)
+	}
+	return orders, nil
+}
+
+func copyOrder(order *burp.Order) *burp.Order {
+	c := *order
+	c.Lines = append([]burp.OrderLine(nil), order.Lines...)
+	return &c
+}
+
+func copyBeer(beer *burp.Beer) *burp.Beer {
+	if beer == nil {
+		return nil
+	}
+
+	c := *beer
+	if beer.DeletedAt != nil {
+		deletedAt := *beer.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	if beer.BreweryID != nil {
+		breweryID := *beer.BreweryID
+		c.BreweryID = &breweryID
+	}
+	if beer.Tags != nil {
+		c.Tags = append([]string(nil), beer.Tags...)
+	}
+	return &c
+}
+
+func copyEvent(e *burp.BeerEvent) *burp.BeerEvent {
+	c := *e
+	c.Before = copyBeer(e.Before)
+	c.After = copyBeer(e.After)
+	return &c
+}
+
+// State is the content of a Repo, e.g. to persist it.
+type State struct {
+	Beers  []*burp.Beer        `json:"beers"`
+	Events []*burp.BeerEvent   `json:"events"`
+	Prices []*burp.PriceChange `json:"prices"`
+
+	Breweries []*burp.Brewery `json:"breweries"`
+	Stock     []*burp.Stock   `json:"stock"`
+	Orders    []*burp.Order   `json:"orders"`
+}
+
+// State returns a copy of repo content, beers and breweries ordered
+// by id, events and price changes by beer id then as recorded, stock
+// by beer id then location, orders by id.
+func (r *Repo) State() State {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.state()
+}
+
+func (r *Repo) state() State {
+	s := State{
+		Beers:  make([]*burp.Beer, 0, len(r.beers)),
+		Events: []*burp.BeerEvent{},
+		Prices: []*burp.PriceChange{},
+
+		Breweries: make([]*burp.Brewery, 0, len(r.breweries)),
+		Stock:     []*burp.Stock{},
+		Orders:    make([]*burp.Order, 0, len(r.orders)),
+	}
+
+	for _, beer := range r.beers {
+		s.Beers = append(s.Beers, copyBeer(beer))
+	}
+	sort.Slice(s.Beers, func(i, j int) bool { return s.Beers[i].ID.String() < s.Beers[j].ID.String() })
+
+	for _, id := range sortedIDs(r.events) {
+		for _, e := range r.events[id] {
+			s.Events = append(s.Events, copyEvent(e))
+		}
+	}
+
+	for _, id := range sortedIDs(r.prices) {
+		for _, c := range r.prices[id] {
+			change := *c
+			s.Prices = append(s.Prices, &change)
+		}
+	}
+
+	for _, id := range sortedIDs(r.breweries) {
+		brewery := *r.breweries[id]
+		s.Breweries = append(s.Breweries, &brewery)
+	}
+
+	for _, id := range sortedIDs(r.stock) {
+		for _, location := range sortedLocations(r.stock[id]) {
+			stock := *r.stock[id][location]
+			s.Stock = append(s.Stock, &stock)
+		}
+	}
+
+	for _, id := range sortedIDs(r.orders) {
+		s.Orders = append(s.Orders, copyOrder(r.orders[id]))
+	}
+
+	return s
+}
+
+// SetState replaces repo content with a copy of s.
+func (r *Repo) SetState(s State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setState(s)
+}
+
+func (r *Repo) setState(s State) {
+	beers := make(map[burp.ID]*burp.Beer, len(s.Beers))
+	for _, beer := range s.Beers {
+		beers[beer.ID] = copyBeer(beer)
+	}
+
+	events := make(map[burp.ID][]*burp.BeerEvent)
+	for _, e := range s.Events {
+		events[e.BeerID] = append(events[e.BeerID], copyEvent(e))
+	}
+
+	prices := make(map[burp.ID][]*burp.PriceChange)
+	for _, c := range s.Prices {
+		change := *c
+		prices[c.BeerID] = append(prices[c.BeerID], &change)
+	}
+	for _, changes := range prices {
+		sort.SliceStable(changes, func(i, j int) bool { return changes[i].From.Before(changes[j].From) })
+	}
+
+	breweries := make(map[burp.ID]*burp.Brewery, len(s.Breweries))
+	for _, b := range s.Breweries {
+		brewery := *b
+		breweries[b.ID] = &brewery
+	}
+
+	stock := make(map[burp.ID]map[string]*burp.Stock)
+	for _, st := range s.Stock {
+		if stock[st.BeerID] == nil {
+			stock[st.BeerID] = make(map[string]*burp.Stock)
+		}
+		c := *st
+		stock[st.BeerID][st.Location] = &c
+	}
+
+	orders := make(map[burp.ID]*burp.Order, len(s.Orders))
+	for _, order := range s.Orders {
+		orders[order.ID] = copyOrder(order)
+	}
+
+	r.beers, r.events, r.prices, r.breweries, r.stock, r.orders = beers, events, prices, breweries, stock, orders
+}
+
+func sortedIDs[T any](m map[burp.ID]T) []burp.ID {
+	ids := make([]burp.ID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
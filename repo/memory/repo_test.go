@@ -0,0 +1,258 @@
+package memory_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo"
+	"burp/repo/memory"
+	"burp/repo/repotest"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConformance(t *testing.T) {
+	r := memory.New()
+	repotest.TestBeerRepo(t, r)
+	repotest.TestTxRunner(t, r)
+	repotest.TestBreweryRepo(t, r)
+	repotest.TestStockRepo(t, r)
+	repotest.TestOrderRepo(t, r)
+}
+
+func TestSaveBeerStoresCopy(t *testing.T) {
+	ctx := context.Background()
+	r := memory.New()
+	beer := burptest.RandBeer()
+
+	if err := r.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	want := *beer
+	beer.Name = "modified after save"
+
+	got, err := r.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) returned beer modified by caller of SaveBeer, (-want/+got):\n%s", beer.ID, diff)
+	}
+
+	got.Name = "modified after select"
+
+	again, err := r.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if diff := cmp.Diff(&want, again); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) returned beer modified by caller of SelectBeer, (-want/+got):\n%s", beer.ID, diff)
+	}
+}
+
+func TestSaveBeerWithStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	r := memory.New()
+	beer := burptest.RandBeer()
+
+	if err := r.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	stale := *beer
+	stale.Version--
+
+	err := r.SaveBeer(ctx, &stale)
+	if !errors.Is(err, burp.ErrVersionConflict) {
+		t.Errorf("SaveBeer(ctx, %+v) returned error %v, want %v", stale, err, burp.ErrVersionConflict)
+	}
+
+	if !errors.As(err, &repo.Err{}) {
+		t.Errorf("SaveBeer(ctx, %+v) returned error %v, want a repo.Err", stale, err)
+	}
+}
+
+func TestRemoveBeer(t *testing.T) {
+	ctx := context.Background()
+	r := memory.New()
+	beer := burptest.RandBeer()
+
+	if err := r.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	if err := r.RemoveBeer(ctx, beer.ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if _, err := r.SelectBeer(ctx, beer.ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("SelectBeer(ctx, %s) of removed beer returned error %v, want %v", beer.ID, err, repo.ErrNotFound)
+	}
+
+	trash, err := r.ListBeers(ctx, burp.BeerQuery{Filter: burp.BeerFilter{Deleted: true}, Sort: burp.SortByName, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, trash) returned unexpected error %s", err)
+	}
+
+	if len(trash) != 1 || trash[0].ID != beer.ID || trash[0].DeletedAt == nil {
+		t.Errorf("ListBeers(ctx, trash) returned %+v, want removed beer", trash)
+	}
+
+	if err := r.RemoveBeer(ctx, beer.ID, burptest.RandTime()); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("RemoveBeer(ctx, %s) twice returned error %v, want %v", beer.ID, err, repo.ErrNotFound)
+	}
+}
+
+func TestRunInTxUndoesEveryChange(t *testing.T) {
+	ctx := context.Background()
+	r := memory.New()
+	at := burptest.RandTime()
+
+	beer, purged, brewery := burptest.RandBeer(), burptest.RandBeer(), burptest.RandBrewery()
+	order := &burp.Order{ID: burp.ID{UUID: uuid.New()}, Customer: "seed", Status: burp.OrderPending, Lines: []burp.OrderLine{{BeerID: beer.ID, Quantity: 1, Price: beer.Price}}}
+	seed := func(ctx context.Context) error {
+		for _, b := range []*burp.Beer{beer, purged} {
+			if err := r.SaveBeer(ctx, b); err != nil {
+				return err
+			}
+			if err := r.SavePriceChange(ctx, &burp.PriceChange{BeerID: b.ID, Price: b.Price, From: at}); err != nil {
+				return err
+			}
+			if _, err := r.MoveStock(ctx, &burp.StockMovement{BeerID: b.ID, Location: "cellar", Delta: 10, Time: at}); err != nil {
+				return err
+			}
+		}
+		if err := r.SaveBeerEvent(ctx, &burp.BeerEvent{ID: burp.ID{UUID: uuid.New()}, BeerID: beer.ID, Kind: burp.BeerCreated, Time: at, After: beer}); err != nil {
+			return err
+		}
+		if err := r.SaveBrewery(ctx, brewery); err != nil {
+			return err
+		}
+		return r.SaveOrder(ctx, order)
+	}
+	if err := r.RunInTx(ctx, seed); err != nil {
+		t.Fatalf("RunInTx(ctx, seed) returned unexpected error %s", err)
+	}
+
+	want := r.State()
+	errFail := errors.New("failed")
+	changeAll := func(ctx context.Context) error {
+		update := *beer
+		update.Name = "updated"
+		if err := r.SaveBeer(ctx, &update); err != nil {
+			return err
+		}
+		if err := r.RemoveBeer(ctx, beer.ID, at); err != nil {
+			return err
+		}
+		if err := r.RestoreBeer(ctx, beer.ID); err != nil {
+			return err
+		}
+		if err := r.SaveBeer(ctx, burptest.RandBeer()); err != nil {
+			return err
+		}
+		if err := r.RemoveBeer(ctx, purged.ID, at); err != nil {
+			return err
+		}
+		if err := r.PurgeBeer(ctx, purged.ID); err != nil {
+			return err
+		}
+		if err := r.SaveBeerEvent(ctx, &burp.BeerEvent{ID: burp.ID{UUID: uuid.New()}, BeerID: beer.ID, Kind: burp.BeerUpdated, Time: at, Before: beer, After: &update}); err != nil {
+			return err
+		}
+		for _, from := range []time.Time{at.Add(-time.Hour), at, at.Add(time.Hour)} {
+			if err := r.SavePriceChange(ctx, &burp.PriceChange{BeerID: beer.ID, Price: burp.Price{Currency: burp.EUR, Amount: 1}, From: from}); err != nil {
+				return err
+			}
+		}
+		if _, err := r.MoveStock(ctx, &burp.StockMovement{BeerID: beer.ID, Location: "cellar", Delta: -5, Time: at}); err != nil {
+			return err
+		}
+		if _, err := r.MoveStock(ctx, &burp.StockMovement{BeerID: beer.ID, Location: "shop", Delta: 5, Time: at}); err != nil {
+			return err
+		}
+		if _, err := r.SetStockThreshold(ctx, &burp.StockThreshold{BeerID: beer.ID, Location: "cellar", Threshold: 3, Time: at}); err != nil {
+			return err
+		}
+		if err := r.SaveBrewery(ctx, burptest.RandBrewery()); err != nil {
+			return err
+		}
+		if err := r.RemoveBrewery(ctx, brewery.ID); err != nil {
+			return err
+		}
+		paid := *order
+		paid.Status = burp.OrderPaid
+		if err := r.SaveOrder(ctx, &paid); err != nil {
+			return err
+		}
+		if err := r.SaveOrder(ctx, &burp.Order{ID: burp.ID{UUID: uuid.New()}, Customer: "new", Status: burp.OrderPending}); err != nil {
+			return err
+		}
+		return errFail
+	}
+
+	if err := r.RunInTx(ctx, changeAll); !errors.Is(err, errFail) {
+		t.Fatalf("RunInTx(ctx, changeAll) returned error %v, want %v", err, errFail)
+	}
+
+	if diff := cmp.Diff(want, r.State()); diff != "" {
+		t.Errorf("State() after failed RunInTx(ctx, changeAll) differs, (-want/+got):\n%s", diff)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	r := memory.New()
+	beer := burptest.RandBeer()
+
+	if err := r.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	const writers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var saved int
+	for i := 0; i < writers; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			update := *beer
+			update.Name = fmt.Sprintf("concurrent %d", i)
+			if err := r.SaveBeer(ctx, &update); err == nil {
+				mu.Lock()
+				saved++
+				mu.Unlock()
+			}
+			_ = r.SaveBeer(ctx, burptest.RandBeer())
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, _ = r.SelectBeer(ctx, beer.ID)
+			_, _ = r.ListBeers(ctx, burp.BeerQuery{Sort: burp.SortByName, Limit: writers})
+			_, _ = r.SearchBeers(ctx, burp.BeerSearch{Text: "concurrent", Limit: writers})
+		}()
+	}
+	wg.Wait()
+
+	if saved != 1 {
+		t.Errorf("SaveBeer(ctx, beer) concurrently at the same version saved %d times, want 1", saved)
+	}
+
+	beers, err := r.ListBeers(ctx, burp.BeerQuery{Sort: burp.SortByName, Limit: 2 * writers})
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, q) returned unexpected error %s", err)
+	}
+
+	if len(beers) != writers+1 {
+		t.Errorf("ListBeers(ctx, q) returned %d beers, want %d", len(beers), writers+1)
+	}
+}
@@ -0,0 +1,93 @@
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// Migration evolves the database schema from Version-1 to Version
+// when applying Up, and back when applying Down.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load returns migrations of fsys root directory, oldest first. They
+// are named <version>_<name>.up.sql and <version>_<name>.down.sql,
+// versions starting from 1 without gap.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		migration, direction, err := parseName(e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[migration.Version]
+		if !ok {
+			m = &migration
+			byVersion[m.Version] = m
+		}
+
+		if m.Name != migration.Name {
+			return nil, fmt.Errorf("migration %d is named both %q and %q", m.Version, m.Name, migration.Name)
+		}
+
+		content, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version := 1; version <= len(byVersion); version++ {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %d is missing", version)
+		}
+
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d %q must have both up and down files", m.Version, m.Name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	return migrations, nil
+}
+
+// parseName parses a migration file name
+// such as 0001_create_beer.up.sql.
+func parseName(name string) (Migration, string, error) {
+	if !strings.HasSuffix(name, ".sql") {
+		return Migration{}, "", fmt.Errorf("migration file %q is not a sql file", name)
+	}
+
+	base, direction, _ := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+	if direction != "up" && direction != "down" {
+		return Migration{}, "", fmt.Errorf("migration file %q is neither up nor down", name)
+	}
+
+	v, n, _ := strings.Cut(base, "_")
+	version, err := strconv.Atoi(v)
+	if err != nil || version < 1 || n == "" {
+		return Migration{}, "", fmt.Errorf("migration file %q is not named <version>_<name>", name)
+	}
+
+	return Migration{Version: version, Name: n}, direction, nil
+}
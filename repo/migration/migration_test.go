@@ -0,0 +1,75 @@
+package migration_test
+
+import (
+	"burp/repo/migration"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_column.up.sql":     {Data: []byte("ALTER TABLE t ADD c INT;")},
+		"0002_add_column.down.sql":   {Data: []byte("ALTER TABLE t DROP c;")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t();")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+
+	got, err := migration.Load(fsys)
+	if err != nil {
+		t.Fatalf("Load(fsys) returned unexpected error %s", err)
+	}
+
+	want := []migration.Migration{
+		{Version: 1, Name: "create_table", Up: "CREATE TABLE t();", Down: "DROP TABLE t;"},
+		{Version: 2, Name: "add_column", Up: "ALTER TABLE t ADD c INT;", Down: "ALTER TABLE t DROP c;"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Load(fsys) returned unexpected migrations, (-want/+got):\n%s", diff)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	file := &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+	tests := []struct {
+		description string
+		fsys        fstest.MapFS
+	}{
+		{
+			description: "gap between versions",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql": file, "0001_a.down.sql": file,
+				"0003_c.up.sql": file, "0003_c.down.sql": file,
+			},
+		},
+		{
+			description: "missing down",
+			fsys:        fstest.MapFS{"0001_a.up.sql": file},
+		},
+		{
+			description: "names differing between up and down",
+			fsys:        fstest.MapFS{"0001_a.up.sql": file, "0001_b.down.sql": file},
+		},
+		{
+			description: "neither up nor down",
+			fsys:        fstest.MapFS{"0001_a.sql": file},
+		},
+		{
+			description: "missing version",
+			fsys:        fstest.MapFS{"a.up.sql": file, "a.down.sql": file},
+		},
+		{
+			description: "not sql",
+			fsys:        fstest.MapFS{"README.md": file},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if _, err := migration.Load(test.fsys); err == nil {
+				t.Errorf("Load(fsys) returned no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package psql
+
+import (
+	"burp/repo/migration"
+	"context"
+	"embed"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey identifies the advisory lock held while migrating,
+// so that concurrent deployments do not migrate at the same time.
+const migrationLockKey = 0x62757270 // "burp"
+
+// Migrations returns embedded migrations, oldest first.
+func Migrations() ([]migration.Migration, error) {
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migration.Load(fsys)
+}
+
+// LatestVersion returns the version of the last embedded migration.
+func LatestVersion() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return len(migrations), nil
+}
+
+// SchemaVersion returns the version of the last migration applied
+// to the database, zero when none is. It leaves the database as is,
+// even when table schema_migrations does not exist yet.
+func SchemaVersion(ctx context.Context, conn *pgx.Conn) (int, error) {
+	var exists bool
+	err := conn.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("unable to look up schema_migrations table: %w", err)
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	return schemaVersion(ctx, conn)
+}
+
+// Migrate applies migrations up or down until the database schema
+// is at given version. Each migration runs in its own transaction,
+// recorded in table schema_migrations. An advisory lock is held on
+// conn meanwhile, so it must not be shared by a pool.
+func Migrate(ctx context.Context, conn *pgx.Conn, to int) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	if to < 0 || to > len(migrations) {
+		return fmt.Errorf("unknown schema version %d, latest is %d", to, len(migrations))
+	}
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("unable to lock migrations: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	if err := createMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if current > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than latest known %d", current, len(migrations))
+	}
+
+	for ; current < to; current++ {
+		m := migrations[current]
+		if err := applyMigration(ctx, conn, m.Up, "INSERT INTO schema_migrations(version, name) VALUES($1, $2)", m); err != nil {
+			return err
+		}
+	}
+
+	for ; current > to; current-- {
+		m := migrations[current-1]
+		if err := applyMigration(ctx, conn, m.Down, "DELETE FROM schema_migrations WHERE version = $1 AND name = $2", m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MigrateUp applies all pending migrations.
+func MigrateUp(ctx context.Context, conn *pgx.Conn) error {
+	latest, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+	return Migrate(ctx, conn, latest)
+}
+
+func createMigrationsTable(ctx context.Context, conn *pgx.Conn) error {
+	q := `CREATE TABLE IF NOT EXISTS schema_migrations(
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at timestamp NOT NULL DEFAULT timezone('utc', now())
+	)`
+
+	if _, err := conn.Exec(ctx, q); err != nil {
+		return fmt.Errorf("unable to create schema_migrations table: %w", err)
+	}
+
+	return nil
+}
+
+func schemaVersion(ctx context.Context, conn *pgx.Conn) (int, error) {
+	var version int
+	err := conn.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("unable to select schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// applyMigration runs sql then records it with record query,
+// both in a transaction.
+func applyMigration(ctx context.Context, conn *pgx.Conn, sql, record string, m migration.Migration) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin migration %d %q: %w", m.Version, m.Name, err)
+	}
+	// no-op once committed
+	defer tx.Rollback(context.Background())
+
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("unable to apply migration %d %q: %w", m.Version, m.Name, err)
+	}
+
+	if _, err := tx.Exec(ctx, record, m.Version, m.Name); err != nil {
+		return fmt.Errorf("unable to record migration %d %q: %w", m.Version, m.Name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit migration %d %q: %w", m.Version, m.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package psql_test
+
+import (
+	"burp/repo/psql"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := psql.Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() returned error %s, want none", err)
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("Migrations() returned migration %q at position %d with version %d", m.Name, i, m.Version)
+		}
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire(ctx) returned error %s, want none", err)
+	}
+	defer conn.Release()
+
+	latest, err := psql.LatestVersion()
+	if err != nil {
+		t.Fatalf("LatestVersion() returned error %s, want none", err)
+	}
+
+	// each migration must revert cleanly and be applicable again
+	for _, to := range []int{0, latest} {
+		if err := psql.Migrate(ctx, conn.Conn(), to); err != nil {
+			t.Fatalf("Migrate(ctx, conn, %d) returned error %s, want none", to, err)
+		}
+
+		version, err := psql.SchemaVersion(ctx, conn.Conn())
+		if err != nil {
+			t.Fatalf("SchemaVersion(ctx, conn) returned error %s, want none", err)
+		}
+
+		if version != to {
+			t.Errorf("SchemaVersion(ctx, conn) after Migrate(ctx, conn, %d) returned %d", to, version)
+		}
+	}
+
+	if err := psql.Migrate(ctx, conn.Conn(), latest+1); err == nil {
+		t.Errorf("Migrate(ctx, conn, %d) past latest version returned no error", latest+1)
+	}
+}
+
+func TestSchemaVersionWithoutMigrationsTable(t *testing.T) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire(ctx) returned error %s, want none", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin(ctx) returned error %s, want none", err)
+	}
+	// leaves table schema_migrations to other tests
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DROP TABLE schema_migrations"); err != nil {
+		t.Fatalf("Dropping schema_migrations returned error %s, want none", err)
+	}
+
+	version, err := psql.SchemaVersion(ctx, tx.Conn())
+	if err != nil {
+		t.Fatalf("SchemaVersion(ctx, conn) returned error %s, want none", err)
+	}
+
+	if version != 0 {
+		t.Errorf("SchemaVersion(ctx, conn) without schema_migrations returned %d, want 0", version)
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		t.Fatalf("Looking up schema_migrations returned error %s, want none", err)
+	}
+
+	if exists {
+		t.Errorf("SchemaVersion(ctx, conn) created table schema_migrations")
+	}
+}
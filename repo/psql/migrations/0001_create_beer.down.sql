@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS beer;
+
+DROP TYPE IF EXISTS currency;
@@ -1,4 +1,11 @@
-CREATE TYPE currency AS ENUM ('Euro', 'Dollar');
+-- tolerates databases created from former schema.sql
+DO $$
+BEGIN
+    CREATE TYPE currency AS ENUM ('Euro', 'Dollar');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END
+$$;
 
 CREATE TABLE IF NOT EXISTS beer(
     id VARCHAR(255) UNIQUE NOT NULL,
@@ -7,4 +14,4 @@ CREATE TABLE IF NOT EXISTS beer(
     name VARCHAR(255) NOT NULL,
     price_currency currency NOT NULL,
     price_amount INT NOT NULL CONSTRAINT positive_price CHECK (price_amount > 0)
-);
\ No newline at end of file
+);
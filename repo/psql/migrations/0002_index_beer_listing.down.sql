@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS beer_price_idx;
+
+DROP INDEX IF EXISTS beer_price_amount_idx;
+
+DROP INDEX IF EXISTS beer_created_at_idx;
+
+DROP INDEX IF EXISTS beer_name_idx;
@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS beer_name_idx ON beer (name COLLATE "C", id COLLATE "C");
+
+CREATE INDEX IF NOT EXISTS beer_created_at_idx ON beer (created_at, id COLLATE "C");
+
+CREATE INDEX IF NOT EXISTS beer_price_amount_idx ON beer (price_amount, id COLLATE "C");
+
+CREATE INDEX IF NOT EXISTS beer_price_idx ON beer (price_currency, price_amount, id COLLATE "C");
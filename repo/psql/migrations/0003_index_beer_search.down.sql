@@ -0,0 +1 @@
+DROP INDEX IF EXISTS beer_name_trgm_idx;
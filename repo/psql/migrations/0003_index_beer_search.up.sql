@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS beer_name_trgm_idx ON beer USING GIN (name gin_trgm_ops);
@@ -0,0 +1 @@
+ALTER TABLE beer DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS beer_deleted_at_idx;
+
+ALTER TABLE beer DROP COLUMN IF EXISTS deleted_at;
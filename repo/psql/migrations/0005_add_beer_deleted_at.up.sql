@@ -0,0 +1,3 @@
+ALTER TABLE beer ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+
+CREATE INDEX IF NOT EXISTS beer_deleted_at_idx ON beer (deleted_at) WHERE deleted_at IS NOT NULL;
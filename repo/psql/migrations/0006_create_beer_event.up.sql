@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS beer_event(
+    seq BIGSERIAL PRIMARY KEY,
+    id VARCHAR(255) UNIQUE NOT NULL,
+    beer_id VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    time timestamp NOT NULL,
+    before jsonb,
+    after jsonb
+);
+
+CREATE INDEX IF NOT EXISTS beer_event_beer_id_idx ON beer_event (beer_id, time, seq);
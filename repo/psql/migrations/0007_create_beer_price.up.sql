@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS beer_price(
+    beer_id VARCHAR(255) NOT NULL REFERENCES beer(id) ON DELETE CASCADE,
+    valid_from timestamp NOT NULL,
+    price_currency currency NOT NULL,
+    price_amount INT NOT NULL CONSTRAINT positive_price CHECK (price_amount > 0),
+    PRIMARY KEY (beer_id, valid_from)
+);
@@ -0,0 +1,8 @@
+-- fails while prices are in other currencies than EUR and USD
+CREATE TYPE currency AS ENUM ('Euro', 'Dollar');
+
+ALTER TABLE beer_price ALTER COLUMN price_currency TYPE currency
+    USING (CASE price_currency WHEN 'EUR' THEN 'Euro' WHEN 'USD' THEN 'Dollar' END)::currency;
+
+ALTER TABLE beer ALTER COLUMN price_currency TYPE currency
+    USING (CASE price_currency WHEN 'EUR' THEN 'Euro' WHEN 'USD' THEN 'Dollar' END)::currency;
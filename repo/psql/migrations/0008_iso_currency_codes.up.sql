@@ -0,0 +1,8 @@
+-- currencies are ISO 4217 codes, registered by the application
+ALTER TABLE beer ALTER COLUMN price_currency TYPE VARCHAR(3)
+    USING CASE price_currency WHEN 'Euro' THEN 'EUR' WHEN 'Dollar' THEN 'USD' END;
+
+ALTER TABLE beer_price ALTER COLUMN price_currency TYPE VARCHAR(3)
+    USING CASE price_currency WHEN 'Euro' THEN 'EUR' WHEN 'Dollar' THEN 'USD' END;
+
+DROP TYPE IF EXISTS currency;
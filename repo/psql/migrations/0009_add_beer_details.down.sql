@@ -0,0 +1,7 @@
+ALTER TABLE beer
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS volume,
+    DROP COLUMN IF EXISTS ibu,
+    DROP COLUMN IF EXISTS abv,
+    DROP COLUMN IF EXISTS style,
+    DROP COLUMN IF EXISTS brewery;
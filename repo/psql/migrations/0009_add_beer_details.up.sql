@@ -0,0 +1,8 @@
+-- catalogue details are optional, empty or zero when unknown
+ALTER TABLE beer
+    ADD COLUMN IF NOT EXISTS brewery VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS style VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS abv DOUBLE PRECISION NOT NULL DEFAULT 0 CONSTRAINT abv_range CHECK (abv >= 0 AND abv <= 100),
+    ADD COLUMN IF NOT EXISTS ibu INT NOT NULL DEFAULT 0 CONSTRAINT positive_ibu CHECK (ibu >= 0),
+    ADD COLUMN IF NOT EXISTS volume INT NOT NULL DEFAULT 0 CONSTRAINT positive_volume CHECK (volume >= 0),
+    ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
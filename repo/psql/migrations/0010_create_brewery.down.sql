@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS beer_brewery_id_idx;
+
+ALTER TABLE beer
+    DROP COLUMN IF EXISTS brewery_id;
+
+DROP TABLE IF EXISTS brewery;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS brewery(
+    id VARCHAR(255) PRIMARY KEY NOT NULL,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    version INT NOT NULL DEFAULT 1,
+    name VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS brewery_name_idx ON brewery (name COLLATE "C", id COLLATE "C");
+
+-- breweries with beers, in trash included, cannot be deleted
+ALTER TABLE beer
+    ADD COLUMN IF NOT EXISTS brewery_id VARCHAR(255) REFERENCES brewery(id);
+
+CREATE INDEX IF NOT EXISTS beer_brewery_id_idx ON beer (brewery_id) WHERE brewery_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- tags are normalized by the application, sorted bytewise when read
+CREATE TABLE IF NOT EXISTS beer_tag(
+    beer_id VARCHAR(255) NOT NULL REFERENCES beer(id) ON DELETE CASCADE,
+    tag VARCHAR(30) NOT NULL CONSTRAINT tag_not_empty CHECK (tag <> ''),
+    PRIMARY KEY (beer_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS beer_tag_tag_idx ON beer_tag (tag, beer_id);
@@ -0,0 +1,11 @@
+-- rows are locked while moving stock, so that it never goes negative
+CREATE TABLE IF NOT EXISTS stock(
+    beer_id VARCHAR(255) NOT NULL REFERENCES beer(id) ON DELETE CASCADE,
+    location VARCHAR(50) NOT NULL CONSTRAINT location_not_empty CHECK (location <> ''),
+    quantity INT NOT NULL DEFAULT 0 CONSTRAINT non_negative_quantity CHECK (quantity >= 0),
+    threshold INT NOT NULL DEFAULT 0 CONSTRAINT non_negative_threshold CHECK (threshold >= 0),
+    updated_at timestamp NOT NULL,
+    PRIMARY KEY (beer_id, location)
+);
+
+CREATE INDEX IF NOT EXISTS stock_low_idx ON stock (location) WHERE quantity < threshold;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS customer_order(
+    id VARCHAR(255) PRIMARY KEY NOT NULL,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    version INT NOT NULL DEFAULT 1,
+    customer VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL CONSTRAINT order_status CHECK (status IN ('pending', 'paid', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS customer_order_listing_idx ON customer_order (created_at DESC, id COLLATE "C");
+
+CREATE INDEX IF NOT EXISTS customer_order_status_idx ON customer_order (status, created_at DESC, id COLLATE "C");
+
+-- lines snapshot ordered beers, which may be purged since
+CREATE TABLE IF NOT EXISTS order_line(
+    order_id VARCHAR(255) NOT NULL REFERENCES customer_order(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    beer_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CONSTRAINT positive_quantity CHECK (quantity > 0),
+    price_currency VARCHAR(3) NOT NULL,
+    price_amount INT NOT NULL CONSTRAINT positive_price CHECK (price_amount > 0),
+    PRIMARY KEY (order_id, position)
+);
@@ -0,0 +1 @@
+-- backfilled prices cannot be told from recorded ones, and are kept
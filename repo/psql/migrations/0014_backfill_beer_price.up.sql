@@ -0,0 +1,5 @@
+-- beers saved before prices were recorded cost their
+-- current price from their last update
+INSERT INTO beer_price(beer_id, valid_from, price_currency, price_amount)
+SELECT id, updated_at, price_currency, price_amount FROM beer
+WHERE NOT EXISTS (SELECT 1 FROM beer_price WHERE beer_price.beer_id = beer.id);
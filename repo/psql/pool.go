@@ -0,0 +1,73 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+// DB queries the database. It is satisfied by *pgxpool.Pool,
+// safe for concurrent use, as well as *pgx.Conn and pgx.Tx.
+// Begin starts a transaction, or a savepoint within one.
+type DB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// PoolConfig sizes a connection pool. Zero values
+// keep pgxpool defaults, or those set in URL.
+type PoolConfig struct {
+	URL string
+
+	MaxConns int32
+	MinConns int32
+
+	// MaxConnLifetime and MaxConnIdleTime bound how long
+	// a connection is kept, open or idle, before being closed.
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+
+	// HealthCheckPeriod is how often idle connections are checked.
+	HealthCheckPeriod time.Duration
+}
+
+// NewPool opens a connection pool and checks the database is reachable.
+func NewPool(ctx context.Context, c PoolConfig) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse database url: %w", err)
+	}
+
+	if c.MaxConns != 0 {
+		config.MaxConns = c.MaxConns
+	}
+	if c.MinConns != 0 {
+		config.MinConns = c.MinConns
+	}
+	if c.MaxConnLifetime != 0 {
+		config.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime != 0 {
+		config.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+	if c.HealthCheckPeriod != 0 {
+		config.HealthCheckPeriod = c.HealthCheckPeriod
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("unable to reach database: %w", err)
+	}
+
+	return pool, nil
+}
@@ -3,25 +3,20 @@ package psql_test
 import (
 	"burp/repo/psql"
 	"context"
-	_ "embed"
 	"fmt"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
 	"log"
 	"os"
-	"strings"
 	"testing"
 	"time"
 )
 
 var (
 	ctx     context.Context
-	conn    *pgx.Conn
+	db      *pgxpool.Pool
 	appRepo *psql.Repo
-
-	//go:embed schema.sql
-	schema string
 )
 
 func TestMain(m *testing.M) {
@@ -71,11 +66,8 @@ func TestMain(m *testing.M) {
 	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
 	pool.MaxWait = 120 * time.Second
 	if err = pool.Retry(func() error {
-		conn, err = pgx.Connect(context.Background(), databaseUrl)
-		if err != nil {
-			return err
-		}
-		return conn.Ping(context.Background())
+		db, err = psql.NewPool(context.Background(), psql.PoolConfig{URL: databaseUrl})
+		return err
 	}); err != nil {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
@@ -84,7 +76,7 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Could not initialize database: %s", err)
 	}
 
-	appRepo = &psql.Repo{Conn: conn}
+	appRepo = &psql.Repo{DB: db}
 
 	//Run tests
 	code := m.Run()
@@ -98,12 +90,11 @@ func TestMain(m *testing.M) {
 }
 
 func initDatabase() error {
-	statements := strings.Split(schema, ";")
-	for _, statement := range statements {
-		_, err := conn.Exec(ctx, statement)
-		if err != nil {
-			return err
-		}
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return err
 	}
-	return nil
+	defer conn.Release()
+
+	return psql.MigrateUp(ctx, conn.Conn())
 }
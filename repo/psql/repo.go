@@ -5,54 +5,876 @@ import (
 	"burp/repo"
 	"context"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"strings"
+	"time"
 )
 
+// Repo stores beers in Postgres. It is safe for
+// concurrent use when DB is, as a connection pool is.
 type Repo struct {
-	Conn *pgx.Conn
+	DB DB
 }
 
+// SaveBeer inserts beer when its version is zero, otherwise updates it
+// if its version still matches the stored one. Version is then incremented.
 func (r *Repo) SaveBeer(ctx context.Context, beer *burp.Beer) error {
-	q := `INSERT INTO beer(id, created_at, updated_at, name, price_currency, price_amount) 
-	VALUES($1, $2, $3, $4, $5, $6)
-	ON CONFLICT (id)
-	DO
-	UPDATE SET created_at = $2, updated_at = $3, name = $4, price_currency = $5, price_amount = $6`
+	// beer row and tags are saved at once
+	return r.SaveBeers(ctx, []*burp.Beer{beer})
+}
+
+// SaveBeers saves beers in order as SaveBeer does, in a single
+// transaction. When one cannot be saved, none is, and given
+// beers are left as they were.
+func (r *Repo) SaveBeers(ctx context.Context, beers []*burp.Beer) error {
+	given := make([]burp.Beer, len(beers))
+	for i, beer := range beers {
+		given[i] = *beer
+	}
+
+	err := pgx.BeginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		for _, beer := range beers {
+			if err := saveBeer(ctx, tx, beer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		for i, beer := range beers {
+			*beer = given[i]
+		}
+	}
+
+	if err != nil && !errors.As(err, &repo.Err{}) {
+		return repo.Error(err.Error())
+	}
+
+	return err
+}
+
+func saveBeer(ctx context.Context, db DB, beer *burp.Beer) error {
+	details := []any{beer.Brewery, beer.Style, beer.ABV, beer.IBU, beer.Volume, beer.Description, beer.BreweryID}
+
+	q := `INSERT INTO beer(id, updated_at, name, price_currency, price_amount, created_at, version,
+		brewery, style, abv, ibu, volume, description, brewery_id)
+	VALUES($1, $2, $3, $4, $5, $6, 1, $7, $8, $9, $10, $11, $12, $13)
+	ON CONFLICT (id) DO NOTHING
+	RETURNING version, created_at`
+	args := append([]any{beer.ID, beer.UpdatedAt, beer.Name, beer.Price.Currency, beer.Price.Amount, beer.CreatedAt}, details...)
+
+	if beer.Version != 0 {
+		q = `UPDATE beer
+		SET updated_at = $2, name = $3, price_currency = $4, price_amount = $5, version = version + 1,
+			brewery = $7, style = $8, abv = $9, ibu = $10, volume = $11, description = $12, brewery_id = $13
+		WHERE id = $1 AND version = $6 AND deleted_at IS NULL
+		RETURNING version, created_at`
+		args = append([]any{beer.ID, beer.UpdatedAt, beer.Name, beer.Price.Currency, beer.Price.Amount, beer.Version}, details...)
+	}
+
+	err := db.QueryRow(ctx, q, args...).Scan(&beer.Version, &beer.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Errorf(
+			"unable to save beer %q at version %d: %w",
+			beer.ID,
+			beer.Version,
+			burp.ErrVersionConflict,
+		)
+	}
 
-	_, err := r.Conn.Exec(ctx, q, beer.ID, beer.CreatedAt, beer.UpdatedAt, beer.Name, beer.Price.Currency, beer.Price.Amount)
 	if err != nil {
 		return repo.Error(err.Error())
 	}
 
+	return saveTags(ctx, db, beer)
+}
+
+// saveTags replaces the tags of beer, within the
+// transaction of the beer row, by those it has.
+func saveTags(ctx context.Context, db DB, beer *burp.Beer) error {
+	if _, err := db.Exec(ctx, `DELETE FROM beer_tag WHERE beer_id = $1`, beer.ID); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	if len(beer.Tags) == 0 {
+		return nil
+	}
+
+	q := `INSERT INTO beer_tag(beer_id, tag) SELECT $1, unnest($2::text[])`
+	if _, err := db.Exec(ctx, q, beer.ID, beer.Tags); err != nil {
+		return repo.Error(err.Error())
+	}
+
 	return nil
 }
 
-func (r *Repo) RemoveBeer(ctx context.Context, id burp.ID) error {
-	q := `DELETE FROM beer WHERE id = $1`
+func (r *Repo) RemoveBeer(ctx context.Context, id burp.ID, at time.Time) error {
+	q := `UPDATE beer SET deleted_at = $2, version = version + 1
+	WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := r.Conn.Exec(ctx, q, id)
-	return err
+	return r.execOnBeer(ctx, q, id, at)
+}
+
+func (r *Repo) RestoreBeer(ctx context.Context, id burp.ID) error {
+	q := `UPDATE beer SET deleted_at = NULL, version = version + 1
+	WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	return r.execOnBeer(ctx, q, id)
+}
+
+func (r *Repo) PurgeBeer(ctx context.Context, id burp.ID) error {
+	q := `DELETE FROM beer WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	return r.execOnBeer(ctx, q, id)
+}
+
+// execOnBeer executes q on beer of given id, $1, then args, failing
+// with repo.ErrNotFound when no beer is affected.
+func (r *Repo) execOnBeer(ctx context.Context, q string, id burp.ID, args ...any) error {
+	tag, err := r.db(ctx).Exec(ctx, q, append([]any{id}, args...)...)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repo.Errorf(
+			"beer not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	return nil
 }
 
 func (r *Repo) SelectBeer(ctx context.Context, id burp.ID) (*burp.Beer, error) {
+	q := `SELECT ` + beerColumns + ` FROM beer WHERE id = $1 AND deleted_at IS NULL`
+	beer, err := scanBeer(r.db(ctx).QueryRow(ctx, q, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.Errorf(
+			"beer not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	return beer, err
+}
+
+// sortColumns maps each sort to the column it orders by and the type
+// its cursor key is cast to. Text is compared bytewise to match cursor keys.
+var sortColumns = map[burp.BeerSort]struct{ expr, typ string }{
+	burp.SortByName:      {expr: `name COLLATE "C"`, typ: "text"},
+	burp.SortByCreatedAt: {expr: "created_at", typ: "timestamp"},
+	burp.SortByPrice:     {expr: "price_amount", typ: "bigint"},
+}
+
+func (r *Repo) ListBeers(ctx context.Context, q burp.BeerQuery) ([]*burp.Beer, error) {
+	var (
+		where = []string{"deleted_at IS NULL"}
+		args  []any
+	)
+
+	if q.Filter.Deleted {
+		where[0] = "deleted_at IS NOT NULL"
+	}
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Filter.Name != "" {
+		where = append(where, "name ILIKE "+arg("%"+escapeLike(q.Filter.Name)+"%"))
+	}
+
+	if q.Filter.BreweryID.UUID != uuid.Nil {
+		where = append(where, "brewery_id = "+arg(q.Filter.BreweryID))
+	}
+
+	for _, tag := range q.Filter.Tags {
+		where = append(where, "EXISTS (SELECT 1 FROM beer_tag WHERE beer_id = beer.id AND tag = "+arg(tag)+")")
+	}
+
+	if q.Filter.Currency != "" {
+		where = append(where, "price_currency = "+arg(q.Filter.Currency))
+	}
+
+	if q.Filter.MinAmount != 0 {
+		where = append(where, "price_amount >= "+arg(q.Filter.MinAmount))
+	}
+
+	if q.Filter.MaxAmount != 0 {
+		where = append(where, "price_amount <= "+arg(q.Filter.MaxAmount))
+	}
+
+	col, ok := sortColumns[q.Sort]
+	if !ok {
+		return nil, repo.Errorf("unable to sort beers by %q", q.Sort)
+	}
+
+	op, dir := ">", "ASC"
+	if q.Desc {
+		op, dir = "<", "DESC"
+	}
+
+	if q.After != nil {
+		where = append(where, fmt.Sprintf(
+			`(%s, id COLLATE "C") %s (%s::%s, %s)`,
+			col.expr, op, arg(q.After.Key), col.typ, arg(q.After.ID),
+		))
+	}
+
+	query := `SELECT ` + beerColumns + ` FROM beer WHERE ` + strings.Join(where, " AND ")
+	query += fmt.Sprintf(` ORDER BY %s %s, id COLLATE "C" %s LIMIT %s`, col.expr, dir, dir, arg(q.Limit))
+
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	beers := []*burp.Beer{}
+	for rows.Next() {
+		beer, err := scanBeer(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		beers = append(beers, beer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return beers, nil
+}
+
+// SearchBeers ranks beers the same way burp.Score does, relying on
+// pg_trgm similarity operator for names not containing searched text.
+func (r *Repo) SearchBeers(ctx context.Context, s burp.BeerSearch) ([]*burp.BeerMatch, error) {
+	q := `SELECT ` + beerColumns + `,
+		similarity(name, $1)::float8 + CASE WHEN name ILIKE $2 THEN 1 ELSE 0 END AS score
+	FROM beer
+	WHERE deleted_at IS NULL AND (name % $1 OR name ILIKE $2)
+	ORDER BY score DESC, name COLLATE "C", id COLLATE "C"
+	LIMIT $3`
+
+	rows, err := r.db(ctx).Query(ctx, q, s.Text, "%"+escapeLike(s.Text)+"%", s.Limit)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	matches := []*burp.BeerMatch{}
+	for rows.Next() {
+		var match burp.BeerMatch
+		match.Beer, err = scanBeer(rows, &match.Score)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		matches = append(matches, &match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return matches, nil
+}
+
+const beerColumns = `id, created_at, updated_at, version, deleted_at, name, price_currency, price_amount,
+	brewery, style, abv, ibu, volume, description, brewery_id,
+	ARRAY(SELECT tag FROM beer_tag WHERE beer_id = beer.id ORDER BY tag COLLATE "C") AS tags`
+
+// scanBeer scans beerColumns from row, followed by extra columns into dest.
+func scanBeer(row pgx.Row, dest ...any) (*burp.Beer, error) {
 	var beer burp.Beer
-	q := `SELECT id, created_at, updated_at, name, price_currency, price_amount FROM beer WHERE id = $1`
-	row := r.Conn.QueryRow(ctx, q, id)
-	err := row.Scan(
+	err := row.Scan(append([]any{
 		&beer.ID,
 		&beer.CreatedAt,
 		&beer.UpdatedAt,
+		&beer.Version,
+		&beer.DeletedAt,
 		&beer.Name,
 		&beer.Price.Currency,
 		&beer.Price.Amount,
+		&beer.Brewery,
+		&beer.Style,
+		&beer.ABV,
+		&beer.IBU,
+		&beer.Volume,
+		&beer.Description,
+		&beer.BreweryID,
+		&beer.Tags,
+	}, dest...)...)
+
+	// beers without tags have none rather than an empty list
+	if len(beer.Tags) == 0 {
+		beer.Tags = nil
+	}
+
+	return &beer, err
+}
+
+// escapeLike escapes LIKE wildcards so that str is matched literally.
+func escapeLike(str string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(str)
+}
+
+func (r *Repo) SaveBeerEvent(ctx context.Context, e *burp.BeerEvent) error {
+	q := `INSERT INTO beer_event(id, beer_id, kind, actor, time, before, after)
+	VALUES($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db(ctx).Exec(ctx, q, e.ID, e.BeerID, e.Kind, e.Actor, e.Time, e.Before, e.After)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectBeerHistory(ctx context.Context, id burp.ID) ([]*burp.BeerEvent, error) {
+	q := `SELECT id, beer_id, kind, actor, time, before, after
+	FROM beer_event
+	WHERE beer_id = $1
+	ORDER BY time, seq`
+
+	rows, err := r.db(ctx).Query(ctx, q, id)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	events := []*burp.BeerEvent{}
+	for rows.Next() {
+		var e burp.BeerEvent
+		err := rows.Scan(&e.ID, &e.BeerID, &e.Kind, &e.Actor, &e.Time, &e.Before, &e.After)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return events, nil
+}
+
+func (r *Repo) SavePriceChange(ctx context.Context, c *burp.PriceChange) error {
+	q := `INSERT INTO beer_price(beer_id, valid_from, price_currency, price_amount)
+	VALUES($1, $2, $3, $4)
+	ON CONFLICT (beer_id, valid_from)
+	DO
+	UPDATE SET price_currency = $3, price_amount = $4`
+
+	_, err := r.db(ctx).Exec(ctx, q, c.BeerID, c.From, c.Price.Currency, c.Price.Amount)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectPriceChanges(ctx context.Context, id burp.ID) ([]*burp.PriceChange, error) {
+	q := `SELECT beer_id, valid_from, price_currency, price_amount
+	FROM beer_price
+	WHERE beer_id = $1
+	ORDER BY valid_from`
+
+	rows, err := r.db(ctx).Query(ctx, q, id)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	changes := []*burp.PriceChange{}
+	for rows.Next() {
+		c, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return changes, nil
+}
+
+func (r *Repo) SelectPriceAt(ctx context.Context, id burp.ID, at time.Time) (*burp.PriceChange, error) {
+	q := `SELECT beer_id, valid_from, price_currency, price_amount
+	FROM beer_price
+	WHERE beer_id = $1 AND valid_from <= $2
+	ORDER BY valid_from DESC
+	LIMIT 1`
+
+	c, err := scanPriceChange(r.db(ctx).QueryRow(ctx, q, id, at))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.Errorf(
+			"price of beer %q not found at %s: %w",
+			id,
+			at,
+			repo.ErrNotFound,
+		)
+	}
+
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return c, nil
+}
+
+func scanPriceChange(row pgx.Row) (*burp.PriceChange, error) {
+	var c burp.PriceChange
+	err := row.Scan(&c.BeerID, &c.From, &c.Price.Currency, &c.Price.Amount)
+	return &c, err
+}
+
+// SaveBrewery inserts brewery when its version is zero, otherwise updates
+// it if its version still matches the stored one. Version is then incremented.
+func (r *Repo) SaveBrewery(ctx context.Context, brewery *burp.Brewery) error {
+	q := `INSERT INTO brewery(id, created_at, updated_at, version, name, country, city)
+	VALUES($1, $2, $3, 1, $4, $5, $6)
+	ON CONFLICT (id) DO NOTHING
+	RETURNING version, created_at`
+	args := []any{brewery.ID, brewery.CreatedAt, brewery.UpdatedAt, brewery.Name, brewery.Country, brewery.City}
+
+	if brewery.Version != 0 {
+		q = `UPDATE brewery
+		SET updated_at = $3, version = version + 1, name = $4, country = $5, city = $6
+		WHERE id = $1 AND version = $2
+		RETURNING version, created_at`
+		args = []any{brewery.ID, brewery.Version, brewery.UpdatedAt, brewery.Name, brewery.Country, brewery.City}
+	}
+
+	err := r.db(ctx).QueryRow(ctx, q, args...).Scan(&brewery.Version, &brewery.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Errorf(
+			"unable to save brewery %q at version %d: %w",
+			brewery.ID,
+			brewery.Version,
+			burp.ErrVersionConflict,
+		)
+	}
+
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectBrewery(ctx context.Context, id burp.ID) (*burp.Brewery, error) {
+	q := `SELECT ` + breweryColumns + ` FROM brewery WHERE id = $1`
+	brewery, err := scanBrewery(r.db(ctx).QueryRow(ctx, q, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.Errorf(
+			"brewery not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return brewery, nil
+}
+
+func (r *Repo) ListBreweries(ctx context.Context, q burp.BreweryQuery) ([]*burp.Brewery, error) {
+	var (
+		where []string
+		args  []any
+	)
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Name != "" {
+		where = append(where, "name ILIKE "+arg("%"+escapeLike(q.Name)+"%"))
+	}
+
+	if q.After != nil {
+		where = append(where, fmt.Sprintf(
+			`(name COLLATE "C", id COLLATE "C") > (%s::text, %s)`,
+			arg(q.After.Key), arg(q.After.ID),
+		))
+	}
+
+	query := `SELECT ` + breweryColumns + ` FROM brewery`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY name COLLATE "C", id COLLATE "C" LIMIT ` + arg(q.Limit)
+
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	breweries := []*burp.Brewery{}
+	for rows.Next() {
+		brewery, err := scanBrewery(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		breweries = append(breweries, brewery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return breweries, nil
+}
+
+// RemoveBrewery deletes a brewery, failing with burp.ErrBreweryHasBeers
+// while beers refer to it, even ones saved since the caller checked.
+func (r *Repo) RemoveBrewery(ctx context.Context, id burp.ID) error {
+	tag, err := r.db(ctx).Exec(ctx, `DELETE FROM brewery WHERE id = $1`, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return repo.Errorf("unable to remove brewery %q: %w", id, burp.ErrBreweryHasBeers)
+	}
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repo.Errorf(
+			"brewery not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	return nil
+}
+
+// foreignKeyViolation is the SQLSTATE of a statement breaking a foreign key.
+const foreignKeyViolation = "23503"
+
+const breweryColumns = `id, created_at, updated_at, version, name, country, city`
+
+func scanBrewery(row pgx.Row) (*burp.Brewery, error) {
+	var b burp.Brewery
+	err := row.Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt, &b.Version, &b.Name, &b.Country, &b.City)
+	return &b, err
+}
+
+// MoveStock moves stock within a transaction, locking its row so that
+// concurrent movements apply one after the other.
+func (r *Repo) MoveStock(ctx context.Context, m *burp.StockMovement) (*burp.Stock, error) {
+	var stock *burp.Stock
+	err := pgx.BeginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		var err error
+		if stock, err = lockStock(ctx, tx, m.BeerID, m.Location, m.Time); err != nil {
+			return err
+		}
+
+		if err := stock.Apply(m); err != nil {
+			return repo.Errorf("unable to move stock of beer %q at %q by %d: %w", m.BeerID, m.Location, m.Delta, err)
+		}
+
+		q := `UPDATE stock SET quantity = $3, updated_at = $4 WHERE beer_id = $1 AND location = $2`
+		if _, err := tx.Exec(ctx, q, stock.BeerID, stock.Location, stock.Quantity, stock.UpdatedAt); err != nil {
+			return repo.Error(err.Error())
+		}
+
+		return nil
+	})
+
+	if errors.As(err, &repo.Err{}) {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return stock, nil
+}
+
+func (r *Repo) SetStockThreshold(ctx context.Context, t *burp.StockThreshold) (*burp.Stock, error) {
+	q := `INSERT INTO stock(beer_id, location, threshold, updated_at)
+	VALUES($1, $2, $3, $4)
+	ON CONFLICT (beer_id, location) DO UPDATE
+	SET threshold = excluded.threshold, updated_at = excluded.updated_at
+	RETURNING ` + stockColumns
+
+	stock, err := scanStock(r.db(ctx).QueryRow(ctx, q, t.BeerID, t.Location, t.Threshold, t.Time))
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return stock, nil
+}
+
+// lockStock returns the stock of beer id at location, inserted empty
+// at given time if new, and locks its row until tx ends.
+func lockStock(ctx context.Context, tx pgx.Tx, id burp.ID, location string, at time.Time) (*burp.Stock, error) {
+	q := `INSERT INTO stock(beer_id, location, updated_at) VALUES($1, $2, $3)
+	ON CONFLICT (beer_id, location) DO NOTHING`
+	if _, err := tx.Exec(ctx, q, id, location, at); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	q = `SELECT ` + stockColumns + ` FROM stock WHERE beer_id = $1 AND location = $2 FOR UPDATE`
+	stock, err := scanStock(tx.QueryRow(ctx, q, id, location))
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return stock, nil
+}
+
+func (r *Repo) ListStock(ctx context.Context, q burp.StockQuery) ([]*burp.Stock, error) {
+	var (
+		where []string
+		args  []any
 	)
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.BeerID.UUID != uuid.Nil {
+		where = append(where, "beer_id = "+arg(q.BeerID))
+	}
+
+	if q.Location != "" {
+		where = append(where, "location = "+arg(q.Location))
+	}
+
+	if q.Low {
+		where = append(where, "quantity < threshold")
+	}
+
+	query := `SELECT ` + stockColumns + ` FROM stock`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY beer_id COLLATE "C", location COLLATE "C"`
+
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	stock := []*burp.Stock{}
+	for rows.Next() {
+		s, err := scanStock(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		stock = append(stock, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return stock, nil
+}
+
+const stockColumns = `beer_id, location, quantity, threshold, updated_at`
+
+func scanStock(row pgx.Row) (*burp.Stock, error) {
+	var s burp.Stock
+	err := row.Scan(&s.BeerID, &s.Location, &s.Quantity, &s.Threshold, &s.UpdatedAt)
+	return &s, err
+}
+
+// SaveOrder inserts order along with its lines when its version is zero,
+// otherwise updates its status if its version still matches the stored
+// one. Version is then incremented.
+func (r *Repo) SaveOrder(ctx context.Context, order *burp.Order) error {
+	given := *order
+	err := pgx.BeginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		return saveOrder(ctx, tx, order)
+	})
+
+	if err != nil {
+		*order = given
+	}
+
+	if err != nil && !errors.As(err, &repo.Err{}) {
+		return repo.Error(err.Error())
+	}
+
+	return err
+}
+
+func saveOrder(ctx context.Context, db DB, order *burp.Order) error {
+	q := `INSERT INTO customer_order(id, created_at, updated_at, version, customer, status)
+	VALUES($1, $2, $3, 1, $4, $5)
+	ON CONFLICT (id) DO NOTHING
+	RETURNING version, created_at`
+	args := []any{order.ID, order.CreatedAt, order.UpdatedAt, order.Customer, order.Status}
+
+	if order.Version != 0 {
+		q = `UPDATE customer_order
+		SET updated_at = $3, version = version + 1, status = $4
+		WHERE id = $1 AND version = $2
+		RETURNING version, created_at`
+		args = []any{order.ID, order.Version, order.UpdatedAt, order.Status}
+	}
+
+	inserted := order.Version == 0
+	err := db.QueryRow(ctx, q, args...).Scan(&order.Version, &order.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Errorf(
+			"unable to save order %q at version %d: %w",
+			order.ID,
+			order.Version,
+			burp.ErrVersionConflict,
+		)
+	}
+
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	if !inserted {
+		return nil
+	}
+
+	q = `INSERT INTO order_line(order_id, position, beer_id, name, quantity, price_currency, price_amount)
+	VALUES($1, $2, $3, $4, $5, $6, $7)`
+	for i, l := range order.Lines {
+		if _, err := db.Exec(ctx, q, order.ID, i, l.BeerID, l.Name, l.Quantity, l.Price.Currency, l.Price.Amount); err != nil {
+			return repo.Error(err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectOrder(ctx context.Context, id burp.ID) (*burp.Order, error) {
+	q := `SELECT ` + orderColumns + ` FROM customer_order WHERE id = $1`
+	order, err := scanOrder(r.db(ctx).QueryRow(ctx, q, id))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, repo.Errorf(
-			"beer not found with id %q: %w",
+			"order not found with id %q: %w",
 			id,
 			repo.ErrNotFound,
 		)
 	}
 
-	return &beer, err
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	if err := r.selectOrderLines(ctx, []*burp.Order{order}); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *Repo) ListOrders(ctx context.Context, q burp.OrderQuery) ([]*burp.Order, error) {
+	var args []any
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query := `SELECT ` + orderColumns + ` FROM customer_order`
+	if q.Status != "" {
+		query += ` WHERE status = ` + arg(q.Status)
+	}
+	query += ` ORDER BY created_at DESC, id COLLATE "C" LIMIT ` + arg(q.Limit)
+
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	orders := []*burp.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	if err := r.selectOrderLines(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// selectOrderLines sets the lines of orders at once.
+func (r *Repo) selectOrderLines(ctx context.Context, orders []*burp.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byID := make(map[burp.ID]*burp.Order, len(orders))
+	ids := make([]string, len(orders))
+	for i, order := range orders {
+		byID[order.ID] = order
+		order.Lines = []burp.OrderLine{}
+		ids[i] = order.ID.String()
+	}
+
+	q := `SELECT order_id, beer_id, name, quantity, price_currency, price_amount
+	FROM order_line
+	WHERE order_id = ANY($1)
+	ORDER BY order_id, position`
+
+	rows, err := r.db(ctx).Query(ctx, q, ids)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id burp.ID
+			l  burp.OrderLine
+		)
+		if err := rows.Scan(&id, &l.BeerID, &l.Name, &l.Quantity, &l.Price.Currency, &l.Price.Amount); err != nil {
+			return repo.Error(err.Error())
+		}
+		byID[id].Lines = append(byID[id].Lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+const orderColumns = `id, created_at, updated_at, version, customer, status`
+
+func scanOrder(row pgx.Row) (*burp.Order, error) {
+	var o burp.Order
+	err := row.Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt, &o.Version, &o.Customer, &o.Status)
+	return &o, err
 }
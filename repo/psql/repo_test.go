@@ -4,13 +4,17 @@ import (
 	"burp"
 	"burp/burptest"
 	"burp/repo"
+	"burp/repo/repotest"
 	"errors"
 	"fmt"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestSaveBeer(t *testing.T) {
@@ -21,9 +25,9 @@ func TestSaveBeer(t *testing.T) {
 		t.Errorf("SaveBeer(ctx, %+v) should not return an error", beer)
 	}
 
-	selectQuery := "SELECT id, created_at, updated_at, name, price_currency, price_amount FROM beer WHERE id = $1"
+	selectQuery := "SELECT id, created_at, updated_at, version, deleted_at, name, price_currency, price_amount FROM beer WHERE id = $1"
 
-	got, err := scanBeerRow(conn.QueryRow(ctx, selectQuery, beer.ID))
+	got, err := scanBeerRow(db.QueryRow(ctx, selectQuery, beer.ID))
 	if err != nil {
 		t.Fatalf("Querying %selectQuery should not fail, got %s", selectQuery, err)
 	}
@@ -38,7 +42,7 @@ func TestSaveBeer(t *testing.T) {
 		t.Errorf("SaveBeer(ctx, %+v) returned unexpected error: %s", beer, err)
 	}
 
-	got, err = scanBeerRow(conn.QueryRow(ctx, selectQuery, beer.ID))
+	got, err = scanBeerRow(db.QueryRow(ctx, selectQuery, beer.ID))
 	if err != nil {
 		t.Fatalf("Querying %selectQuery should not fail, got %s", selectQuery, err)
 	}
@@ -48,21 +52,172 @@ func TestSaveBeer(t *testing.T) {
 	}
 }
 
+func TestSaveBeerPreservesCreationDate(t *testing.T) {
+	beer := burptest.RandBeer()
+	createdAt := beer.CreatedAt
+
+	insertBeer(t, beer)
+
+	beer.CreatedAt = burptest.RandTime()
+	err := appRepo.SaveBeer(ctx, beer)
+	if err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error: %s", beer, err)
+	}
+
+	if !beer.CreatedAt.Equal(createdAt) {
+		t.Errorf("SaveBeer(ctx, %+v) set back creation date %s, want stored one %s", beer, beer.CreatedAt, createdAt)
+	}
+
+	got, err := appRepo.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned error %s, want none", beer.ID, err)
+	}
+
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("SaveBeer(ctx, %+v) overwrote creation date with %s, want %s", beer, got.CreatedAt, createdAt)
+	}
+}
+
+func TestSaveBeerWithStaleVersion(t *testing.T) {
+	beer := burptest.RandBeer()
+
+	insertBeer(t, beer)
+
+	stale := *beer
+	stale.Version = 0
+
+	err := appRepo.SaveBeer(ctx, &stale)
+	if !errors.Is(err, burp.ErrVersionConflict) {
+		t.Errorf("SaveBeer(ctx, %+v) of an existing beer with version 0 returned error %v, want %s", stale, err, burp.ErrVersionConflict)
+	}
+
+	err = appRepo.SaveBeer(ctx, beer)
+	if err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error: %s", beer, err)
+	}
+
+	stale.Version = 1
+
+	err = appRepo.SaveBeer(ctx, &stale)
+	if !errors.Is(err, burp.ErrVersionConflict) {
+		t.Errorf("SaveBeer(ctx, %+v) with an outdated version returned error %v, want %s", stale, err, burp.ErrVersionConflict)
+	}
+}
+
 func TestRemoveBeer(t *testing.T) {
 	beer := burptest.RandBeer()
 
 	insertBeer(t, beer)
 
-	err := appRepo.RemoveBeer(ctx, beer.ID)
+	err := appRepo.RemoveBeer(ctx, beer.ID, burptest.RandTime())
 	if err != nil {
 		t.Errorf("RemoveBeer(ctx, %s) returnd error %s, want none", beer.ID, err)
 	}
 
+	var deleted bool
+	db.QueryRow(ctx, "SELECT deleted_at IS NOT NULL FROM beer WHERE id = $1", beer.ID).Scan(&deleted)
+
+	if !deleted {
+		t.Errorf("Selecting beer after deletion returned no deletion date, want beer in trash")
+	}
+
+	_, err = appRepo.SelectBeer(ctx, beer.ID)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("SelectBeer(ctx, %s) of a beer in trash returned error %v, want %s", beer.ID, err, repo.ErrNotFound)
+	}
+
+	err = appRepo.RemoveBeer(ctx, beer.ID, burptest.RandTime())
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("RemoveBeer(ctx, %s) of a beer in trash returned error %v, want %s", beer.ID, err, repo.ErrNotFound)
+	}
+}
+
+func TestRestoreBeer(t *testing.T) {
+	beer := burptest.RandBeer()
+
+	insertBeer(t, beer)
+
+	err := appRepo.RestoreBeer(ctx, beer.ID)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("RestoreBeer(ctx, %s) of a beer not in trash returned error %v, want %s", beer.ID, err, repo.ErrNotFound)
+	}
+
+	if err := appRepo.RemoveBeer(ctx, beer.ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned error %s, want none", beer.ID, err)
+	}
+
+	if err := appRepo.RestoreBeer(ctx, beer.ID); err != nil {
+		t.Errorf("RestoreBeer(ctx, %s) returned error %s, want none", beer.ID, err)
+	}
+
+	got, err := appRepo.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) of a restored beer returned error %s, want none", beer.ID, err)
+	}
+
+	beer.Version = 3
+	if diff := cmp.Diff(beer, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) returned unexpected restored beer, (-want/+got):\n%s", beer.ID, diff)
+	}
+}
+
+func TestPurgeBeer(t *testing.T) {
+	beer := burptest.RandBeer()
+
+	insertBeer(t, beer)
+
+	err := appRepo.PurgeBeer(ctx, beer.ID)
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("PurgeBeer(ctx, %s) of a beer not in trash returned error %v, want %s", beer.ID, err, repo.ErrNotFound)
+	}
+
+	if err := appRepo.RemoveBeer(ctx, beer.ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned error %s, want none", beer.ID, err)
+	}
+
+	if err := appRepo.PurgeBeer(ctx, beer.ID); err != nil {
+		t.Errorf("PurgeBeer(ctx, %s) returned error %s, want none", beer.ID, err)
+	}
+
 	var count int
-	conn.QueryRow(ctx, "SELECT COUNT(*) FROM beer WHERE id = $1", beer.ID).Scan(&count)
+	db.QueryRow(ctx, "SELECT COUNT(*) FROM beer WHERE id = $1", beer.ID).Scan(&count)
 
 	if count != 0 {
-		t.Errorf("Selecting beer count after deletion returned %d, want 0", count)
+		t.Errorf("Selecting beer count after purge returned %d, want 0", count)
+	}
+}
+
+func TestSaveBeerConcurrently(t *testing.T) {
+	beer := burptest.RandBeer()
+	insertBeer(t, beer)
+
+	const writers = 10
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			update := *beer
+			update.Name = fmt.Sprintf("concurrent %d", i)
+			errs <- appRepo.SaveBeer(ctx, &update)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	var saved int
+	for err := range errs {
+		switch {
+		case err == nil:
+			saved++
+		case !errors.Is(err, burp.ErrVersionConflict):
+			t.Errorf("SaveBeer(ctx, beer) concurrently returned error %s, want none or %v", err, burp.ErrVersionConflict)
+		}
+	}
+
+	if saved != 1 {
+		t.Errorf("SaveBeer(ctx, beer) concurrently at the same version saved %d times, want 1", saved)
 	}
 }
 
@@ -102,18 +257,195 @@ func TestSelectBeerNotFound(t *testing.T) {
 	}
 }
 
+func TestListBeers(t *testing.T) {
+	prefix := burptest.RandString(10)
+	beers := []*burp.Beer{burptest.RandBeer(), burptest.RandBeer(), burptest.RandBeer()}
+	for i, beer := range beers {
+		beer.Name = prefix + string(rune('a'+i))
+		insertBeer(t, beer)
+	}
+
+	q := burp.BeerQuery{
+		Filter: burp.BeerFilter{Name: strings.ToLower(prefix)},
+		Sort:   burp.SortByName,
+		Limit:  2,
+	}
+
+	got, err := appRepo.ListBeers(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, %+v) returned error %s, want none", q, err)
+	}
+
+	if diff := cmp.Diff(beers[:2], got); diff != "" {
+		t.Errorf("ListBeers(ctx, %+v) returned unexpected beers, (-want/+got):\n%s", q, diff)
+	}
+
+	q.After = burp.NewCursor(got[1], q.Sort, q.Desc)
+
+	got, err = appRepo.ListBeers(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, %+v) returned error %s, want none", q, err)
+	}
+
+	if diff := cmp.Diff(beers[2:], got); diff != "" {
+		t.Errorf("ListBeers(ctx, %+v) after cursor returned unexpected beers, (-want/+got):\n%s", q, diff)
+	}
+}
+
+func TestListBeersByPrice(t *testing.T) {
+	prefix := burptest.RandString(10)
+	amounts := []uint{300, 100, 200, 400}
+	var beers []*burp.Beer
+	for i, amount := range amounts {
+		beer := burptest.RandBeer()
+		beer.Name = prefix + string(rune('a'+i))
+		beer.Price = burp.Price{Currency: burp.EUR, Amount: amount}
+		insertBeer(t, beer)
+		beers = append(beers, beer)
+	}
+
+	dollarBeer := burptest.RandBeer()
+	dollarBeer.Name = prefix + "z"
+	dollarBeer.Price = burp.Price{Currency: burp.USD, Amount: 200}
+	insertBeer(t, dollarBeer)
+
+	q := burp.BeerQuery{
+		Filter: burp.BeerFilter{Name: prefix, Currency: burp.EUR, MinAmount: 150, MaxAmount: 400},
+		Sort:   burp.SortByPrice,
+		Desc:   true,
+		Limit:  10,
+	}
+
+	got, err := appRepo.ListBeers(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, %+v) returned error %s, want none", q, err)
+	}
+
+	want := []*burp.Beer{beers[3], beers[0], beers[2]}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListBeers(ctx, %+v) returned unexpected beers, (-want/+got):\n%s", q, diff)
+	}
+}
+
+func TestSearchBeers(t *testing.T) {
+	word := burptest.RandString(8)
+	exact, misspelled := burptest.RandBeer(), burptest.RandBeer()
+	exact.Name = word + " ale"
+	misspelled.Name = word[:7] + "x"
+	insertBeer(t, misspelled)
+	insertBeer(t, exact)
+
+	s := burp.BeerSearch{Text: word, Limit: 10}
+
+	got, err := appRepo.SearchBeers(ctx, s)
+	if err != nil {
+		t.Fatalf("SearchBeers(ctx, %+v) returned error %s, want none", s, err)
+	}
+
+	want := []*burp.BeerMatch{
+		{Beer: exact, Score: burp.Score(exact, word)},
+		{Beer: misspelled, Score: burp.Score(misspelled, word)},
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(0, 1e-6)); diff != "" {
+		t.Errorf("SearchBeers(ctx, %+v) returned unexpected matches, (-want/+got):\n%s", s, diff)
+	}
+}
+
+func TestBeerHistory(t *testing.T) {
+	beer := burptest.RandBeer()
+	after := *beer
+	after.Version = 1
+	events := []*burp.BeerEvent{
+		{
+			ID:     burp.ID{UUID: uuid.New()},
+			BeerID: beer.ID,
+			Kind:   burp.BeerCreated,
+			Actor:  burptest.RandString(10),
+			Time:   burptest.RandTime(),
+			After:  &after,
+		},
+		{
+			ID:     burp.ID{UUID: uuid.New()},
+			BeerID: beer.ID,
+			Kind:   burp.BeerDeleted,
+			Time:   time.Now().UTC().Truncate(time.Microsecond),
+			Before: &after,
+		},
+	}
+
+	for _, e := range events {
+		if err := appRepo.SaveBeerEvent(ctx, e); err != nil {
+			t.Fatalf("SaveBeerEvent(ctx, %+v) returned error %s, want none", e, err)
+		}
+	}
+
+	got, err := appRepo.SelectBeerHistory(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeerHistory(ctx, %s) returned error %s, want none", beer.ID, err)
+	}
+
+	if diff := cmp.Diff(events, got); diff != "" {
+		t.Errorf("SelectBeerHistory(ctx, %s) returned unexpected events, (-want/+got):\n%s", beer.ID, diff)
+	}
+}
+
+func TestPriceChanges(t *testing.T) {
+	beer := burptest.RandBeer()
+	insertBeer(t, beer)
+
+	from := time.Now().UTC().Truncate(time.Microsecond)
+	changes := []*burp.PriceChange{
+		{BeerID: beer.ID, Price: burp.Price{Currency: burp.EUR, Amount: 100}, From: from},
+		{BeerID: beer.ID, Price: burp.Price{Currency: burp.EUR, Amount: 200}, From: from.Add(time.Hour)},
+	}
+
+	for _, c := range changes {
+		if err := appRepo.SavePriceChange(ctx, c); err != nil {
+			t.Fatalf("SavePriceChange(ctx, %+v) returned error %s, want none", c, err)
+		}
+	}
+
+	got, err := appRepo.SelectPriceChanges(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectPriceChanges(ctx, %s) returned error %s, want none", beer.ID, err)
+	}
+
+	if diff := cmp.Diff(changes, got); diff != "" {
+		t.Errorf("SelectPriceChanges(ctx, %s) returned unexpected changes, (-want/+got):\n%s", beer.ID, diff)
+	}
+
+	at, err := appRepo.SelectPriceAt(ctx, beer.ID, from.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("SelectPriceAt(ctx, %s, %s) returned error %s, want none", beer.ID, from, err)
+	}
+
+	if diff := cmp.Diff(changes[0], at); diff != "" {
+		t.Errorf("SelectPriceAt(ctx, %s, %s) returned unexpected change, (-want/+got):\n%s", beer.ID, from, diff)
+	}
+
+	_, err = appRepo.SelectPriceAt(ctx, beer.ID, from.Add(-time.Minute))
+	if !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("SelectPriceAt(ctx, %s) before any change returned error %v, want %v", beer.ID, err, repo.ErrNotFound)
+	}
+}
+
 func insertBeer(t *testing.T, beer *burp.Beer) {
 	t.Helper()
 
-	insertQuery := `INSERT INTO beer(id, created_at, updated_at, name, price_currency, price_amount)
-	VALUES($1, $2, $3, $4, $5, $6)`
+	insertQuery := `INSERT INTO beer(id, created_at, updated_at, version, name, price_currency, price_amount)
+	VALUES($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := conn.Exec(
+	// beer is stored as if it had been saved once
+	beer.Version = 1
+
+	_, err := db.Exec(
 		ctx,
 		insertQuery,
 		beer.ID,
 		beer.CreatedAt,
 		beer.UpdatedAt,
+		beer.Version,
 		beer.Name,
 		beer.Price.Currency,
 		beer.Price.Amount,
@@ -129,9 +461,37 @@ func scanBeerRow(row pgx.Row) (*burp.Beer, error) {
 		&got.ID,
 		&got.CreatedAt,
 		&got.UpdatedAt,
+		&got.Version,
+		&got.DeletedAt,
 		&got.Name,
 		&got.Price.Currency,
 		&got.Price.Amount,
 	)
 	return &got, err
 }
+
+func TestRemoveBreweryWithBeers(t *testing.T) {
+	brewery := burptest.RandBrewery()
+	if err := appRepo.SaveBrewery(ctx, brewery); err != nil {
+		t.Fatalf("SaveBrewery(ctx, %+v) returned unexpected error %s", brewery, err)
+	}
+
+	beer := burptest.RandBeer()
+	beer.BreweryID = &brewery.ID
+	if err := appRepo.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	err := appRepo.RemoveBrewery(ctx, brewery.ID)
+	if !errors.Is(err, burp.ErrBreweryHasBeers) || !errors.As(err, &repo.Err{}) {
+		t.Errorf("RemoveBrewery(ctx, %q) returned error %s, want repo.Err{} wrapping %s", brewery.ID, err, burp.ErrBreweryHasBeers)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	repotest.TestBeerRepo(t, appRepo)
+	repotest.TestTxRunner(t, appRepo)
+	repotest.TestBreweryRepo(t, appRepo)
+	repotest.TestStockRepo(t, appRepo)
+	repotest.TestOrderRepo(t, appRepo)
+}
@@ -0,0 +1,54 @@
+package psql
+
+import (
+	"burp/repo"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// tx is a transaction carried by a context,
+// along with the database it runs on.
+type tx struct {
+	db DB
+	tx pgx.Tx
+}
+
+type txKey struct{}
+
+// RunInTx runs fn in a transaction, committed when fn returns nil and
+// rolled back otherwise. Repos on the same database take part in it
+// when called with the context given to fn, as do nested RunInTx.
+func (r *Repo) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := r.tx(ctx); ok {
+		return fn(ctx)
+	}
+
+	var fnErr error
+	err := pgx.BeginFunc(ctx, r.DB, func(t pgx.Tx) error {
+		fnErr = fn(context.WithValue(ctx, txKey{}, tx{db: r.DB, tx: t}))
+		return fnErr
+	})
+
+	if err != nil && !errors.Is(err, fnErr) {
+		return repo.Error(err.Error())
+	}
+
+	return err
+}
+
+func (r *Repo) tx(ctx context.Context) (pgx.Tx, bool) {
+	t, ok := ctx.Value(txKey{}).(tx)
+	if !ok || t.db != r.DB {
+		return nil, false
+	}
+	return t.tx, true
+}
+
+// db returns the transaction ctx carries on r.DB, r.DB otherwise.
+func (r *Repo) db(ctx context.Context) DB {
+	if t, ok := r.tx(ctx); ok {
+		return t
+	}
+	return r.DB
+}
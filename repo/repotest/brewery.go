@@ -0,0 +1,212 @@
+package repotest
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo"
+	"context"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+// BreweryRepo is a repo storing breweries along with their beers.
+type BreweryRepo interface {
+	burp.BeerRepo
+	burp.BreweryRepo
+}
+
+// TestBreweryRepo checks r behaves as every burp.BreweryRepo must.
+// As TestBeerRepo, it only relies on breweries and beers it saves.
+func TestBreweryRepo(t *testing.T, r BreweryRepo) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r BreweryRepo)
+	}{
+		{"SaveNewBrewery", testSaveNewBrewery},
+		{"UpdateBrewery", testUpdateBrewery},
+		{"SaveBreweryVersionConflict", testSaveBreweryVersionConflict},
+		{"SelectMissingBrewery", testSelectMissingBrewery},
+		{"ListBreweries", testListBreweries},
+		{"RemoveBrewery", testRemoveBrewery},
+		{"ListBeersOfBrewery", testListBeersOfBrewery},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, r)
+		})
+	}
+}
+
+// saveBrewery saves a new random brewery in r.
+func saveBrewery(t *testing.T, r burp.BrewerySaver) *burp.Brewery {
+	t.Helper()
+
+	brewery := burptest.RandBrewery()
+	if err := r.SaveBrewery(context.Background(), brewery); err != nil {
+		t.Fatalf("SaveBrewery(ctx, %+v) returned unexpected error %s", brewery, err)
+	}
+
+	return brewery
+}
+
+func testSaveNewBrewery(t *testing.T, r BreweryRepo) {
+	brewery := saveBrewery(t, r)
+
+	if brewery.Version != 1 {
+		t.Errorf("SaveBrewery(ctx, brewery) of a new brewery set version %d, want 1", brewery.Version)
+	}
+
+	got, err := r.SelectBrewery(context.Background(), brewery.ID)
+	if err != nil {
+		t.Fatalf("SelectBrewery(ctx, %s) returned unexpected error %s", brewery.ID, err)
+	}
+
+	if diff := cmp.Diff(brewery, got); diff != "" {
+		t.Errorf("SelectBrewery(ctx, %s) returned unexpected brewery, (-want/+got):\n%s", brewery.ID, diff)
+	}
+}
+
+func testUpdateBrewery(t *testing.T, r BreweryRepo) {
+	ctx := context.Background()
+	brewery := saveBrewery(t, r)
+	createdAt := brewery.CreatedAt
+
+	brewery.Name = burptest.RandString(20)
+	brewery.Country, brewery.City = "", burptest.RandString(12)
+	brewery.CreatedAt = burptest.RandTime()
+	brewery.UpdatedAt = burptest.RandTime()
+	if err := r.SaveBrewery(ctx, brewery); err != nil {
+		t.Fatalf("SaveBrewery(ctx, %+v) of an existing brewery returned unexpected error %s", brewery, err)
+	}
+
+	if brewery.Version != 2 {
+		t.Errorf("SaveBrewery(ctx, brewery) of an existing brewery set version %d, want 2", brewery.Version)
+	}
+
+	if !brewery.CreatedAt.Equal(createdAt) {
+		t.Errorf("SaveBrewery(ctx, brewery) of an existing brewery set creation date %s, want stored %s", brewery.CreatedAt, createdAt)
+	}
+
+	got, err := r.SelectBrewery(ctx, brewery.ID)
+	if err != nil {
+		t.Fatalf("SelectBrewery(ctx, %s) returned unexpected error %s", brewery.ID, err)
+	}
+
+	if diff := cmp.Diff(brewery, got); diff != "" {
+		t.Errorf("SelectBrewery(ctx, %s) after update returned unexpected brewery, (-want/+got):\n%s", brewery.ID, diff)
+	}
+}
+
+func testSaveBreweryVersionConflict(t *testing.T, r BreweryRepo) {
+	ctx := context.Background()
+	brewery := saveBrewery(t, r)
+
+	tests := []struct {
+		description string
+		version     uint
+	}{
+		{"already created", 0},
+		{"stale version", brewery.Version + 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			conflict := *brewery
+			conflict.Version = test.version
+
+			err := r.SaveBrewery(ctx, &conflict)
+			assertErr(t, "SaveBrewery(ctx, brewery)", err, burp.ErrVersionConflict)
+		})
+	}
+}
+
+func testSelectMissingBrewery(t *testing.T, r BreweryRepo) {
+	id := burptest.RandBrewery().ID
+	_, err := r.SelectBrewery(context.Background(), id)
+	assertErr(t, fmt.Sprintf("SelectBrewery(ctx, %s)", id), err, repo.ErrNotFound)
+}
+
+func testListBreweries(t *testing.T, r BreweryRepo) {
+	ctx := context.Background()
+
+	// breweries share a unique prefix, to be listed apart from others in r
+	prefix := burptest.RandString(10)
+	breweries := make([]*burp.Brewery, 3)
+	for i := range breweries {
+		brewery := burptest.RandBrewery()
+		brewery.Name = fmt.Sprintf("%s %d", prefix, i)
+		if err := r.SaveBrewery(ctx, brewery); err != nil {
+			t.Fatalf("SaveBrewery(ctx, %+v) returned unexpected error %s", brewery, err)
+		}
+		breweries[i] = brewery
+	}
+
+	q := burp.BreweryQuery{Name: prefix, Limit: 2}
+
+	first, err := r.ListBreweries(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBreweries(ctx, %+v) returned unexpected error %s", q, err)
+	}
+
+	if diff := cmp.Diff(breweries[:2], first); diff != "" {
+		t.Errorf("ListBreweries(ctx, %+v) returned unexpected breweries, (-want/+got):\n%s", q, diff)
+	}
+
+	q.After = &burp.Cursor{Sort: burp.SortByName, Key: breweries[1].Name, ID: breweries[1].ID}
+	next, err := r.ListBreweries(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBreweries(ctx, %+v) returned unexpected error %s", q, err)
+	}
+
+	if diff := cmp.Diff(breweries[2:], next); diff != "" {
+		t.Errorf("ListBreweries(ctx, %+v) after cursor returned unexpected breweries, (-want/+got):\n%s", q, diff)
+	}
+}
+
+func testRemoveBrewery(t *testing.T, r BreweryRepo) {
+	ctx := context.Background()
+	brewery := saveBrewery(t, r)
+
+	if err := r.RemoveBrewery(ctx, brewery.ID); err != nil {
+		t.Fatalf("RemoveBrewery(ctx, %s) returned unexpected error %s", brewery.ID, err)
+	}
+
+	_, err := r.SelectBrewery(ctx, brewery.ID)
+	assertErr(t, "SelectBrewery(ctx, removed)", err, repo.ErrNotFound)
+
+	err = r.RemoveBrewery(ctx, brewery.ID)
+	assertErr(t, "RemoveBrewery(ctx, removed)", err, repo.ErrNotFound)
+}
+
+func testListBeersOfBrewery(t *testing.T, r BreweryRepo) {
+	ctx := context.Background()
+	brewery := saveBrewery(t, r)
+
+	beer := burptest.RandBeer()
+	beer.BreweryID = &brewery.ID
+	if err := r.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+	saveBeer(t, r)
+
+	got, err := r.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if diff := cmp.Diff(beer, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) returned unexpected beer, (-want/+got):\n%s", beer.ID, diff)
+	}
+
+	q := burp.BeerQuery{Filter: burp.BeerFilter{BreweryID: brewery.ID}, Sort: burp.SortByName, Limit: 10}
+	beers, err := r.ListBeers(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, %+v) returned unexpected error %s", q, err)
+	}
+
+	if diff := cmp.Diff([]*burp.Beer{beer}, beers); diff != "" {
+		t.Errorf("ListBeers(ctx, %+v) returned unexpected beers, (-want/+got):\n%s", q, diff)
+	}
+}
@@ -0,0 +1,664 @@
+package repotest
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestBeerRepo checks r behaves as every burp.BeerRepo must. It only
+// relies on beers it saves, so r may be shared with other tests.
+//
+// Times given to repos are UTC, and are kept to the microsecond.
+func TestBeerRepo(t *testing.T, r burp.BeerRepo) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r burp.BeerRepo)
+	}{
+		{"SaveNewBeer", testSaveNewBeer},
+		{"UpdateBeer", testUpdateBeer},
+		{"SaveBeerVersionConflict", testSaveBeerVersionConflict},
+		{"SaveBeers", testSaveBeers},
+		{"SaveBeersVersionConflict", testSaveBeersVersionConflict},
+		{"SelectMissingBeer", testSelectMissingBeer},
+		{"RemoveBeer", testRemoveBeer},
+		{"RestoreBeer", testRestoreBeer},
+		{"PurgeBeer", testPurgeBeer},
+		{"RoundTripTimestamps", testRoundTripTimestamps},
+		{"RoundTripPrices", testRoundTripPrices},
+		{"ListBeers", testListBeers},
+		{"ListBeersByTags", testListBeersByTags},
+		{"ConcurrentSaves", testConcurrentSaves},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, r)
+		})
+	}
+}
+
+// saveBeer saves a new random beer in r.
+func saveBeer(t *testing.T, r burp.BeerSaver) *burp.Beer {
+	t.Helper()
+
+	beer := burptest.RandBeer()
+	if err := r.SaveBeer(context.Background(), beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	return beer
+}
+
+// assertErr fails t unless err is a repo.Err wrapping target.
+func assertErr(t *testing.T, call string, err, target error) {
+	t.Helper()
+
+	if !errors.Is(err, target) {
+		t.Errorf("%s returned error %v, want %v", call, err, target)
+		return
+	}
+
+	if !errors.As(err, &repo.Err{}) {
+		t.Errorf("%s returned error %v, want a repo.Err", call, err)
+	}
+}
+
+func testSaveNewBeer(t *testing.T, r burp.BeerRepo) {
+	beer := saveBeer(t, r)
+
+	if beer.Version != 1 {
+		t.Errorf("SaveBeer(ctx, beer) of a new beer set version %d, want 1", beer.Version)
+	}
+
+	got, err := r.SelectBeer(context.Background(), beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if diff := cmp.Diff(beer, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) returned unexpected beer, (-want/+got):\n%s", beer.ID, diff)
+	}
+}
+
+func testUpdateBeer(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+	createdAt := beer.CreatedAt
+
+	beer.Name = burptest.RandString(15)
+	beer.Price.Amount++
+	beer.Brewery, beer.Style, beer.Description = "", "Saison", burptest.RandString(60)
+	beer.ABV += 0.5
+	beer.IBU, beer.Volume = 0, 750
+	beer.Tags = []string{"organic", "winter"}
+	beer.CreatedAt = burptest.RandTime()
+	beer.UpdatedAt = burptest.RandTime()
+	if err := r.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) of an existing beer returned unexpected error %s", beer, err)
+	}
+
+	if beer.Version != 2 {
+		t.Errorf("SaveBeer(ctx, beer) of an existing beer set version %d, want 2", beer.Version)
+	}
+
+	if !beer.CreatedAt.Equal(createdAt) {
+		t.Errorf("SaveBeer(ctx, beer) of an existing beer set creation date %s, want stored %s", beer.CreatedAt, createdAt)
+	}
+
+	got, err := r.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if diff := cmp.Diff(beer, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) after update returned unexpected beer, (-want/+got):\n%s", beer.ID, diff)
+	}
+}
+
+func testSaveBeerVersionConflict(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+
+	tests := []struct {
+		description string
+		beer        burp.Beer
+	}{
+		{description: "creating an existing beer", beer: burp.Beer{ID: beer.ID, Name: beer.Name, Price: beer.Price}},
+		{description: "updating at a stale version", beer: burp.Beer{ID: beer.ID, Version: beer.Version + 1, Name: beer.Name, Price: beer.Price}},
+		{description: "updating a missing beer", beer: burp.Beer{ID: burptest.RandBeer().ID, Version: 1, Name: beer.Name, Price: beer.Price}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			err := r.SaveBeer(ctx, &test.beer)
+			assertErr(t, "SaveBeer(ctx, beer) "+test.description, err, burp.ErrVersionConflict)
+		})
+	}
+
+	if err := r.RemoveBeer(ctx, beer.ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	removed := *beer
+	removed.Version++
+	err := r.SaveBeer(ctx, &removed)
+	assertErr(t, "SaveBeer(ctx, beer) of a removed beer", err, burp.ErrVersionConflict)
+}
+
+func testSaveBeers(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	updated := saveBeer(t, r)
+	updated.Name = burptest.RandString(15)
+	beers := []*burp.Beer{burptest.RandBeer(), updated, burptest.RandBeer()}
+
+	if err := r.SaveBeers(ctx, beers); err != nil {
+		t.Fatalf("SaveBeers(ctx, beers) returned unexpected error %s", err)
+	}
+
+	for i, want := range []uint{1, 2, 1} {
+		if beers[i].Version != want {
+			t.Errorf("SaveBeers(ctx, beers) set version %d of beer %d, want %d", beers[i].Version, i, want)
+		}
+
+		got, err := r.SelectBeer(ctx, beers[i].ID)
+		if err != nil {
+			t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beers[i].ID, err)
+		}
+
+		if diff := cmp.Diff(beers[i], got); diff != "" {
+			t.Errorf("SelectBeer(ctx, %s) after SaveBeers returned unexpected beer, (-want/+got):\n%s", beers[i].ID, diff)
+		}
+	}
+}
+
+func testSaveBeersVersionConflict(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	stored := saveBeer(t, r)
+
+	updated := *stored
+	updated.Name = burptest.RandString(15)
+	created := burptest.RandBeer()
+	stale := &burp.Beer{ID: stored.ID, Name: stored.Name, Price: stored.Price}
+	beers := []*burp.Beer{created, &updated, stale}
+	given := []burp.Beer{*created, updated, *stale}
+
+	err := r.SaveBeers(ctx, beers)
+	assertErr(t, "SaveBeers(ctx, beers) with a conflicting beer", err, burp.ErrVersionConflict)
+
+	for i, beer := range beers {
+		if diff := cmp.Diff(&given[i], beer); diff != "" {
+			t.Errorf("SaveBeers(ctx, beers) which failed changed beer %d, (-want/+got):\n%s", i, diff)
+		}
+	}
+
+	_, err = r.SelectBeer(ctx, created.ID)
+	assertErr(t, "SelectBeer(ctx, id) of a beer created by failed SaveBeers", err, repo.ErrNotFound)
+
+	got, err := r.SelectBeer(ctx, stored.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", stored.ID, err)
+	}
+
+	if diff := cmp.Diff(stored, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) of a beer updated by failed SaveBeers returned unexpected beer, (-want/+got):\n%s", stored.ID, diff)
+	}
+}
+
+func testSelectMissingBeer(t *testing.T, r burp.BeerRepo) {
+	id := burptest.RandBeer().ID
+	_, err := r.SelectBeer(context.Background(), id)
+	assertErr(t, "SelectBeer(ctx, id) of a missing beer", err, repo.ErrNotFound)
+}
+
+func testRemoveBeer(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+
+	err := r.RemoveBeer(ctx, burptest.RandBeer().ID, burptest.RandTime())
+	assertErr(t, "RemoveBeer(ctx, id) of a missing beer", err, repo.ErrNotFound)
+
+	at := burptest.RandTime()
+	if err := r.RemoveBeer(ctx, beer.ID, at); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s, %s) returned unexpected error %s", beer.ID, at, err)
+	}
+
+	_, err = r.SelectBeer(ctx, beer.ID)
+	assertErr(t, "SelectBeer(ctx, id) of a removed beer", err, repo.ErrNotFound)
+
+	trash, err := r.ListBeers(ctx, burp.BeerQuery{Filter: burp.BeerFilter{Name: beer.Name, Deleted: true}, Sort: burp.SortByName, Limit: 1})
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, trash) returned unexpected error %s", err)
+	}
+
+	if len(trash) != 1 || trash[0].DeletedAt == nil || !trash[0].DeletedAt.Equal(at) {
+		t.Errorf("ListBeers(ctx, trash) returned %+v, want beer removed at %s", trash, at)
+	}
+
+	err = r.RemoveBeer(ctx, beer.ID, burptest.RandTime())
+	assertErr(t, "RemoveBeer(ctx, id) of a removed beer", err, repo.ErrNotFound)
+}
+
+func testRestoreBeer(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+
+	err := r.RestoreBeer(ctx, beer.ID)
+	assertErr(t, "RestoreBeer(ctx, id) of a beer not in trash", err, repo.ErrNotFound)
+
+	if err := r.RemoveBeer(ctx, beer.ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if err := r.RestoreBeer(ctx, beer.ID); err != nil {
+		t.Fatalf("RestoreBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	got, err := r.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) of a restored beer returned unexpected error %s", beer.ID, err)
+	}
+
+	// removing then restoring each bump version
+	beer.Version += 2
+	if diff := cmp.Diff(beer, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) of a restored beer returned unexpected beer, (-want/+got):\n%s", beer.ID, diff)
+	}
+}
+
+func testPurgeBeer(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+
+	err := r.PurgeBeer(ctx, beer.ID)
+	assertErr(t, "PurgeBeer(ctx, id) of a beer not in trash", err, repo.ErrNotFound)
+
+	if err := r.RemoveBeer(ctx, beer.ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if err := r.PurgeBeer(ctx, beer.ID); err != nil {
+		t.Fatalf("PurgeBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	err = r.RestoreBeer(ctx, beer.ID)
+	assertErr(t, "RestoreBeer(ctx, id) of a purged beer", err, repo.ErrNotFound)
+
+	err = r.PurgeBeer(ctx, beer.ID)
+	assertErr(t, "PurgeBeer(ctx, id) of a purged beer", err, repo.ErrNotFound)
+
+	// a purged beer can be created anew
+	beer.Version = 0
+	if err := r.SaveBeer(ctx, beer); err != nil {
+		t.Errorf("SaveBeer(ctx, %+v) of a purged beer returned unexpected error %s", beer, err)
+	}
+}
+
+func testRoundTripTimestamps(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	tests := []struct {
+		description string
+		time        time.Time
+	}{
+		{description: "with microseconds", time: time.Date(2022, 12, 31, 23, 59, 59, 999999000, time.UTC)},
+		{description: "before unix epoch", time: time.Date(1901, 1, 1, 0, 0, 0, 1000, time.UTC)},
+		{description: "far in the future", time: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			beer := burptest.RandBeer()
+			beer.CreatedAt = test.time
+			beer.UpdatedAt = test.time.Add(time.Microsecond)
+			if err := r.SaveBeer(ctx, beer); err != nil {
+				t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+			}
+
+			got, err := r.SelectBeer(ctx, beer.ID)
+			if err != nil {
+				t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+			}
+
+			if !got.CreatedAt.Equal(beer.CreatedAt) || !got.UpdatedAt.Equal(beer.UpdatedAt) {
+				t.Errorf("SelectBeer(ctx, %s) returned times %s and %s, want %s and %s",
+					beer.ID, got.CreatedAt, got.UpdatedAt, beer.CreatedAt, beer.UpdatedAt)
+			}
+
+			if got.CreatedAt.Location() != time.UTC || got.UpdatedAt.Location() != time.UTC {
+				t.Errorf("SelectBeer(ctx, %s) returned times in %s and %s, want UTC",
+					beer.ID, got.CreatedAt.Location(), got.UpdatedAt.Location())
+			}
+
+			if err := r.RemoveBeer(ctx, beer.ID, burptest.RandTime()); err != nil {
+				t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+			}
+
+			trash, err := r.ListBeers(ctx, burp.BeerQuery{
+				Filter: burp.BeerFilter{Name: beer.Name, Deleted: true},
+				Sort:   burp.SortByName,
+				Limit:  1,
+			})
+			if err != nil {
+				t.Fatalf("ListBeers(ctx, trash) returned unexpected error %s", err)
+			}
+
+			if len(trash) != 1 || trash[0].DeletedAt == nil || trash[0].DeletedAt.Location() != time.UTC {
+				t.Errorf("ListBeers(ctx, trash) returned %+v, want removed beer with a UTC deletion date", trash)
+			}
+		})
+	}
+}
+
+func testRoundTripPrices(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	prices := []burp.Price{
+		{Currency: burp.EUR, Amount: 1},
+		{Currency: burp.USD, Amount: 1999},
+		{Currency: "JPY", Amount: 560},
+		{Currency: burp.EUR, Amount: math.MaxInt32},
+	}
+
+	for _, price := range prices {
+		t.Run(fmt.Sprintf("%d %s", price.Amount, price.Currency), func(t *testing.T) {
+			beer := burptest.RandBeer()
+			beer.Price = price
+			if err := r.SaveBeer(ctx, beer); err != nil {
+				t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+			}
+
+			got, err := r.SelectBeer(ctx, beer.ID)
+			if err != nil {
+				t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+			}
+
+			if got.Price != price {
+				t.Errorf("SelectBeer(ctx, %s) returned price %+v, want %+v", beer.ID, got.Price, price)
+			}
+		})
+	}
+}
+
+func testListBeers(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+
+	// beers share a unique prefix, to be listed apart from others in r
+	prefix := burptest.RandString(10)
+	beers := make([]*burp.Beer, 4)
+	for i := range beers {
+		beer := burptest.RandBeer()
+		beer.Name = fmt.Sprintf("%s %d", prefix, i)
+		if err := r.SaveBeer(ctx, beer); err != nil {
+			t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+		}
+		beers[i] = beer
+	}
+
+	if err := r.RemoveBeer(ctx, beers[3].ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", beers[3].ID, err)
+	}
+
+	q := burp.BeerQuery{Filter: burp.BeerFilter{Name: prefix}, Sort: burp.SortByName, Limit: 2}
+
+	first, err := r.ListBeers(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, %+v) returned unexpected error %s", q, err)
+	}
+
+	if diff := cmp.Diff(beers[:2], first); diff != "" {
+		t.Errorf("ListBeers(ctx, %+v) returned unexpected beers, (-want/+got):\n%s", q, diff)
+	}
+
+	q.After = burp.NewCursor(beers[1], q.Sort, q.Desc)
+	next, err := r.ListBeers(ctx, q)
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, %+v) returned unexpected error %s", q, err)
+	}
+
+	if diff := cmp.Diff(beers[2:3], next); diff != "" {
+		t.Errorf("ListBeers(ctx, %+v) after cursor returned unexpected beers, (-want/+got):\n%s", q, diff)
+	}
+
+	trash, err := r.ListBeers(ctx, burp.BeerQuery{Filter: burp.BeerFilter{Name: prefix, Deleted: true}, Sort: burp.SortByName, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListBeers(ctx, trash) returned unexpected error %s", err)
+	}
+
+	if len(trash) != 1 || trash[0].ID != beers[3].ID {
+		t.Errorf("ListBeers(ctx, trash) returned %+v, want removed beer only", trash)
+	}
+}
+
+func testListBeersByTags(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+
+	// a unique tag lists these beers apart from others in r
+	tag := strings.ToLower(burptest.RandString(10))
+	tags := [][]string{{tag}, {"sour", tag}, {"sour"}, nil}
+	beers := make([]*burp.Beer, len(tags))
+	for i := range beers {
+		beer := burptest.RandBeer()
+		beer.Name = fmt.Sprintf("%s %d", tag, i)
+		beer.Tags = burp.NormalizeTags(tags[i])
+		if err := r.SaveBeer(ctx, beer); err != nil {
+			t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+		}
+		beers[i] = beer
+	}
+
+	tests := []struct {
+		tags []string
+		want []*burp.Beer
+	}{
+		{tags: []string{tag}, want: beers[:2]},
+		{tags: burp.NormalizeTags([]string{"sour", tag}), want: beers[1:2]},
+		{tags: nil, want: beers},
+	}
+
+	for _, test := range tests {
+		q := burp.BeerQuery{Filter: burp.BeerFilter{Name: tag, Tags: test.tags}, Sort: burp.SortByName, Limit: 10}
+		got, err := r.ListBeers(ctx, q)
+		if err != nil {
+			t.Fatalf("ListBeers(ctx, %+v) returned unexpected error %s", q, err)
+		}
+
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("ListBeers(ctx, %+v) returned unexpected beers, (-want/+got):\n%s", q, diff)
+		}
+	}
+}
+
+func testConcurrentSaves(t *testing.T, r burp.BeerRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+
+	const writers = 10
+	var (
+		wg      sync.WaitGroup
+		updates = make(chan error, writers)
+		creates = make(chan error, writers)
+	)
+
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			update := *beer
+			update.Name = fmt.Sprintf("concurrent %d", i)
+			updates <- r.SaveBeer(ctx, &update)
+
+			creates <- r.SaveBeer(ctx, burptest.RandBeer())
+		}(i)
+	}
+	wg.Wait()
+	close(updates)
+	close(creates)
+
+	var saved int
+	for err := range updates {
+		switch {
+		case err == nil:
+			saved++
+		case !errors.Is(err, burp.ErrVersionConflict):
+			t.Errorf("SaveBeer(ctx, beer) concurrently returned error %s, want none or %v", err, burp.ErrVersionConflict)
+		}
+	}
+
+	if saved != 1 {
+		t.Errorf("SaveBeer(ctx, beer) concurrently at the same version saved %d times, want 1", saved)
+	}
+
+	for err := range creates {
+		if err != nil {
+			t.Errorf("SaveBeer(ctx, beer) of new beers concurrently returned error %s, want none", err)
+		}
+	}
+
+	got, err := r.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if got.Version != beer.Version+1 {
+		t.Errorf("SelectBeer(ctx, %s) after concurrent saves returned version %d, want %d", beer.ID, got.Version, beer.Version+1)
+	}
+}
+
+// TxRepo is a repo running transactions.
+type TxRepo interface {
+	burp.BeerRepo
+	burp.TxRunner
+}
+
+// TestTxRunner checks transactions of r commit and roll back
+// as every burp.TxRunner must.
+func TestTxRunner(t *testing.T, r TxRepo) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r TxRepo)
+	}{
+		{"Commit", testTxCommit},
+		{"Rollback", testTxRollback},
+		{"NestedTx", testNestedTx},
+		{"SaveBeersInTx", testSaveBeersInTx},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, r)
+		})
+	}
+}
+
+func testTxCommit(t *testing.T, r TxRepo) {
+	ctx := context.Background()
+	beer := burptest.RandBeer()
+
+	err := r.RunInTx(ctx, func(ctx context.Context) error {
+		if err := r.SaveBeer(ctx, beer); err != nil {
+			return err
+		}
+
+		// changes are visible within the transaction
+		_, err := r.SelectBeer(ctx, beer.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("RunInTx(ctx, fn) returned unexpected error %s", err)
+	}
+
+	if _, err := r.SelectBeer(ctx, beer.ID); err != nil {
+		t.Errorf("SelectBeer(ctx, %s) of a beer saved in a committed transaction returned error %s", beer.ID, err)
+	}
+}
+
+func testTxRollback(t *testing.T, r TxRepo) {
+	ctx := context.Background()
+	stored := saveBeer(t, r)
+	created := burptest.RandBeer()
+	fnErr := errors.New("rollback")
+
+	err := r.RunInTx(ctx, func(ctx context.Context) error {
+		if err := r.SaveBeer(ctx, created); err != nil {
+			return err
+		}
+		if err := r.RemoveBeer(ctx, stored.ID, burptest.RandTime()); err != nil {
+			return err
+		}
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("RunInTx(ctx, fn) returned error %v, want error of fn %v", err, fnErr)
+	}
+
+	_, err = r.SelectBeer(ctx, created.ID)
+	assertErr(t, "SelectBeer(ctx, id) of a beer saved in a rolled back transaction", err, repo.ErrNotFound)
+
+	got, err := r.SelectBeer(ctx, stored.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) of a beer removed in a rolled back transaction returned error %s", stored.ID, err)
+	}
+
+	if diff := cmp.Diff(stored, got); diff != "" {
+		t.Errorf("SelectBeer(ctx, %s) of a beer removed in a rolled back transaction returned unexpected beer, (-want/+got):\n%s", stored.ID, diff)
+	}
+}
+
+func testNestedTx(t *testing.T, r TxRepo) {
+	ctx := context.Background()
+	beer := burptest.RandBeer()
+	fnErr := errors.New("rollback")
+
+	err := r.RunInTx(ctx, func(ctx context.Context) error {
+		err := r.RunInTx(ctx, func(ctx context.Context) error {
+			return r.SaveBeer(ctx, beer)
+		})
+		if err != nil {
+			return err
+		}
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("RunInTx(ctx, fn) returned error %v, want error of fn %v", err, fnErr)
+	}
+
+	_, err = r.SelectBeer(ctx, beer.ID)
+	assertErr(t, "SelectBeer(ctx, id) of a beer saved in a nested transaction rolled back", err, repo.ErrNotFound)
+}
+
+func testSaveBeersInTx(t *testing.T, r TxRepo) {
+	ctx := context.Background()
+	saved := burptest.RandBeer()
+	stored := saveBeer(t, r)
+	created := burptest.RandBeer()
+
+	err := r.RunInTx(ctx, func(ctx context.Context) error {
+		if err := r.SaveBeer(ctx, saved); err != nil {
+			return err
+		}
+
+		stale := &burp.Beer{ID: stored.ID, Name: stored.Name, Price: stored.Price}
+		err := r.SaveBeers(ctx, []*burp.Beer{created, stale})
+		assertErr(t, "SaveBeers(ctx, beers) with a conflicting beer", err, burp.ErrVersionConflict)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunInTx(ctx, fn) returned unexpected error %s", err)
+	}
+
+	if _, err := r.SelectBeer(ctx, saved.ID); err != nil {
+		t.Errorf("SelectBeer(ctx, %s) of a beer saved before failed SaveBeers returned error %s", saved.ID, err)
+	}
+
+	_, err = r.SelectBeer(ctx, created.ID)
+	assertErr(t, "SelectBeer(ctx, id) of a beer created by failed SaveBeers", err, repo.ErrNotFound)
+}
@@ -0,0 +1,14 @@
+package repotest_test
+
+import (
+	"burp/repo/repotest"
+	"testing"
+)
+
+func TestFakeRepo(t *testing.T) {
+	repotest.TestBeerRepo(t, repotest.FakeRepo)
+	repotest.TestTxRunner(t, repotest.FakeRepo)
+	repotest.TestBreweryRepo(t, repotest.FakeRepo)
+	repotest.TestStockRepo(t, repotest.FakeRepo)
+	repotest.TestOrderRepo(t, repotest.FakeRepo)
+}
@@ -0,0 +1,191 @@
+package repotest
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo"
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// TestOrderRepo checks r behaves as every burp.OrderRepo must.
+// It only relies on orders it saves, except when listing them,
+// which it does with times later than those of other orders.
+func TestOrderRepo(t *testing.T, r burp.OrderRepo) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r burp.OrderRepo)
+	}{
+		{"SaveNewOrder", testSaveNewOrder},
+		{"UpdateOrderStatus", testUpdateOrderStatus},
+		{"SaveOrderVersionConflict", testSaveOrderVersionConflict},
+		{"SelectMissingOrder", testSelectMissingOrder},
+		{"ListOrders", testListOrders},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, r)
+		})
+	}
+}
+
+// randOrder returns a new pending order of random beers.
+func randOrder() *burp.Order {
+	createdAt := burptest.RandTime()
+	order := &burp.Order{
+		ID:        burp.ID{UUID: uuid.New()},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Customer:  burptest.RandString(12),
+		Status:    burp.OrderPending,
+	}
+
+	for i := 0; i < 3; i++ {
+		beer := burptest.RandBeer()
+		order.Lines = append(order.Lines, burp.OrderLine{
+			BeerID:   beer.ID,
+			Name:     beer.Name,
+			Quantity: uint(i + 1),
+			Price:    beer.Price,
+		})
+	}
+
+	return order
+}
+
+// saveOrder saves order in r.
+func saveOrder(t *testing.T, r burp.OrderSaver, order *burp.Order) *burp.Order {
+	t.Helper()
+
+	if err := r.SaveOrder(context.Background(), order); err != nil {
+		t.Fatalf("SaveOrder(ctx, %+v) returned unexpected error %s", order, err)
+	}
+
+	return order
+}
+
+func testSaveNewOrder(t *testing.T, r burp.OrderRepo) {
+	order := saveOrder(t, r, randOrder())
+
+	if order.Version != 1 {
+		t.Errorf("SaveOrder(ctx, order) of a new order set version %d, want 1", order.Version)
+	}
+
+	got, err := r.SelectOrder(context.Background(), order.ID)
+	if err != nil {
+		t.Fatalf("SelectOrder(ctx, %s) returned unexpected error %s", order.ID, err)
+	}
+
+	if diff := cmp.Diff(order, got); diff != "" {
+		t.Errorf("SelectOrder(ctx, %s) returned unexpected order, (-want/+got):\n%s", order.ID, diff)
+	}
+}
+
+func testUpdateOrderStatus(t *testing.T, r burp.OrderRepo) {
+	ctx := context.Background()
+	order := saveOrder(t, r, randOrder())
+	want := *order
+
+	// lines and customer never change once saved
+	order.Status = burp.OrderPaid
+	order.UpdatedAt = burptest.RandTime()
+	order.CreatedAt = burptest.RandTime()
+	order.Customer = burptest.RandString(12)
+	order.Lines = order.Lines[:1]
+	saveOrder(t, r, order)
+
+	want.Status, want.UpdatedAt, want.Version = burp.OrderPaid, order.UpdatedAt, 2
+	if order.Version != 2 || !order.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("SaveOrder(ctx, order) of a saved order set version %d and creation date %s, want 2 and %s", order.Version, order.CreatedAt, want.CreatedAt)
+	}
+
+	got, err := r.SelectOrder(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("SelectOrder(ctx, %s) returned unexpected error %s", order.ID, err)
+	}
+
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("SelectOrder(ctx, %s) of an updated order returned unexpected order, (-want/+got):\n%s", order.ID, diff)
+	}
+}
+
+func testSaveOrderVersionConflict(t *testing.T, r burp.OrderRepo) {
+	ctx := context.Background()
+	order := saveOrder(t, r, randOrder())
+
+	stale := *order
+	order.Status = burp.OrderCancelled
+	saveOrder(t, r, order)
+
+	stale.Status = burp.OrderPaid
+	err := r.SaveOrder(ctx, &stale)
+	assertErr(t, "SaveOrder(ctx, order) at a stale version", err, burp.ErrVersionConflict)
+
+	if stale.Version != 1 {
+		t.Errorf("SaveOrder(ctx, order) failing set version %d, want it left at 1", stale.Version)
+	}
+
+	err = r.SaveOrder(ctx, &burp.Order{ID: burp.ID{UUID: uuid.New()}, Version: 1, Status: burp.OrderPaid})
+	assertErr(t, "SaveOrder(ctx, order) of a missing order at version 1", err, burp.ErrVersionConflict)
+}
+
+func testSelectMissingOrder(t *testing.T, r burp.OrderRepo) {
+	_, err := r.SelectOrder(context.Background(), burp.ID{UUID: uuid.New()})
+	assertErr(t, "SelectOrder(ctx, id) of a missing order", err, repo.ErrNotFound)
+}
+
+func testListOrders(t *testing.T, r burp.OrderRepo) {
+	ctx := context.Background()
+
+	// orders are placed after those of other tests, to be listed first
+	latest := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Microsecond)
+	var orders []*burp.Order
+	for i := 0; i < 3; i++ {
+		order := randOrder()
+		order.CreatedAt = latest.Add(time.Duration(i) * time.Hour)
+		order.UpdatedAt = order.CreatedAt
+		orders = append(orders, saveOrder(t, r, order))
+	}
+
+	orders[1].Status = burp.OrderPaid
+	saveOrder(t, r, orders[1])
+
+	tests := []struct {
+		description string
+		query       burp.OrderQuery
+		want        []*burp.Order
+	}{
+		{
+			description: "newest first",
+			query:       burp.OrderQuery{Limit: 2},
+			want:        []*burp.Order{orders[2], orders[1]},
+		},
+		{
+			description: "pending",
+			query:       burp.OrderQuery{Status: burp.OrderPending, Limit: 2},
+			want:        []*burp.Order{orders[2], orders[0]},
+		},
+		{
+			description: "paid",
+			query:       burp.OrderQuery{Status: burp.OrderPaid, Limit: 1},
+			want:        []*burp.Order{orders[1]},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := r.ListOrders(ctx, test.query)
+			if err != nil {
+				t.Fatalf("ListOrders(ctx, %+v) returned unexpected error %s", test.query, err)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ListOrders(ctx, %+v) returned unexpected orders, (-want/+got):\n%s", test.query, diff)
+			}
+		})
+	}
+}
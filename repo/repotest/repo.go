@@ -4,12 +4,18 @@ import (
 	"burp"
 	"burp/repo"
 	"context"
+	"time"
 )
 
 type Repo struct {
 	burp.BeerSaver
+	burp.BeersSaver
 	burp.BeerSelector
 	burp.BeerRemover
+	burp.BeerRestorer
+	burp.BeerPurger
+	burp.BeerLister
+	burp.BeerSearcher
 }
 
 func (r Repo) SaveBeer(ctx context.Context, beer *burp.Beer) error {
@@ -19,6 +25,13 @@ func (r Repo) SaveBeer(ctx context.Context, beer *burp.Beer) error {
 	return repo.Errorf("SaveBeer(ctx, %+v) is unimplemented", beer)
 }
 
+func (r Repo) SaveBeers(ctx context.Context, beers []*burp.Beer) error {
+	if r.BeersSaver != nil {
+		return r.BeersSaver.SaveBeers(ctx, beers)
+	}
+	return repo.Errorf("SaveBeers(ctx, %+v) is unimplemented", beers)
+}
+
 func (r Repo) SelectBeer(ctx context.Context, id burp.ID) (*burp.Beer, error) {
 	if r.BeerSelector != nil {
 		return r.BeerSelector.SelectBeer(ctx, id)
@@ -26,9 +39,37 @@ func (r Repo) SelectBeer(ctx context.Context, id burp.ID) (*burp.Beer, error) {
 	return nil, repo.Errorf("SelectBeer(ctx, %+v) is unimplemented", id)
 }
 
-func (r Repo) RemoveBeer(ctx context.Context, id burp.ID) error {
+func (r Repo) RemoveBeer(ctx context.Context, id burp.ID, at time.Time) error {
 	if r.BeerRemover != nil {
-		return r.BeerRemover.RemoveBeer(ctx, id)
+		return r.BeerRemover.RemoveBeer(ctx, id, at)
 	}
 	return repo.Errorf("RemoveBeer(ctx, %+v) is unimplemented", id)
 }
+
+func (r Repo) RestoreBeer(ctx context.Context, id burp.ID) error {
+	if r.BeerRestorer != nil {
+		return r.BeerRestorer.RestoreBeer(ctx, id)
+	}
+	return repo.Errorf("RestoreBeer(ctx, %+v) is unimplemented", id)
+}
+
+func (r Repo) PurgeBeer(ctx context.Context, id burp.ID) error {
+	if r.BeerPurger != nil {
+		return r.BeerPurger.PurgeBeer(ctx, id)
+	}
+	return repo.Errorf("PurgeBeer(ctx, %+v) is unimplemented", id)
+}
+
+func (r Repo) ListBeers(ctx context.Context, q burp.BeerQuery) ([]*burp.Beer, error) {
+	if r.BeerLister != nil {
+		return r.BeerLister.ListBeers(ctx, q)
+	}
+	return nil, repo.Errorf("ListBeers(ctx, %+v) is unimplemented", q)
+}
+
+func (r Repo) SearchBeers(ctx context.Context, s burp.BeerSearch) ([]*burp.BeerMatch, error) {
+	if r.BeerSearcher != nil {
+		return r.BeerSearcher.SearchBeers(ctx, s)
+	}
+	return nil, repo.Errorf("SearchBeers(ctx, %+v) is unimplemented", s)
+}
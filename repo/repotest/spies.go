@@ -2,16 +2,38 @@ package repotest
 
 import (
 	"burp"
+	"burp/repo"
 	"context"
+	"time"
 )
 
 type (
-	BeerSaverSpy    struct{ BeerSaved *burp.Beer }
-	BeerRemoverSpy  struct{ RemovedID burp.ID }
+	BeerSaverSpy   struct{ BeerSaved *burp.Beer }
+	BeersSaverSpy  struct{ BeersSaved []*burp.Beer }
+	BeerRemoverSpy struct {
+		RemovedID burp.ID
+		RemovedAt time.Time
+	}
+	BeerRestorerSpy struct{ RestoredID burp.ID }
+	BeerPurgerSpy   struct{ PurgedID burp.ID }
 	BeerSelectorSpy struct {
 		SelectedID burp.ID
 		Beer       *burp.Beer
 	}
+	BeerListerSpy struct {
+		Query burp.BeerQuery
+		Beers []*burp.Beer
+	}
+	BeerEventRepoSpy struct {
+		Events []*burp.BeerEvent
+	}
+	PriceRepoSpy struct {
+		Changes []*burp.PriceChange
+	}
+	BeerSearcherSpy struct {
+		Search  burp.BeerSearch
+		Matches []*burp.BeerMatch
+	}
 )
 
 func (s *BeerSaverSpy) SaveBeer(ctx context.Context, b *burp.Beer) error {
@@ -19,8 +41,24 @@ func (s *BeerSaverSpy) SaveBeer(ctx context.Context, b *burp.Beer) error {
 	return nil
 }
 
-func (b *BeerRemoverSpy) RemoveBeer(ctx context.Context, id burp.ID) error {
+func (s *BeersSaverSpy) SaveBeers(ctx context.Context, beers []*burp.Beer) error {
+	s.BeersSaved = beers
+	return nil
+}
+
+func (b *BeerRemoverSpy) RemoveBeer(ctx context.Context, id burp.ID, at time.Time) error {
 	b.RemovedID = id
+	b.RemovedAt = at
+	return nil
+}
+
+func (b *BeerRestorerSpy) RestoreBeer(ctx context.Context, id burp.ID) error {
+	b.RestoredID = id
+	return nil
+}
+
+func (b *BeerPurgerSpy) PurgeBeer(ctx context.Context, id burp.ID) error {
+	b.PurgedID = id
 	return nil
 }
 
@@ -28,3 +66,59 @@ func (b *BeerSelectorSpy) SelectBeer(ctx context.Context, id burp.ID) (*burp.Bee
 	b.SelectedID = id
 	return b.Beer, nil
 }
+
+func (b *BeerListerSpy) ListBeers(ctx context.Context, q burp.BeerQuery) ([]*burp.Beer, error) {
+	b.Query = q
+	if len(b.Beers) > q.Limit {
+		return b.Beers[:q.Limit], nil
+	}
+	return b.Beers, nil
+}
+
+func (b *BeerSearcherSpy) SearchBeers(ctx context.Context, s burp.BeerSearch) ([]*burp.BeerMatch, error) {
+	b.Search = s
+	return b.Matches, nil
+}
+
+func (b *BeerEventRepoSpy) SaveBeerEvent(ctx context.Context, e *burp.BeerEvent) error {
+	b.Events = append(b.Events, e)
+	return nil
+}
+
+func (b *BeerEventRepoSpy) SelectBeerHistory(ctx context.Context, id burp.ID) ([]*burp.BeerEvent, error) {
+	var events []*burp.BeerEvent
+	for _, e := range b.Events {
+		if e.BeerID == id {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (p *PriceRepoSpy) SavePriceChange(ctx context.Context, c *burp.PriceChange) error {
+	p.Changes = append(p.Changes, c)
+	return nil
+}
+
+func (p *PriceRepoSpy) SelectPriceChanges(ctx context.Context, id burp.ID) ([]*burp.PriceChange, error) {
+	var changes []*burp.PriceChange
+	for _, c := range p.Changes {
+		if c.BeerID == id {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+func (p *PriceRepoSpy) SelectPriceAt(ctx context.Context, id burp.ID, at time.Time) (*burp.PriceChange, error) {
+	var last *burp.PriceChange
+	for _, c := range p.Changes {
+		if c.BeerID == id && !c.From.After(at) {
+			last = c
+		}
+	}
+	if last == nil {
+		return nil, repo.ErrNotFound
+	}
+	return last, nil
+}
@@ -0,0 +1,285 @@
+package repotest
+
+import (
+	"burp"
+	"burp/burptest"
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"sync"
+	"testing"
+)
+
+// StockRepo is a repo tracking stock along with beers.
+type StockRepo interface {
+	burp.BeerRepo
+	burp.StockRepo
+}
+
+// TestStockRepo checks r behaves as every burp.StockRepo must.
+// As TestBeerRepo, it only relies on stock of beers it saves.
+func TestStockRepo(t *testing.T, r StockRepo) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r StockRepo)
+	}{
+		{"MoveStock", testMoveStock},
+		{"MoveStockInsufficient", testMoveStockInsufficient},
+		{"MoveStockOverflow", testMoveStockOverflow},
+		{"SetStockThreshold", testSetStockThreshold},
+		{"ListStock", testListStock},
+		{"PurgeBeerStock", testPurgeBeerStock},
+		{"ConcurrentMoves", testConcurrentMoves},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, r)
+		})
+	}
+}
+
+// moveStock moves stock of beer id at location by delta in r.
+func moveStock(t *testing.T, r burp.StockMover, id burp.ID, location string, delta int) *burp.Stock {
+	t.Helper()
+
+	m := &burp.StockMovement{BeerID: id, Location: location, Delta: delta, Time: burptest.RandTime()}
+	stock, err := r.MoveStock(context.Background(), m)
+	if err != nil {
+		t.Fatalf("MoveStock(ctx, %+v) returned unexpected error %s", m, err)
+	}
+
+	return stock
+}
+
+// listStock lists stock matching q in r.
+func listStock(t *testing.T, r burp.StockLister, q burp.StockQuery) []*burp.Stock {
+	t.Helper()
+
+	stock, err := r.ListStock(context.Background(), q)
+	if err != nil {
+		t.Fatalf("ListStock(ctx, %+v) returned unexpected error %s", q, err)
+	}
+
+	return stock
+}
+
+func testMoveStock(t *testing.T, r StockRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+
+	moveStock(t, r, beer.ID, "cellar", 5)
+	m := &burp.StockMovement{BeerID: beer.ID, Location: "cellar", Delta: -3, Time: burptest.RandTime()}
+	got, err := r.MoveStock(ctx, m)
+	if err != nil {
+		t.Fatalf("MoveStock(ctx, %+v) returned unexpected error %s", m, err)
+	}
+
+	want := &burp.Stock{BeerID: beer.ID, Location: "cellar", Quantity: 2, UpdatedAt: m.Time}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("MoveStock(ctx, %+v) returned unexpected stock, (-want/+got):\n%s", m, diff)
+	}
+
+	q := burp.StockQuery{BeerID: beer.ID}
+	if diff := cmp.Diff([]*burp.Stock{want}, listStock(t, r, q)); diff != "" {
+		t.Errorf("ListStock(ctx, %+v) returned unexpected stock, (-want/+got):\n%s", q, diff)
+	}
+}
+
+func testMoveStockInsufficient(t *testing.T, r StockRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+	want := moveStock(t, r, beer.ID, "bar", 2)
+
+	m := &burp.StockMovement{BeerID: beer.ID, Location: "bar", Delta: -3, Time: burptest.RandTime()}
+	_, err := r.MoveStock(ctx, m)
+	assertErr(t, "MoveStock(ctx, m) removing more than stored", err, burp.ErrStockInsufficient)
+
+	m = &burp.StockMovement{BeerID: beer.ID, Location: "cellar", Delta: -1, Time: burptest.RandTime()}
+	_, err = r.MoveStock(ctx, m)
+	assertErr(t, "MoveStock(ctx, m) removing from new stock", err, burp.ErrStockInsufficient)
+
+	q := burp.StockQuery{BeerID: beer.ID}
+	if diff := cmp.Diff([]*burp.Stock{want}, listStock(t, r, q)); diff != "" {
+		t.Errorf("ListStock(ctx, %+v) after insufficient stock returned unexpected stock, (-want/+got):\n%s", q, diff)
+	}
+}
+
+func testMoveStockOverflow(t *testing.T, r StockRepo) {
+	beer := saveBeer(t, r)
+	moveStock(t, r, beer.ID, "cellar", burp.MaxStockQuantity)
+
+	m := &burp.StockMovement{BeerID: beer.ID, Location: "cellar", Delta: 1, Time: burptest.RandTime()}
+	_, err := r.MoveStock(context.Background(), m)
+	assertErr(t, "MoveStock(ctx, m) adding over the maximum", err, burp.ErrStockOverflow)
+}
+
+func testSetStockThreshold(t *testing.T, r StockRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+
+	th := &burp.StockThreshold{BeerID: beer.ID, Location: "bar", Threshold: 3, Time: burptest.RandTime()}
+	got, err := r.SetStockThreshold(ctx, th)
+	if err != nil {
+		t.Fatalf("SetStockThreshold(ctx, %+v) returned unexpected error %s", th, err)
+	}
+
+	want := &burp.Stock{BeerID: beer.ID, Location: "bar", Threshold: 3, UpdatedAt: th.Time}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SetStockThreshold(ctx, %+v) of new stock returned unexpected stock, (-want/+got):\n%s", th, diff)
+	}
+
+	moved := moveStock(t, r, beer.ID, "bar", 4)
+	if moved.Threshold != 3 {
+		t.Errorf("MoveStock(ctx, m) returned threshold %d, want it kept at 3", moved.Threshold)
+	}
+
+	th.Threshold, th.Time = 10, burptest.RandTime()
+	if got, err = r.SetStockThreshold(ctx, th); err != nil {
+		t.Fatalf("SetStockThreshold(ctx, %+v) returned unexpected error %s", th, err)
+	}
+
+	want = &burp.Stock{BeerID: beer.ID, Location: "bar", Quantity: 4, Threshold: 10, UpdatedAt: th.Time}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SetStockThreshold(ctx, %+v) of stored stock returned unexpected stock, (-want/+got):\n%s", th, diff)
+	}
+}
+
+func testListStock(t *testing.T, r StockRepo) {
+	ctx := context.Background()
+	beer, other := saveBeer(t, r), saveBeer(t, r)
+
+	cellar := moveStock(t, r, beer.ID, "cellar", 12)
+	bar := moveStock(t, r, beer.ID, "bar", 2)
+	otherBar := moveStock(t, r, other.ID, "bar", 1)
+
+	for _, s := range []*burp.Stock{bar, otherBar} {
+		th := &burp.StockThreshold{BeerID: s.BeerID, Location: s.Location, Threshold: 5, Time: s.UpdatedAt}
+		if _, err := r.SetStockThreshold(ctx, th); err != nil {
+			t.Fatalf("SetStockThreshold(ctx, %+v) returned unexpected error %s", th, err)
+		}
+		s.Threshold = 5
+	}
+
+	tests := []struct {
+		description string
+		query       burp.StockQuery
+		want        []*burp.Stock
+	}{
+		{
+			description: "of a beer, by location",
+			query:       burp.StockQuery{BeerID: beer.ID},
+			want:        []*burp.Stock{bar, cellar},
+		},
+		{
+			description: "of a beer at a location",
+			query:       burp.StockQuery{BeerID: beer.ID, Location: "cellar"},
+			want:        []*burp.Stock{cellar},
+		},
+		{
+			description: "of a beer, low only",
+			query:       burp.StockQuery{BeerID: beer.ID, Low: true},
+			want:        []*burp.Stock{bar},
+		},
+		{
+			description: "of a beer at an empty location",
+			query:       burp.StockQuery{BeerID: beer.ID, Location: "attic"},
+			want:        []*burp.Stock{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, listStock(t, r, test.query)); diff != "" {
+				t.Errorf("ListStock(ctx, %+v) returned unexpected stock, (-want/+got):\n%s", test.query, diff)
+			}
+		})
+	}
+
+	// other beers may be stored, so check those saved here only
+	q := burp.StockQuery{Location: "bar", Low: true}
+	var got []*burp.Stock
+	for _, s := range listStock(t, r, q) {
+		if s.BeerID == beer.ID || s.BeerID == other.ID {
+			got = append(got, s)
+		}
+		if !s.Low() || s.Location != "bar" {
+			t.Errorf("ListStock(ctx, %+v) returned stock %+v not matching query", q, s)
+		}
+	}
+
+	want := []*burp.Stock{bar, otherBar}
+	if beer.ID.String() > other.ID.String() {
+		want = []*burp.Stock{otherBar, bar}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListStock(ctx, %+v) returned unexpected stock, (-want/+got):\n%s", q, diff)
+	}
+}
+
+func testPurgeBeerStock(t *testing.T, r StockRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+	moveStock(t, r, beer.ID, "cellar", 3)
+
+	if err := r.RemoveBeer(ctx, beer.ID, burptest.RandTime()); err != nil {
+		t.Fatalf("RemoveBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	q := burp.StockQuery{BeerID: beer.ID}
+	if got := listStock(t, r, q); len(got) != 1 {
+		t.Errorf("ListStock(ctx, %+v) of a beer in trash returned %d stock, want it kept", q, len(got))
+	}
+
+	if err := r.PurgeBeer(ctx, beer.ID); err != nil {
+		t.Fatalf("PurgeBeer(ctx, %s) returned unexpected error %s", beer.ID, err)
+	}
+
+	if got := listStock(t, r, q); len(got) != 0 {
+		t.Errorf("ListStock(ctx, %+v) of a purged beer returned %d stock, want none", q, len(got))
+	}
+}
+
+func testConcurrentMoves(t *testing.T, r StockRepo) {
+	ctx := context.Background()
+	beer := saveBeer(t, r)
+	moveStock(t, r, beer.ID, "bar", 5)
+
+	const workers = 10
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, workers)
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m := &burp.StockMovement{BeerID: beer.ID, Location: "bar", Delta: -1, Time: burptest.RandTime()}
+			_, err := r.MoveStock(ctx, m)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var moved int
+	for err := range errs {
+		switch {
+		case err == nil:
+			moved++
+		case !errors.Is(err, burp.ErrStockInsufficient):
+			t.Errorf("MoveStock(ctx, m) concurrently returned error %s, want none or %v", err, burp.ErrStockInsufficient)
+		}
+	}
+
+	if moved != 5 {
+		t.Errorf("MoveStock(ctx, m) concurrently removed %d units of 5 stored, want 5", moved)
+	}
+
+	q := burp.StockQuery{BeerID: beer.ID}
+	if got := listStock(t, r, q); len(got) != 1 || got[0].Quantity != 0 {
+		t.Errorf("ListStock(ctx, %+v) after concurrent moves returned %+v, want 0 unit left", q, got)
+	}
+}
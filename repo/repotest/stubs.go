@@ -5,24 +5,55 @@ import (
 	"burp/burptest"
 	"burp/repo"
 	"context"
+	"time"
 )
 
 type (
 	beerSaverStub    struct{ Err error }
 	beerRemoverStub  struct{ Err error }
+	beerRestorerStub struct{ Err error }
+	beerPurgerStub   struct{ Err error }
 	beerSelectorStub struct {
 		Beer *burp.Beer
 		Err  error
 	}
+	beerListerStub    struct{ Err error }
+	beerSearcherStub  struct{ Err error }
+	beerEventRepoStub struct{ Err error }
 )
 
 var BeerSaverErrStub = beerSaverStub{Err: repo.Error(burptest.RandString(20))}
 var BeerRemoverErrStub = beerRemoverStub{Err: repo.Error(burptest.RandString(20))}
+var BeerRestorerNotFoundStub = beerRestorerStub{Err: repo.ErrNotFound}
+var BeerPurgerNotFoundStub = beerPurgerStub{Err: repo.ErrNotFound}
 var BeerSelectorNotFoundStub = beerSelectorStub{Err: repo.ErrNotFound}
 var BeerSelectorStub = beerSelectorStub{Beer: burptest.RandBeer()}
+var BeerListerErrStub = beerListerStub{Err: repo.Error(burptest.RandString(20))}
+var BeerSearcherErrStub = beerSearcherStub{Err: repo.Error(burptest.RandString(20))}
+var BeerEventRepoErrStub = beerEventRepoStub{Err: repo.Error(burptest.RandString(20))}
 
-func (s beerSaverStub) SaveBeer(ctx context.Context, b *burp.Beer) error   { return s.Err }
-func (s beerRemoverStub) RemoveBeer(ctx context.Context, id burp.ID) error { return s.Err }
+func (s beerSaverStub) SaveBeer(ctx context.Context, b *burp.Beer) error { return s.Err }
+func (s beerRemoverStub) RemoveBeer(ctx context.Context, id burp.ID, at time.Time) error {
+	return s.Err
+}
+func (s beerRestorerStub) RestoreBeer(ctx context.Context, id burp.ID) error { return s.Err }
+func (s beerPurgerStub) PurgeBeer(ctx context.Context, id burp.ID) error     { return s.Err }
 func (b beerSelectorStub) SelectBeer(ctx context.Context, id burp.ID) (*burp.Beer, error) {
 	return b.Beer, b.Err
 }
+
+func (b beerListerStub) ListBeers(ctx context.Context, q burp.BeerQuery) ([]*burp.Beer, error) {
+	return nil, b.Err
+}
+
+func (b beerSearcherStub) SearchBeers(ctx context.Context, s burp.BeerSearch) ([]*burp.BeerMatch, error) {
+	return nil, b.Err
+}
+
+func (b beerEventRepoStub) SaveBeerEvent(ctx context.Context, e *burp.BeerEvent) error {
+	return b.Err
+}
+
+func (b beerEventRepoStub) SelectBeerHistory(ctx context.Context, id burp.ID) ([]*burp.BeerEvent, error) {
+	return nil, b.Err
+}
@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"burp/repo/migration"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns embedded migrations, oldest first.
+func Migrations() ([]migration.Migration, error) {
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migration.Load(fsys)
+}
+
+// LatestVersion returns the version of the last embedded migration.
+func LatestVersion() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return len(migrations), nil
+}
+
+// SchemaVersion returns the version of the last migration applied
+// to the database, zero when none is. It leaves the database as is,
+// even when table schema_migrations does not exist yet.
+func SchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var exists bool
+	q := `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')`
+	if err := db.QueryRowContext(ctx, q).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("unable to look up schema_migrations table: %w", err)
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	return schemaVersion(ctx, db)
+}
+
+// Migrate applies migrations up or down until the database schema is
+// at given version. As SQLite allows a single writer, all migrations
+// run in one transaction, recorded in table schema_migrations.
+func Migrate(ctx context.Context, db *sql.DB, to int) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	if to < 0 || to > len(migrations) {
+		return fmt.Errorf("unknown schema version %d, latest is %d", to, len(migrations))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin migrations: %w", err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+
+	if err := createMigrationsTable(ctx, tx); err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if current > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than latest known %d", current, len(migrations))
+	}
+
+	for ; current < to; current++ {
+		m := migrations[current]
+		if err := applyMigration(ctx, tx, m.Up, "INSERT INTO schema_migrations(version, name) VALUES(?, ?)", m); err != nil {
+			return err
+		}
+	}
+
+	for ; current > to; current-- {
+		m := migrations[current-1]
+		if err := applyMigration(ctx, tx, m.Down, "DELETE FROM schema_migrations WHERE version = ? AND name = ?", m); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit migrations: %w", err)
+	}
+
+	return nil
+}
+
+// MigrateUp applies all pending migrations.
+func MigrateUp(ctx context.Context, db *sql.DB) error {
+	latest, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+	return Migrate(ctx, db, latest)
+}
+
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func createMigrationsTable(ctx context.Context, db execQueryer) error {
+	q := `CREATE TABLE IF NOT EXISTS schema_migrations(
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f', 'now'))
+	)`
+
+	if _, err := db.ExecContext(ctx, q); err != nil {
+		return fmt.Errorf("unable to create schema_migrations table: %w", err)
+	}
+
+	return nil
+}
+
+func schemaVersion(ctx context.Context, db execQueryer) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("unable to select schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// applyMigration runs sql then records it with record query.
+func applyMigration(ctx context.Context, tx *sql.Tx, sql, record string, m migration.Migration) error {
+	if _, err := tx.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("unable to apply migration %d %q: %w", m.Version, m.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, record, m.Version, m.Name); err != nil {
+		return fmt.Errorf("unable to record migration %d %q: %w", m.Version, m.Name, err)
+	}
+
+	return nil
+}
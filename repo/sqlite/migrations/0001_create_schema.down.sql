@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS beer_price;
+
+DROP TABLE IF EXISTS beer_event;
+
+DROP TABLE IF EXISTS beer;
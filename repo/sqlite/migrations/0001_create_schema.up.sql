@@ -0,0 +1,43 @@
+-- times are stored as UTC text formatted as 2006-01-02T15:04:05.000000,
+-- so that they sort lexically
+CREATE TABLE beer(
+    id TEXT PRIMARY KEY NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TEXT,
+    name TEXT NOT NULL,
+    price_currency TEXT NOT NULL CHECK (price_currency IN ('Euro', 'Dollar')),
+    price_amount INTEGER NOT NULL CHECK (price_amount > 0)
+);
+
+CREATE INDEX beer_name_idx ON beer (name, id);
+
+CREATE INDEX beer_created_at_idx ON beer (created_at, id);
+
+CREATE INDEX beer_price_amount_idx ON beer (price_amount, id);
+
+CREATE INDEX beer_price_idx ON beer (price_currency, price_amount, id);
+
+CREATE INDEX beer_deleted_at_idx ON beer (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE beer_event(
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    id TEXT UNIQUE NOT NULL,
+    beer_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    time TEXT NOT NULL,
+    before TEXT,
+    after TEXT
+);
+
+CREATE INDEX beer_event_beer_id_idx ON beer_event (beer_id, time, seq);
+
+CREATE TABLE beer_price(
+    beer_id TEXT NOT NULL REFERENCES beer(id) ON DELETE CASCADE,
+    valid_from TEXT NOT NULL,
+    price_currency TEXT NOT NULL CHECK (price_currency IN ('Euro', 'Dollar')),
+    price_amount INTEGER NOT NULL CHECK (price_amount > 0),
+    PRIMARY KEY (beer_id, valid_from)
+);
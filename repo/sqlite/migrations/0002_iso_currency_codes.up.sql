@@ -0,0 +1,51 @@
+-- currencies are ISO 4217 codes, registered by the application.
+-- Tables are rebuilt to drop their CHECK constraint: renaming them
+-- first keeps beer_price rows from cascading when beer is dropped.
+ALTER TABLE beer_price RENAME TO beer_price_old;
+
+ALTER TABLE beer RENAME TO beer_old;
+
+CREATE TABLE beer(
+    id TEXT PRIMARY KEY NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TEXT,
+    name TEXT NOT NULL,
+    price_currency TEXT NOT NULL CHECK (length(price_currency) = 3),
+    price_amount INTEGER NOT NULL CHECK (price_amount > 0)
+);
+
+INSERT INTO beer
+SELECT id, created_at, updated_at, version, deleted_at, name,
+    CASE price_currency WHEN 'Euro' THEN 'EUR' WHEN 'Dollar' THEN 'USD' END,
+    price_amount
+FROM beer_old;
+
+CREATE TABLE beer_price(
+    beer_id TEXT NOT NULL REFERENCES beer(id) ON DELETE CASCADE,
+    valid_from TEXT NOT NULL,
+    price_currency TEXT NOT NULL CHECK (length(price_currency) = 3),
+    price_amount INTEGER NOT NULL CHECK (price_amount > 0),
+    PRIMARY KEY (beer_id, valid_from)
+);
+
+INSERT INTO beer_price
+SELECT beer_id, valid_from,
+    CASE price_currency WHEN 'Euro' THEN 'EUR' WHEN 'Dollar' THEN 'USD' END,
+    price_amount
+FROM beer_price_old;
+
+DROP TABLE beer_price_old;
+
+DROP TABLE beer_old;
+
+CREATE INDEX beer_name_idx ON beer (name, id);
+
+CREATE INDEX beer_created_at_idx ON beer (created_at, id);
+
+CREATE INDEX beer_price_amount_idx ON beer (price_amount, id);
+
+CREATE INDEX beer_price_idx ON beer (price_currency, price_amount, id);
+
+CREATE INDEX beer_deleted_at_idx ON beer (deleted_at) WHERE deleted_at IS NOT NULL;
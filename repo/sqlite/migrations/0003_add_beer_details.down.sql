@@ -0,0 +1,11 @@
+ALTER TABLE beer DROP COLUMN description;
+
+ALTER TABLE beer DROP COLUMN volume;
+
+ALTER TABLE beer DROP COLUMN ibu;
+
+ALTER TABLE beer DROP COLUMN abv;
+
+ALTER TABLE beer DROP COLUMN style;
+
+ALTER TABLE beer DROP COLUMN brewery;
@@ -0,0 +1,12 @@
+-- catalogue details are optional, empty or zero when unknown
+ALTER TABLE beer ADD COLUMN brewery TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE beer ADD COLUMN style TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE beer ADD COLUMN abv REAL NOT NULL DEFAULT 0 CHECK (abv >= 0 AND abv <= 100);
+
+ALTER TABLE beer ADD COLUMN ibu INTEGER NOT NULL DEFAULT 0 CHECK (ibu >= 0);
+
+ALTER TABLE beer ADD COLUMN volume INTEGER NOT NULL DEFAULT 0 CHECK (volume >= 0);
+
+ALTER TABLE beer ADD COLUMN description TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+CREATE TABLE brewery(
+    id TEXT PRIMARY KEY NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    name TEXT NOT NULL,
+    country TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX brewery_name_idx ON brewery (name, id);
+
+-- breweries with beers, in trash included, cannot be deleted
+ALTER TABLE beer ADD COLUMN brewery_id TEXT REFERENCES brewery(id);
+
+CREATE INDEX beer_brewery_id_idx ON beer (brewery_id) WHERE brewery_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- tags are normalized by the application, sorted bytewise when read
+CREATE TABLE beer_tag(
+    beer_id TEXT NOT NULL REFERENCES beer(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL CHECK (length(tag) BETWEEN 1 AND 30),
+    PRIMARY KEY (beer_id, tag)
+);
+
+CREATE INDEX beer_tag_tag_idx ON beer_tag (tag, beer_id);
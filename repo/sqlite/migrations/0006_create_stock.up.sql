@@ -0,0 +1,8 @@
+CREATE TABLE stock(
+    beer_id TEXT NOT NULL REFERENCES beer(id) ON DELETE CASCADE,
+    location TEXT NOT NULL CHECK (length(location) BETWEEN 1 AND 50),
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    threshold INTEGER NOT NULL DEFAULT 0 CHECK (threshold >= 0),
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (beer_id, location)
+);
@@ -0,0 +1,24 @@
+CREATE TABLE customer_order(
+    id TEXT PRIMARY KEY NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    customer TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'paid', 'cancelled'))
+);
+
+CREATE INDEX customer_order_listing_idx ON customer_order (created_at DESC, id);
+
+CREATE INDEX customer_order_status_idx ON customer_order (status, created_at DESC, id);
+
+-- lines snapshot ordered beers, which may be purged since
+CREATE TABLE order_line(
+    order_id TEXT NOT NULL REFERENCES customer_order(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    beer_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    price_currency TEXT NOT NULL CHECK (length(price_currency) = 3),
+    price_amount INTEGER NOT NULL CHECK (price_amount > 0),
+    PRIMARY KEY (order_id, position)
+);
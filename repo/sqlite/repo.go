@@ -0,0 +1,947 @@
+package sqlite
+
+import (
+	"burp"
+	"burp/repo"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// Repo stores beers in SQLite. It is safe for concurrent use.
+type Repo struct {
+	DB *sql.DB
+}
+
+// SaveBeer inserts beer when its version is zero, otherwise updates it
+// if its version still matches the stored one. Version is then incremented.
+func (r *Repo) SaveBeer(ctx context.Context, beer *burp.Beer) error {
+	// beer row and tags are saved at once
+	return r.SaveBeers(ctx, []*burp.Beer{beer})
+}
+
+// SaveBeers saves beers in order as SaveBeer does, in a single
+// transaction. When one cannot be saved, none is, and given
+// beers are left as they were.
+func (r *Repo) SaveBeers(ctx context.Context, beers []*burp.Beer) error {
+	given := make([]burp.Beer, len(beers))
+	for i, beer := range beers {
+		given[i] = *beer
+	}
+
+	err := r.saveBeers(ctx, beers)
+	if err != nil {
+		for i, beer := range beers {
+			*beer = given[i]
+		}
+	}
+
+	return err
+}
+
+// saveBeers saves beers within a savepoint, rolled back to when one
+// cannot be saved, so that none is even within a transaction.
+func (r *Repo) saveBeers(ctx context.Context, beers []*burp.Beer) error {
+	if _, ok := r.tx(ctx); !ok {
+		return r.RunInTx(ctx, func(ctx context.Context) error { return r.saveBeers(ctx, beers) })
+	}
+
+	db := r.db(ctx)
+	if _, err := db.ExecContext(ctx, "SAVEPOINT save_beers"); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	for _, beer := range beers {
+		if err := saveBeer(ctx, db, beer); err != nil {
+			if _, rbErr := db.ExecContext(ctx, "ROLLBACK TO save_beers"); rbErr != nil {
+				return repo.Error(rbErr.Error())
+			}
+			return err
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, "RELEASE save_beers"); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func saveBeer(ctx context.Context, db conn, beer *burp.Beer) error {
+	details := []any{beer.Brewery, beer.Style, beer.ABV, beer.IBU, beer.Volume, beer.Description, beer.BreweryID}
+
+	q := `INSERT INTO beer(id, updated_at, name, price_currency, price_amount, created_at, version,
+		brewery, style, abv, ibu, volume, description, brewery_id)
+	VALUES(?, ?, ?, ?, ?, ?, 1, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (id) DO NOTHING
+	RETURNING version, created_at`
+	args := append([]any{beer.ID, formatTime(beer.UpdatedAt), beer.Name, beer.Price.Currency, beer.Price.Amount, formatTime(beer.CreatedAt)}, details...)
+
+	if beer.Version != 0 {
+		q = `UPDATE beer
+		SET updated_at = ?, name = ?, price_currency = ?, price_amount = ?, version = version + 1,
+			brewery = ?, style = ?, abv = ?, ibu = ?, volume = ?, description = ?, brewery_id = ?
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
+		RETURNING version, created_at`
+		args = append([]any{formatTime(beer.UpdatedAt), beer.Name, beer.Price.Currency, beer.Price.Amount}, details...)
+		args = append(args, beer.ID, beer.Version)
+	}
+
+	err := db.QueryRowContext(ctx, q, args...).Scan(&beer.Version, timestamp{&beer.CreatedAt})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Errorf(
+			"unable to save beer %q at version %d: %w",
+			beer.ID,
+			beer.Version,
+			burp.ErrVersionConflict,
+		)
+	}
+
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return saveTags(ctx, db, beer)
+}
+
+// saveTags replaces the tags of beer, within the
+// transaction of the beer row, by those it has.
+func saveTags(ctx context.Context, db conn, beer *burp.Beer) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM beer_tag WHERE beer_id = ?`, beer.ID); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	for _, tag := range beer.Tags {
+		_, err := db.ExecContext(ctx, `INSERT INTO beer_tag(beer_id, tag) VALUES(?, ?)`, beer.ID, tag)
+		if err != nil {
+			return repo.Error(err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (r *Repo) RemoveBeer(ctx context.Context, id burp.ID, at time.Time) error {
+	q := `UPDATE beer SET deleted_at = ?, version = version + 1
+	WHERE id = ? AND deleted_at IS NULL`
+
+	return r.execOnBeer(ctx, q, id, formatTime(at), id)
+}
+
+func (r *Repo) RestoreBeer(ctx context.Context, id burp.ID) error {
+	q := `UPDATE beer SET deleted_at = NULL, version = version + 1
+	WHERE id = ? AND deleted_at IS NOT NULL`
+
+	return r.execOnBeer(ctx, q, id, id)
+}
+
+func (r *Repo) PurgeBeer(ctx context.Context, id burp.ID) error {
+	q := `DELETE FROM beer WHERE id = ? AND deleted_at IS NOT NULL`
+
+	return r.execOnBeer(ctx, q, id, id)
+}
+
+// execOnBeer executes q on beer of given id, failing
+// with repo.ErrNotFound when no beer is affected.
+func (r *Repo) execOnBeer(ctx context.Context, q string, id burp.ID, args ...any) error {
+	res, err := r.db(ctx).ExecContext(ctx, q, args...)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	if n == 0 {
+		return repo.Errorf(
+			"beer not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectBeer(ctx context.Context, id burp.ID) (*burp.Beer, error) {
+	q := `SELECT ` + beerColumns + ` FROM beer WHERE id = ? AND deleted_at IS NULL`
+	beer, err := scanBeer(r.db(ctx).QueryRowContext(ctx, q, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repo.Errorf(
+			"beer not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return beer, nil
+}
+
+// sortColumns maps each sort to the column it orders by and the
+// expression its cursor key is compared with. Text is compared
+// bytewise by SQLite default collation, matching cursor keys.
+var sortColumns = map[burp.BeerSort]struct{ expr, key string }{
+	burp.SortByName:      {expr: "name", key: "?"},
+	burp.SortByCreatedAt: {expr: "created_at", key: "?"},
+	burp.SortByPrice:     {expr: "price_amount", key: "CAST(? AS INTEGER)"},
+}
+
+func (r *Repo) ListBeers(ctx context.Context, q burp.BeerQuery) ([]*burp.Beer, error) {
+	var (
+		where = []string{"deleted_at IS NULL"}
+		args  []any
+	)
+
+	if q.Filter.Deleted {
+		where[0] = "deleted_at IS NOT NULL"
+	}
+
+	if q.Filter.Name != "" {
+		where = append(where, "burp_contains(name, ?)")
+		args = append(args, q.Filter.Name)
+	}
+
+	if q.Filter.BreweryID.UUID != uuid.Nil {
+		where = append(where, "brewery_id = ?")
+		args = append(args, q.Filter.BreweryID)
+	}
+
+	for _, tag := range q.Filter.Tags {
+		where = append(where, "EXISTS (SELECT 1 FROM beer_tag WHERE beer_id = beer.id AND tag = ?)")
+		args = append(args, tag)
+	}
+
+	if q.Filter.Currency != "" {
+		where = append(where, "price_currency = ?")
+		args = append(args, q.Filter.Currency)
+	}
+
+	if q.Filter.MinAmount != 0 {
+		where = append(where, "price_amount >= ?")
+		args = append(args, q.Filter.MinAmount)
+	}
+
+	if q.Filter.MaxAmount != 0 {
+		where = append(where, "price_amount <= ?")
+		args = append(args, q.Filter.MaxAmount)
+	}
+
+	col, ok := sortColumns[q.Sort]
+	if !ok {
+		return nil, repo.Errorf("unable to sort beers by %q", q.Sort)
+	}
+
+	op, dir := ">", "ASC"
+	if q.Desc {
+		op, dir = "<", "DESC"
+	}
+
+	if q.After != nil {
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s, ?)", col.expr, op, col.key))
+		args = append(args, q.After.Key, q.After.ID)
+	}
+
+	query := `SELECT ` + beerColumns + ` FROM beer WHERE ` + strings.Join(where, " AND ")
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT ?`, col.expr, dir, dir)
+	args = append(args, q.Limit)
+
+	rows, err := r.db(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	beers := []*burp.Beer{}
+	for rows.Next() {
+		beer, err := scanBeer(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		beers = append(beers, beer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return beers, nil
+}
+
+// SearchBeers ranks beers with burp.Score.
+func (r *Repo) SearchBeers(ctx context.Context, s burp.BeerSearch) ([]*burp.BeerMatch, error) {
+	q := `SELECT * FROM (
+		SELECT ` + beerColumns + `, burp_score(name, ?) AS score
+		FROM beer
+		WHERE deleted_at IS NULL
+	)
+	WHERE score > 0
+	ORDER BY score DESC, name, id
+	LIMIT ?`
+
+	rows, err := r.db(ctx).QueryContext(ctx, q, s.Text, s.Limit)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	matches := []*burp.BeerMatch{}
+	for rows.Next() {
+		var match burp.BeerMatch
+		match.Beer, err = scanBeer(rows, &match.Score)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		matches = append(matches, &match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return matches, nil
+}
+
+const beerColumns = `id, created_at, updated_at, version, deleted_at, name, price_currency, price_amount,
+	brewery, style, abv, ibu, volume, description, brewery_id,
+	(SELECT json_group_array(tag) FROM (SELECT tag FROM beer_tag WHERE beer_id = beer.id ORDER BY tag)) AS tags`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanBeer scans beerColumns from row, followed by extra columns into dest.
+func scanBeer(row scanner, dest ...any) (*burp.Beer, error) {
+	var beer burp.Beer
+	err := row.Scan(append([]any{
+		&beer.ID,
+		timestamp{&beer.CreatedAt},
+		timestamp{&beer.UpdatedAt},
+		&beer.Version,
+		nullTimestamp{&beer.DeletedAt},
+		&beer.Name,
+		&beer.Price.Currency,
+		&beer.Price.Amount,
+		&beer.Brewery,
+		&beer.Style,
+		&beer.ABV,
+		&beer.IBU,
+		&beer.Volume,
+		&beer.Description,
+		&beer.BreweryID,
+		tagList{&beer.Tags},
+	}, dest...)...)
+	return &beer, err
+}
+
+func (r *Repo) SaveBeerEvent(ctx context.Context, e *burp.BeerEvent) error {
+	q := `INSERT INTO beer_event(id, beer_id, kind, actor, time, before, after)
+	VALUES(?, ?, ?, ?, ?, ?, ?)`
+
+	before, err := marshalNull(e.Before)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	after, err := marshalNull(e.After)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	_, err = r.db(ctx).ExecContext(ctx, q, e.ID, e.BeerID, e.Kind, e.Actor, formatTime(e.Time), before, after)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectBeerHistory(ctx context.Context, id burp.ID) ([]*burp.BeerEvent, error) {
+	q := `SELECT id, beer_id, kind, actor, time, before, after
+	FROM beer_event
+	WHERE beer_id = ?
+	ORDER BY time, seq`
+
+	rows, err := r.db(ctx).QueryContext(ctx, q, id)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	events := []*burp.BeerEvent{}
+	for rows.Next() {
+		var (
+			e             burp.BeerEvent
+			before, after sql.NullString
+		)
+
+		err := rows.Scan(&e.ID, &e.BeerID, &e.Kind, &e.Actor, timestamp{&e.Time}, &before, &after)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+
+		if e.Before, err = unmarshalNull(before); err != nil {
+			return nil, repo.Error(err.Error())
+		}
+
+		if e.After, err = unmarshalNull(after); err != nil {
+			return nil, repo.Error(err.Error())
+		}
+
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return events, nil
+}
+
+func marshalNull(beer *burp.Beer) (any, error) {
+	if beer == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(beer)
+	return string(b), err
+}
+
+func unmarshalNull(s sql.NullString) (*burp.Beer, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+
+	var beer burp.Beer
+	err := json.Unmarshal([]byte(s.String), &beer)
+	return &beer, err
+}
+
+func (r *Repo) SavePriceChange(ctx context.Context, c *burp.PriceChange) error {
+	q := `INSERT INTO beer_price(beer_id, valid_from, price_currency, price_amount)
+	VALUES(?, ?, ?, ?)
+	ON CONFLICT (beer_id, valid_from)
+	DO
+	UPDATE SET price_currency = excluded.price_currency, price_amount = excluded.price_amount`
+
+	_, err := r.db(ctx).ExecContext(ctx, q, c.BeerID, formatTime(c.From), c.Price.Currency, c.Price.Amount)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectPriceChanges(ctx context.Context, id burp.ID) ([]*burp.PriceChange, error) {
+	q := `SELECT beer_id, valid_from, price_currency, price_amount
+	FROM beer_price
+	WHERE beer_id = ?
+	ORDER BY valid_from`
+
+	rows, err := r.db(ctx).QueryContext(ctx, q, id)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	changes := []*burp.PriceChange{}
+	for rows.Next() {
+		c, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return changes, nil
+}
+
+func (r *Repo) SelectPriceAt(ctx context.Context, id burp.ID, at time.Time) (*burp.PriceChange, error) {
+	q := `SELECT beer_id, valid_from, price_currency, price_amount
+	FROM beer_price
+	WHERE beer_id = ? AND valid_from <= ?
+	ORDER BY valid_from DESC
+	LIMIT 1`
+
+	c, err := scanPriceChange(r.db(ctx).QueryRowContext(ctx, q, id, formatTime(at)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repo.Errorf(
+			"price of beer %q not found at %s: %w",
+			id,
+			at,
+			repo.ErrNotFound,
+		)
+	}
+
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return c, nil
+}
+
+func scanPriceChange(row scanner) (*burp.PriceChange, error) {
+	var c burp.PriceChange
+	err := row.Scan(&c.BeerID, timestamp{&c.From}, &c.Price.Currency, &c.Price.Amount)
+	return &c, err
+}
+
+// SaveBrewery inserts brewery when its version is zero, otherwise updates
+// it if its version still matches the stored one. Version is then incremented.
+func (r *Repo) SaveBrewery(ctx context.Context, brewery *burp.Brewery) error {
+	q := `INSERT INTO brewery(id, created_at, updated_at, version, name, country, city)
+	VALUES(?, ?, ?, 1, ?, ?, ?)
+	ON CONFLICT (id) DO NOTHING
+	RETURNING version, created_at`
+	args := []any{brewery.ID, formatTime(brewery.CreatedAt), formatTime(brewery.UpdatedAt), brewery.Name, brewery.Country, brewery.City}
+
+	if brewery.Version != 0 {
+		q = `UPDATE brewery
+		SET updated_at = ?, version = version + 1, name = ?, country = ?, city = ?
+		WHERE id = ? AND version = ?
+		RETURNING version, created_at`
+		args = []any{formatTime(brewery.UpdatedAt), brewery.Name, brewery.Country, brewery.City, brewery.ID, brewery.Version}
+	}
+
+	err := r.db(ctx).QueryRowContext(ctx, q, args...).Scan(&brewery.Version, timestamp{&brewery.CreatedAt})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Errorf(
+			"unable to save brewery %q at version %d: %w",
+			brewery.ID,
+			brewery.Version,
+			burp.ErrVersionConflict,
+		)
+	}
+
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectBrewery(ctx context.Context, id burp.ID) (*burp.Brewery, error) {
+	q := `SELECT ` + breweryColumns + ` FROM brewery WHERE id = ?`
+	brewery, err := scanBrewery(r.db(ctx).QueryRowContext(ctx, q, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repo.Errorf(
+			"brewery not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return brewery, nil
+}
+
+func (r *Repo) ListBreweries(ctx context.Context, q burp.BreweryQuery) ([]*burp.Brewery, error) {
+	var (
+		where []string
+		args  []any
+	)
+
+	if q.Name != "" {
+		where = append(where, "burp_contains(name, ?)")
+		args = append(args, q.Name)
+	}
+
+	if q.After != nil {
+		where = append(where, "(name, id) > (?, ?)")
+		args = append(args, q.After.Key, q.After.ID)
+	}
+
+	query := `SELECT ` + breweryColumns + ` FROM brewery`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY name, id LIMIT ?`
+	args = append(args, q.Limit)
+
+	rows, err := r.db(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	breweries := []*burp.Brewery{}
+	for rows.Next() {
+		brewery, err := scanBrewery(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		breweries = append(breweries, brewery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return breweries, nil
+}
+
+// RemoveBrewery deletes a brewery, failing while beers refer to it.
+func (r *Repo) RemoveBrewery(ctx context.Context, id burp.ID) error {
+	res, err := r.db(ctx).ExecContext(ctx, `DELETE FROM brewery WHERE id = ?`, id)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	if n == 0 {
+		return repo.Errorf(
+			"brewery not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	return nil
+}
+
+const breweryColumns = `id, created_at, updated_at, version, name, country, city`
+
+func scanBrewery(row scanner) (*burp.Brewery, error) {
+	var b burp.Brewery
+	err := row.Scan(&b.ID, timestamp{&b.CreatedAt}, timestamp{&b.UpdatedAt}, &b.Version, &b.Name, &b.Country, &b.City)
+	return &b, err
+}
+
+// MoveStock moves stock within a transaction, which holds the database
+// write lock so that concurrent movements apply one after the other.
+func (r *Repo) MoveStock(ctx context.Context, m *burp.StockMovement) (*burp.Stock, error) {
+	var stock *burp.Stock
+	err := r.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		if stock, err = r.selectStock(ctx, m.BeerID, m.Location); err != nil {
+			return err
+		}
+
+		if err := stock.Apply(m); err != nil {
+			return repo.Errorf("unable to move stock of beer %q at %q by %d: %w", m.BeerID, m.Location, m.Delta, err)
+		}
+
+		return r.saveStock(ctx, stock)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+func (r *Repo) SetStockThreshold(ctx context.Context, t *burp.StockThreshold) (*burp.Stock, error) {
+	q := `INSERT INTO stock(beer_id, location, threshold, updated_at)
+	VALUES(?, ?, ?, ?)
+	ON CONFLICT (beer_id, location) DO UPDATE
+	SET threshold = excluded.threshold, updated_at = excluded.updated_at
+	RETURNING ` + stockColumns
+
+	stock, err := scanStock(r.db(ctx).QueryRowContext(ctx, q, t.BeerID, t.Location, t.Threshold, formatTime(t.Time)))
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return stock, nil
+}
+
+// selectStock returns the stock of beer id at location, empty if none is stored.
+func (r *Repo) selectStock(ctx context.Context, id burp.ID, location string) (*burp.Stock, error) {
+	q := `SELECT ` + stockColumns + ` FROM stock WHERE beer_id = ? AND location = ?`
+	stock, err := scanStock(r.db(ctx).QueryRowContext(ctx, q, id, location))
+	if errors.Is(err, sql.ErrNoRows) {
+		return &burp.Stock{BeerID: id, Location: location}, nil
+	}
+
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return stock, nil
+}
+
+func (r *Repo) saveStock(ctx context.Context, s *burp.Stock) error {
+	q := `INSERT INTO stock(beer_id, location, quantity, threshold, updated_at)
+	VALUES(?, ?, ?, ?, ?)
+	ON CONFLICT (beer_id, location) DO UPDATE
+	SET quantity = excluded.quantity, threshold = excluded.threshold, updated_at = excluded.updated_at`
+
+	_, err := r.db(ctx).ExecContext(ctx, q, s.BeerID, s.Location, s.Quantity, s.Threshold, formatTime(s.UpdatedAt))
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func (r *Repo) ListStock(ctx context.Context, q burp.StockQuery) ([]*burp.Stock, error) {
+	var (
+		where []string
+		args  []any
+	)
+
+	if q.BeerID.UUID != uuid.Nil {
+		where = append(where, "beer_id = ?")
+		args = append(args, q.BeerID)
+	}
+
+	if q.Location != "" {
+		where = append(where, "location = ?")
+		args = append(args, q.Location)
+	}
+
+	if q.Low {
+		where = append(where, "quantity < threshold")
+	}
+
+	query := `SELECT ` + stockColumns + ` FROM stock`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY beer_id, location`
+
+	rows, err := r.db(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	stock := []*burp.Stock{}
+	for rows.Next() {
+		s, err := scanStock(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		stock = append(stock, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	return stock, nil
+}
+
+const stockColumns = `beer_id, location, quantity, threshold, updated_at`
+
+func scanStock(row scanner) (*burp.Stock, error) {
+	var s burp.Stock
+	err := row.Scan(&s.BeerID, &s.Location, &s.Quantity, &s.Threshold, timestamp{&s.UpdatedAt})
+	return &s, err
+}
+
+// SaveOrder inserts order along with its lines when its version is zero,
+// otherwise updates its status if its version still matches the stored
+// one. Version is then incremented.
+func (r *Repo) SaveOrder(ctx context.Context, order *burp.Order) error {
+	given := *order
+	err := r.saveOrder(ctx, order)
+	if err != nil {
+		*order = given
+	}
+
+	return err
+}
+
+// saveOrder saves order within a savepoint as saveBeers does beers,
+// so that its row and lines are saved at once.
+func (r *Repo) saveOrder(ctx context.Context, order *burp.Order) error {
+	if _, ok := r.tx(ctx); !ok {
+		return r.RunInTx(ctx, func(ctx context.Context) error { return r.saveOrder(ctx, order) })
+	}
+
+	db := r.db(ctx)
+	if _, err := db.ExecContext(ctx, "SAVEPOINT save_order"); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	if err := saveOrder(ctx, db, order); err != nil {
+		if _, rbErr := db.ExecContext(ctx, "ROLLBACK TO save_order"); rbErr != nil {
+			return repo.Error(rbErr.Error())
+		}
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, "RELEASE save_order"); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func saveOrder(ctx context.Context, db conn, order *burp.Order) error {
+
+	q := `INSERT INTO customer_order(id, created_at, updated_at, version, customer, status)
+	VALUES(?, ?, ?, 1, ?, ?)
+	ON CONFLICT (id) DO NOTHING
+	RETURNING version, created_at`
+	args := []any{order.ID, formatTime(order.CreatedAt), formatTime(order.UpdatedAt), order.Customer, order.Status}
+
+	if order.Version != 0 {
+		q = `UPDATE customer_order
+		SET updated_at = ?, version = version + 1, status = ?
+		WHERE id = ? AND version = ?
+		RETURNING version, created_at`
+		args = []any{formatTime(order.UpdatedAt), order.Status, order.ID, order.Version}
+	}
+
+	inserted := order.Version == 0
+	err := db.QueryRowContext(ctx, q, args...).Scan(&order.Version, timestamp{&order.CreatedAt})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Errorf(
+			"unable to save order %q at version %d: %w",
+			order.ID,
+			order.Version,
+			burp.ErrVersionConflict,
+		)
+	}
+
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+
+	if !inserted {
+		return nil
+	}
+
+	q = `INSERT INTO order_line(order_id, position, beer_id, name, quantity, price_currency, price_amount)
+	VALUES(?, ?, ?, ?, ?, ?, ?)`
+	for i, l := range order.Lines {
+		if _, err := db.ExecContext(ctx, q, order.ID, i, l.BeerID, l.Name, l.Quantity, l.Price.Currency, l.Price.Amount); err != nil {
+			return repo.Error(err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (r *Repo) SelectOrder(ctx context.Context, id burp.ID) (*burp.Order, error) {
+	q := `SELECT ` + orderColumns + ` FROM customer_order WHERE id = ?`
+	order, err := scanOrder(r.db(ctx).QueryRowContext(ctx, q, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repo.Errorf(
+			"order not found with id %q: %w",
+			id,
+			repo.ErrNotFound,
+		)
+	}
+
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	if err := r.selectOrderLines(ctx, []*burp.Order{order}); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *Repo) ListOrders(ctx context.Context, q burp.OrderQuery) ([]*burp.Order, error) {
+	var args []any
+
+	query := `SELECT ` + orderColumns + ` FROM customer_order`
+	if q.Status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, q.Status)
+	}
+	query += ` ORDER BY created_at DESC, id LIMIT ?`
+	args = append(args, q.Limit)
+
+	rows, err := r.db(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	orders := []*burp.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, repo.Error(err.Error())
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, repo.Error(err.Error())
+	}
+
+	if err := r.selectOrderLines(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// selectOrderLines sets the lines of orders at once.
+func (r *Repo) selectOrderLines(ctx context.Context, orders []*burp.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byID := make(map[burp.ID]*burp.Order, len(orders))
+	args := make([]any, len(orders))
+	for i, order := range orders {
+		byID[order.ID] = order
+		order.Lines = []burp.OrderLine{}
+		args[i] = order.ID
+	}
+
+	q := `SELECT order_id, beer_id, name, quantity, price_currency, price_amount
+	FROM order_line
+	WHERE order_id IN (?` + strings.Repeat(", ?", len(orders)-1) + `)
+	ORDER BY order_id, position`
+
+	rows, err := r.db(ctx).QueryContext(ctx, q, args...)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id burp.ID
+			l  burp.OrderLine
+		)
+		if err := rows.Scan(&id, &l.BeerID, &l.Name, &l.Quantity, &l.Price.Currency, &l.Price.Amount); err != nil {
+			return repo.Error(err.Error())
+		}
+		byID[id].Lines = append(byID[id].Lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+const orderColumns = `id, created_at, updated_at, version, customer, status`
+
+func scanOrder(row scanner) (*burp.Order, error) {
+	var o burp.Order
+	err := row.Scan(&o.ID, timestamp{&o.CreatedAt}, timestamp{&o.UpdatedAt}, &o.Version, &o.Customer, &o.Status)
+	return &o, err
+}
@@ -0,0 +1,220 @@
+package sqlite_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo/repotest"
+	"burp/repo/sqlite"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openDB opens a migrated database in a temporary directory.
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "burp.db"), 5*time.Second)
+	if err != nil {
+		t.Fatalf("Open returned unexpected error %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := sqlite.MigrateUp(context.Background(), db); err != nil {
+		t.Fatalf("MigrateUp(ctx, db) returned unexpected error %s", err)
+	}
+
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	r := &sqlite.Repo{DB: openDB(t)}
+	repotest.TestBeerRepo(t, r)
+	repotest.TestTxRunner(t, r)
+	repotest.TestBreweryRepo(t, r)
+	repotest.TestStockRepo(t, r)
+	repotest.TestOrderRepo(t, r)
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	latest, err := sqlite.LatestVersion()
+	if err != nil {
+		t.Fatalf("LatestVersion() returned error %s, want none", err)
+	}
+
+	for _, to := range []int{0, latest} {
+		if err := sqlite.Migrate(ctx, db, to); err != nil {
+			t.Fatalf("Migrate(ctx, db, %d) returned error %s, want none", to, err)
+		}
+
+		version, err := sqlite.SchemaVersion(ctx, db)
+		if err != nil {
+			t.Fatalf("SchemaVersion(ctx, db) returned error %s, want none", err)
+		}
+
+		if version != to {
+			t.Errorf("SchemaVersion(ctx, db) after Migrate(ctx, db, %d) returned %d", to, version)
+		}
+	}
+}
+
+func TestSchemaVersionWithoutMigrationsTable(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "burp.db"), 5*time.Second)
+	if err != nil {
+		t.Fatalf("Open returned unexpected error %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	version, err := sqlite.SchemaVersion(ctx, db)
+	if err != nil {
+		t.Fatalf("SchemaVersion(ctx, db) returned error %s, want none", err)
+	}
+
+	if version != 0 {
+		t.Errorf("SchemaVersion(ctx, db) of an empty database returned %d, want 0", version)
+	}
+
+	var tables int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		t.Fatalf("Counting tables returned error %s, want none", err)
+	}
+
+	if tables != 0 {
+		t.Errorf("SchemaVersion(ctx, db) of an empty database created %d tables, want none", tables)
+	}
+}
+
+func TestMigrateLegacyCurrencies(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	r := &sqlite.Repo{DB: db}
+
+	if err := sqlite.Migrate(ctx, db, 1); err != nil {
+		t.Fatalf("Migrate(ctx, db, 1) returned error %s, want none", err)
+	}
+
+	beer := burptest.RandBeer()
+	_, err := db.ExecContext(ctx, `INSERT INTO beer (id, created_at, updated_at, name, price_currency, price_amount)
+		VALUES (?, '2023-01-01T00:00:00.000000', '2023-01-01T00:00:00.000000', ?, 'Dollar', 350)`, beer.ID, beer.Name)
+	if err != nil {
+		t.Fatalf("inserting a beer priced in Dollar returned error %s", err)
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO beer_price (beer_id, valid_from, price_currency, price_amount)
+		VALUES (?, '2023-01-01T00:00:00.000000', 'Dollar', 350)`, beer.ID)
+	if err != nil {
+		t.Fatalf("inserting a price in Dollar returned error %s", err)
+	}
+
+	if err := sqlite.MigrateUp(ctx, db); err != nil {
+		t.Fatalf("MigrateUp(ctx, db) returned error %s, want none", err)
+	}
+
+	want := burp.Price{Currency: burp.USD, Amount: 350}
+
+	got, err := r.SelectBeer(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectBeer(ctx, %s) after migration returned error %s", beer.ID, err)
+	}
+	if got.Price != want {
+		t.Errorf("SelectBeer(ctx, %s) after migration returned price %+v, want %+v", beer.ID, got.Price, want)
+	}
+
+	changes, err := r.SelectPriceChanges(ctx, beer.ID)
+	if err != nil {
+		t.Fatalf("SelectPriceChanges(ctx, %s) after migration returned error %s", beer.ID, err)
+	}
+	if len(changes) != 1 || changes[0].Price != want {
+		t.Errorf("SelectPriceChanges(ctx, %s) after migration returned %+v, want a change to %+v", beer.ID, changes, want)
+	}
+}
+
+func TestListBeers(t *testing.T) {
+	ctx := context.Background()
+	r := &sqlite.Repo{DB: openDB(t)}
+
+	var beers []*burp.Beer
+	for i := 0; i < 5; i++ {
+		beer := burptest.RandBeer()
+		beer.Name = fmt.Sprintf("Ale %d", i)
+		beer.Price.Amount = uint(100 * (5 - i))
+		if err := r.SaveBeer(ctx, beer); err != nil {
+			t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+		}
+		beers = append(beers, beer)
+	}
+
+	tests := []struct {
+		description string
+		query       burp.BeerQuery
+		want        []*burp.Beer
+	}{
+		{
+			description: "by name after cursor",
+			query:       burp.BeerQuery{Sort: burp.SortByName, Limit: 2, After: burp.NewCursor(beers[1], burp.SortByName, false)},
+			want:        beers[2:4],
+		},
+		{
+			description: "by price after cursor",
+			query:       burp.BeerQuery{Sort: burp.SortByPrice, Limit: 10, After: burp.NewCursor(beers[3], burp.SortByPrice, false)},
+			want:        []*burp.Beer{beers[2], beers[1], beers[0]},
+		},
+		{
+			description: "by name descending, filtered",
+			query:       burp.BeerQuery{Sort: burp.SortByName, Desc: true, Limit: 10, Filter: burp.BeerFilter{Name: "ALE", MaxAmount: 300}},
+			want:        []*burp.Beer{beers[4], beers[3], beers[2]},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, err := r.ListBeers(ctx, test.query)
+			if err != nil {
+				t.Fatalf("ListBeers(ctx, %+v) returned unexpected error %s", test.query, err)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ListBeers(ctx, %+v) returned unexpected beers, (-want/+got):\n%s", test.query, diff)
+			}
+		})
+	}
+}
+
+func TestSearchBeers(t *testing.T) {
+	ctx := context.Background()
+	r := &sqlite.Repo{DB: openDB(t)}
+
+	names := []string{"Triple Karmeliet", "Karmeliet", "Kwak"}
+	for _, name := range names {
+		beer := burptest.RandBeer()
+		beer.Name = name
+		if err := r.SaveBeer(ctx, beer); err != nil {
+			t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+		}
+	}
+
+	matches, err := r.SearchBeers(ctx, burp.BeerSearch{Text: "karmeliet", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchBeers(ctx, karmeliet) returned unexpected error %s", err)
+	}
+
+	var got []string
+	for _, m := range matches {
+		got = append(got, m.Beer.Name)
+		if want := burp.Score(m.Beer, "karmeliet"); m.Score != want {
+			t.Errorf("SearchBeers(ctx, karmeliet) scored %q %f, want %f", m.Beer.Name, m.Score, want)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"Karmeliet", "Triple Karmeliet"}, got); diff != "" {
+		t.Errorf("SearchBeers(ctx, karmeliet) returned unexpected beers, (-want/+got):\n%s", diff)
+	}
+}
@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"burp"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"modernc.org/sqlite"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func init() {
+	// Go functions keep filtering and ranking identical to
+	// other repos, e.g. case folding beyond ascii.
+	sqlite.MustRegisterDeterministicScalarFunction("burp_contains", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		s, substr, err := stringArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Contains(strings.ToLower(s), strings.ToLower(substr)), nil
+	})
+
+	sqlite.MustRegisterDeterministicScalarFunction("burp_score", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		name, text, err := stringArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return burp.Score(&burp.Beer{Name: name}, text), nil
+	})
+}
+
+func stringArgs(args []driver.Value) (string, string, error) {
+	a, ok := args[0].(string)
+	if !ok {
+		return "", "", fmt.Errorf("want text argument, got %T", args[0])
+	}
+	b, ok := args[1].(string)
+	if !ok {
+		return "", "", fmt.Errorf("want text argument, got %T", args[1])
+	}
+	return a, b, nil
+}
+
+// Open opens the database file at path, created when missing,
+// with foreign keys enforced. Transactions take the write lock
+// as they begin, and wait for it up to busy timeout.
+func Open(path string, busyTimeout time.Duration) (*sql.DB, error) {
+	params := url.Values{
+		"_pragma": {
+			"foreign_keys(1)",
+			"journal_mode(WAL)",
+			fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()),
+		},
+		"_txlock": {"immediate"},
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %q: %w", path, err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to open %q: %w", path, err)
+	}
+
+	return db, nil
+}
+
+// timeLayout formats times stored as text. Fixed width makes them sort
+// lexically, and it matches burp.Cursor keys of creation dates.
+const timeLayout = "2006-01-02T15:04:05.000000"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// timestamp scans a time stored as text.
+type timestamp struct{ t *time.Time }
+
+func (ts timestamp) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unable to scan %T into a time", src)
+	}
+
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return err
+	}
+
+	*ts.t = t
+	return nil
+}
+
+// nullTimestamp scans a time stored as text or NULL.
+type nullTimestamp struct{ t **time.Time }
+
+func (ts nullTimestamp) Scan(src any) error {
+	if src == nil {
+		*ts.t = nil
+		return nil
+	}
+
+	var t time.Time
+	if err := (timestamp{&t}).Scan(src); err != nil {
+		return err
+	}
+
+	*ts.t = &t
+	return nil
+}
+
+func formatNullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// tagList scans tags aggregated as a JSON array, nil when empty.
+type tagList struct{ tags *[]string }
+
+func (l tagList) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unable to scan %T into tags", src)
+	}
+
+	var tags []string
+	if err := json.Unmarshal([]byte(s), &tags); err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		tags = nil
+	}
+
+	*l.tags = tags
+	return nil
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"burp/repo"
+	"context"
+	"database/sql"
+)
+
+// conn queries the database, satisfied by *sql.DB and *sql.Tx.
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// tx is a transaction carried by a context,
+// along with the database it runs on.
+type tx struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+type txKey struct{}
+
+// RunInTx runs fn in a transaction, committed when fn returns nil and
+// rolled back otherwise. Repos on the same database take part in it
+// when called with the context given to fn, as do nested RunInTx.
+// The transaction holds the database write lock until it ends.
+func (r *Repo) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := r.tx(ctx); ok {
+		return fn(ctx)
+	}
+
+	t, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return repo.Error(err.Error())
+	}
+	defer t.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx{db: r.DB, tx: t})); err != nil {
+		return err
+	}
+
+	if err := t.Commit(); err != nil {
+		return repo.Error(err.Error())
+	}
+
+	return nil
+}
+
+func (r *Repo) tx(ctx context.Context) (*sql.Tx, bool) {
+	t, ok := ctx.Value(txKey{}).(tx)
+	if !ok || t.db != r.DB {
+		return nil, false
+	}
+	return t.tx, true
+}
+
+// db returns the transaction ctx carries on r.DB, r.DB otherwise.
+func (r *Repo) db(ctx context.Context) conn {
+	if t, ok := r.tx(ctx); ok {
+		return t
+	}
+	return r.DB
+}
@@ -0,0 +1,88 @@
+package chi
+
+import (
+	"burp"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// PostBatch runs a batch of operations on beers, such as:
+//
+//	{"atomic": false, "operations": [
+//	  {"op": "create", "name": "Kwak", "price": {"currency": "EUR", "amount": 350}},
+//	  {"op": "update", "id": "...", "version": 2, "name": "Kwak", "price": {...}},
+//	  {"op": "delete", "id": "..."}
+//	]}
+//
+// Response tells, in the same order, the status each operation would have
+// had as a single request: 201 with the created beer, 200 with the updated
+// one, 204 once deleted, or an error. Operations not run because another
+// of an atomic batch failed have status 424.
+func PostBatch(batcher BeerBatcher) HandlerWithErr {
+	type operation struct {
+		Op      burp.BatchOpKind `json:"op"`
+		ID      burp.ID          `json:"id"`
+		Version uint             `json:"version"`
+		Name    string           `json:"name"`
+		Price   burp.Price       `json:"price"`
+		beerDetails
+	}
+
+	type result struct {
+		Status int        `json:"status"`
+		Beer   *burp.Beer `json:"beer,omitempty"`
+		Error  string     `json:"error,omitempty"`
+	}
+
+	statuses := map[burp.BatchOpKind]int{
+		burp.BatchCreate: http.StatusCreated,
+		burp.BatchUpdate: http.StatusOK,
+		burp.BatchDelete: http.StatusNoContent,
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var batch struct {
+			Atomic     bool        `json:"atomic"`
+			Operations []operation `json:"operations"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			return err
+		}
+
+		ops := make([]*burp.BatchOp, len(batch.Operations))
+		for i, op := range batch.Operations {
+			ops[i] = &burp.BatchOp{
+				Kind: op.Op,
+				Beer: &burp.Beer{
+					ID:      op.ID,
+					Version: op.Version,
+					Name:    op.Name,
+					Price:   op.Price,
+				},
+			}
+			op.beerDetails.set(ops[i].Beer)
+		}
+
+		results, err := batcher.Batch(r.Context(), ops, batch.Atomic)
+		if err != nil {
+			return err
+		}
+
+		body := make([]result, len(results))
+		for i, res := range results {
+			switch {
+			case res.Err == nil:
+				body[i] = result{Status: statuses[ops[i].Kind], Beer: res.Beer}
+			case errors.Is(res.Err, burp.ErrBatchAborted):
+				body[i] = result{Status: http.StatusFailedDependency, Error: res.Err.Error()}
+			default:
+				apiErr := toAPIError(res.Err)
+				body[i] = result{Status: apiErr.Code, Error: apiErr.ErrorMessage}
+			}
+		}
+
+		return json.NewEncoder(w).Encode(map[string]any{"items": body})
+	}
+}
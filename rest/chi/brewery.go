@@ -0,0 +1,188 @@
+package chi
+
+import (
+	"burp"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// breweryFields are the fields of a brewery sent in request bodies.
+type breweryFields struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+	Country string `json:"country"`
+	City    string `json:"city"`
+}
+
+func PostBrewery(saver BrewerySaver) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var fields breweryFields
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+			return err
+		}
+
+		brewery := burp.Brewery{
+			ID:      burp.ID{UUID: uuid.New()},
+			Name:    fields.Name,
+			Country: fields.Country,
+			City:    fields.City,
+		}
+
+		if err := saver.SaveBrewery(r.Context(), &brewery); err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(brewery.Version))
+		w.WriteHeader(http.StatusCreated)
+		return json.NewEncoder(w).Encode(brewery)
+	}
+}
+
+// PutBrewery creates or replaces the brewery of path id,
+// at the version sent in If-Match header or request body.
+func PutBrewery(saver BrewerySaver) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		var fields breweryFields
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+			return err
+		}
+
+		brewery := burp.Brewery{
+			ID:      id,
+			Version: fields.Version,
+			Name:    fields.Name,
+			Country: fields.Country,
+			City:    fields.City,
+		}
+
+		version, conditional, err := ifMatch(r)
+		if err != nil {
+			return err
+		}
+		if conditional {
+			brewery.Version = version
+		}
+
+		err = saver.SaveBrewery(r.Context(), &brewery)
+		if conditional && errors.Is(err, burp.ErrVersionConflict) {
+			return apiError{
+				Code:         http.StatusPreconditionFailed,
+				ErrorMessage: err.Error(),
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(brewery.Version))
+		w.WriteHeader(http.StatusAccepted)
+		return json.NewEncoder(w).Encode(brewery)
+	}
+}
+
+func GetBrewery(selector BrewerySelector) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		brewery, err := selector.SelectBrewery(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		tag := etag(brewery.Version)
+		w.Header().Set("ETag", tag)
+
+		if r.Header.Get("If-None-Match") == tag {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+
+		return json.NewEncoder(w).Encode(brewery)
+	}
+}
+
+// DeleteBrewery deletes a brewery, in conflict while it has beers.
+func DeleteBrewery(remover BreweryRemover) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		if err := remover.RemoveBrewery(r.Context(), id); err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+		return nil
+	}
+}
+
+// ListBreweries lists breweries sorted by name, page by page. Query
+// parameters are name to filter by, limit and cursor of the page to fetch.
+func ListBreweries(lister BreweryLister) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		params := r.URL.Query()
+		q := burp.BreweryQuery{Name: params.Get("name")}
+
+		var err error
+		if q.Limit, err = parseLimit(params); err != nil {
+			return err
+		}
+
+		if p := params.Get("cursor"); p != "" {
+			cursor, err := burp.ParseCursor(p)
+			if err != nil {
+				return err
+			}
+			q.After = cursor
+		}
+
+		page, err := lister.ListBreweries(r.Context(), q)
+		if err != nil {
+			return err
+		}
+
+		return json.NewEncoder(w).Encode(page)
+	}
+}
+
+// ListBreweryBeers lists beers of a brewery,
+// with the query parameters of ListBeers.
+func ListBreweryBeers(app BreweryBeerListConverter) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		params := r.URL.Query()
+		q, err := parseBeerQuery(params)
+		if err != nil {
+			return err
+		}
+
+		page, err := app.ListBreweryBeers(r.Context(), id, q)
+		if err != nil {
+			return err
+		}
+
+		if err := convertBeerPrices(r.Context(), app, page.Beers, parseConvert(params)); err != nil {
+			return err
+		}
+
+		return json.NewEncoder(w).Encode(page)
+	}
+}
@@ -0,0 +1,113 @@
+package chi
+
+import (
+	"burp"
+	"burp/beerio"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// MaxImportSize bounds the size of an import request body.
+const MaxImportSize = 32 << 20
+
+// ImportBeers saves beers of request body, CSV or NDJSON as told by its
+// Content-Type. Mode query parameter is "each", the default, to save
+// every valid beer, or "all" to save none unless all are valid.
+// Rows which could not be imported are reported in response.
+func ImportBeers(importer BeerImporter) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		format, err := beerio.FormatOf(r.Header.Get("Content-Type"))
+		if err != nil {
+			return apiError{
+				Code:         http.StatusUnsupportedMediaType,
+				ErrorMessage: err.Error(),
+			}
+		}
+
+		mode := burp.ImportMode(r.URL.Query().Get("mode"))
+		if mode == "" {
+			mode = burp.ImportEach
+		}
+
+		dec, err := beerio.NewDecoder(format, http.MaxBytesReader(w, r.Body, MaxImportSize))
+		if err != nil {
+			return err
+		}
+
+		report, err := importer.ImportBeers(r.Context(), dec, mode)
+		if err != nil {
+			return err
+		}
+
+		if mode == burp.ImportAll && len(report.Errors) > 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+
+		return json.NewEncoder(w).Encode(report)
+	}
+}
+
+// ExportBeers streams beers sorted by name, as CSV or NDJSON told
+// by format query parameter, CSV by default. They are filtered by
+// the same query parameters as ListBeers. An error once streaming
+// started aborts the response, so that clients see it truncated
+// rather than ending with an error body.
+func ExportBeers(exporter BeerExporter) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		params := r.URL.Query()
+		filter, err := parseFilter(params)
+		if err != nil {
+			return err
+		}
+
+		format := beerio.Format(params.Get("format"))
+		if format == "" {
+			format = beerio.CSV
+		}
+
+		ew := &exportWriter{ResponseWriter: w, format: format}
+		enc, err := beerio.NewEncoder(format, ew)
+		if err != nil {
+			return err
+		}
+
+		err = exporter.ExportBeers(r.Context(), filter, enc)
+		if err != nil && ew.started {
+			log.Printf("Export aborted:\n%q\n", err.Error())
+			panic(http.ErrAbortHandler)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// an export of no beer may write nothing
+		ew.start()
+		return nil
+	}
+}
+
+// exportWriter sets export headers on first write, and tells
+// whether the response started, its status being then sent.
+type exportWriter struct {
+	http.ResponseWriter
+	format  beerio.Format
+	started bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.start()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *exportWriter) start() {
+	if w.started {
+		return
+	}
+
+	w.started = true
+	w.Header().Set("Content-Type", w.format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="beers.`+string(w.format)+`"`)
+	w.WriteHeader(http.StatusOK)
+}
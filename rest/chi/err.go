@@ -2,7 +2,6 @@ package chi
 
 import (
 	"burp"
-	"burp/repo"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -29,32 +28,7 @@ func Handle(fn HandlerWithErr) http.HandlerFunc {
 			return
 		}
 
-		var unmarshalTypeError *json.UnmarshalTypeError
-		var parseTimeError *time.ParseError
-
-		now := time.Now()
-		apiErr := apiError{Time: now}
-
-		switch {
-		case errors.As(err, &unmarshalTypeError):
-			apiErr.Code = http.StatusBadRequest
-			apiErr.ErrorMessage = fmt.Sprintf("corrupted %s type", unmarshalTypeError.Field)
-		case errors.As(err, &parseTimeError):
-			apiErr.Code = http.StatusBadRequest
-			apiErr.ErrorMessage = fmt.Sprintf("corrupted time value: %s", parseTimeError.Value)
-		case errors.As(err, &burp.Err{}):
-			apiErr.Code = http.StatusBadRequest
-			apiErr.ErrorMessage = err.Error()
-		case errors.As(err, &apiErr):
-			apiErr.Time = now
-		case errors.Is(err, repo.ErrNotFound):
-			apiErr.Code = http.StatusNotFound
-			apiErr.ErrorMessage = err.Error()
-		default:
-			log.Printf("Internal error:\n%q\n", err.Error())
-			apiErr.Code = http.StatusInternalServerError
-			apiErr.ErrorMessage = "internal error"
-		}
+		apiErr := toAPIError(err)
 
 		jsonB, err := json.Marshal(apiErr)
 		if err != nil {
@@ -66,3 +40,44 @@ func Handle(fn HandlerWithErr) http.HandlerFunc {
 		w.Write(jsonB)
 	}
 }
+
+// toAPIError tells the status and message of err to respond with.
+func toAPIError(err error) apiError {
+	var unmarshalTypeError *json.UnmarshalTypeError
+	var parseTimeError *time.ParseError
+	var maxBytesError *http.MaxBytesError
+
+	now := time.Now()
+	apiErr := apiError{Time: now}
+
+	switch {
+	case errors.As(err, &unmarshalTypeError):
+		apiErr.Code = http.StatusBadRequest
+		apiErr.ErrorMessage = fmt.Sprintf("corrupted %s type", unmarshalTypeError.Field)
+	case errors.As(err, &parseTimeError):
+		apiErr.Code = http.StatusBadRequest
+		apiErr.ErrorMessage = fmt.Sprintf("corrupted time value: %s", parseTimeError.Value)
+	case errors.As(err, &maxBytesError):
+		apiErr.Code = http.StatusRequestEntityTooLarge
+		apiErr.ErrorMessage = fmt.Sprintf("request body exceed %d bytes", maxBytesError.Limit)
+	case errors.Is(err, burp.ErrVersionConflict), errors.Is(err, burp.ErrBreweryHasBeers),
+		errors.Is(err, burp.ErrStockInsufficient), errors.Is(err, burp.ErrStockOverflow),
+		errors.Is(err, burp.ErrOrderTransitionInvalid):
+		apiErr.Code = http.StatusConflict
+		apiErr.ErrorMessage = err.Error()
+	case errors.As(err, &burp.Err{}):
+		apiErr.Code = http.StatusBadRequest
+		apiErr.ErrorMessage = err.Error()
+	case errors.As(err, &apiErr):
+		apiErr.Time = now
+	case errors.Is(err, burp.ErrNotFound):
+		apiErr.Code = http.StatusNotFound
+		apiErr.ErrorMessage = err.Error()
+	default:
+		log.Printf("Internal error:\n%q\n", err.Error())
+		apiErr.Code = http.StatusInternalServerError
+		apiErr.ErrorMessage = "internal error"
+	}
+
+	return apiErr
+}
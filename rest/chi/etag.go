@@ -0,0 +1,50 @@
+package chi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag formats a beer version as a strong entity tag.
+func etag(version uint) string {
+	return strconv.Quote(strconv.FormatUint(uint64(version), 10))
+}
+
+// parseETag reads a beer version from an entity tag, weak or not.
+func parseETag(tag string) (uint, error) {
+	unquoted, err := strconv.Unquote(strings.TrimPrefix(tag, "W/"))
+	if err != nil {
+		return 0, apiError{
+			Code:         http.StatusBadRequest,
+			ErrorMessage: fmt.Sprintf("invalid entity tag %q", tag),
+		}
+	}
+
+	version, err := strconv.ParseUint(unquoted, 10, 0)
+	if err != nil {
+		return 0, apiError{
+			Code:         http.StatusBadRequest,
+			ErrorMessage: fmt.Sprintf("invalid entity tag %q", tag),
+		}
+	}
+
+	return uint(version), nil
+}
+
+// ifMatch reads the beer version expected by If-Match header.
+// ok is false when the header is missing or matches any version.
+func ifMatch(r *http.Request) (version uint, ok bool, err error) {
+	tag := r.Header.Get("If-Match")
+	if tag == "" || tag == "*" {
+		return 0, false, nil
+	}
+
+	version, err = parseETag(tag)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return version, true, nil
+}
@@ -2,26 +2,42 @@ package chi
 
 import (
 	"burp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// parseID reads beer ID from URL path.
+func parseID(r *http.Request) (burp.ID, error) {
+	p := chi.URLParam(r, "id")
+	id, err := uuid.Parse(p)
+	if err != nil {
+		return burp.ID{}, apiError{
+			Code:         http.StatusBadRequest,
+			ErrorMessage: fmt.Sprintf("invalid id %q: %s", p, err),
+		}
+	}
+
+	return burp.ID{UUID: id}, nil
+}
+
 func DeleteBeer(remover BeerRemover) HandlerWithErr {
 	return func(w http.ResponseWriter, r *http.Request) error {
-		p := chi.URLParam(r, "id")
-		id, err := uuid.Parse(p)
+		id, err := parseID(r)
 		if err != nil {
-			return apiError{
-				Code:         http.StatusBadRequest,
-				ErrorMessage: fmt.Sprintf("invalid id %q: %s", p, err),
-			}
+			return err
 		}
 
-		err = remover.RemoveBeer(r.Context(), burp.ID{UUID: id})
+		err = remover.RemoveBeer(r.Context(), id)
 		if err != nil {
 			return err
 		}
@@ -32,34 +48,235 @@ func DeleteBeer(remover BeerRemover) HandlerWithErr {
 	}
 }
 
-func GetBeer(selector BeerSelector) HandlerWithErr {
+func RestoreBeer(restorer BeerRestorer) HandlerWithErr {
 	return func(w http.ResponseWriter, r *http.Request) error {
-		p := chi.URLParam(r, "id")
-		id, err := uuid.Parse(p)
+		id, err := parseID(r)
 		if err != nil {
-			return apiError{
-				Code:         http.StatusBadRequest,
-				ErrorMessage: fmt.Sprintf("invalid id %q: %s", p, err),
-			}
+			return err
+		}
+
+		err = restorer.RestoreBeer(r.Context(), id)
+		if err != nil {
+			return err
 		}
 
-		beer, err := selector.SelectBeer(r.Context(), burp.ID{UUID: id})
+		w.WriteHeader(http.StatusNoContent)
+
+		return nil
+	}
+}
+
+func PurgeBeer(purger BeerPurger) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
 		if err != nil {
 			return err
 		}
 
+		err = purger.PurgeBeer(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+		return nil
+	}
+}
+
+// GetBeer returns a beer, its price converted to currency query
+// parameter if any. Converted beers have no ETag, as their
+// price changes with exchange rates.
+func GetBeer(app BeerSelectConverter) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		beer, err := app.SelectBeer(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		if currency := parseConvert(r.URL.Query()); currency != "" {
+			if err := convertPrice(r.Context(), app, &beer.Price, currency); err != nil {
+				return err
+			}
+			return json.NewEncoder(w).Encode(&beer)
+		}
+
+		tag := etag(beer.Version)
+		w.Header().Set("ETag", tag)
+
+		if r.Header.Get("If-None-Match") == tag {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+
 		return json.NewEncoder(w).Encode(&beer)
 	}
 }
 
+func GetBeerHistory(selector BeerHistorySelector) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		events, err := selector.BeerHistory(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		return json.NewEncoder(w).Encode(map[string]any{"items": events})
+	}
+}
+
+// GetPriceHistory returns the price periods of a beer, converted
+// to convert query parameter if any.
+func GetPriceHistory(app PriceHistoryConverter) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		periods, err := app.PriceHistory(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		if currency := parseConvert(r.URL.Query()); currency != "" {
+			for _, period := range periods {
+				if err := convertPrice(r.Context(), app, &period.Price, currency); err != nil {
+					return err
+				}
+			}
+		}
+
+		return json.NewEncoder(w).Encode(map[string]any{"items": periods})
+	}
+}
+
+// GetPriceAt returns the price of a beer effective at
+// the instant given by query parameter at, now if missing,
+// converted to convert query parameter if any.
+func GetPriceAt(app PriceAtConverter) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		at := time.Now().UTC()
+		if p := r.URL.Query().Get("at"); p != "" {
+			if at, err = time.Parse(time.RFC3339, p); err != nil {
+				return apiError{
+					Code:         http.StatusBadRequest,
+					ErrorMessage: fmt.Sprintf("invalid instant %q: %s", p, err),
+				}
+			}
+		}
+
+		price, err := app.PriceAt(r.Context(), id, at)
+		if err != nil {
+			return err
+		}
+
+		if currency := parseConvert(r.URL.Query()); currency != "" {
+			if err := convertPrice(r.Context(), app, price, currency); err != nil {
+				return err
+			}
+		}
+
+		return json.NewEncoder(w).Encode(price)
+	}
+}
+
+// PostPriceChange schedules a future price of a beer.
+func PostPriceChange(scheduler PriceScheduler) HandlerWithErr {
+	type fields struct {
+		Price burp.Price `json:"price"`
+		From  time.Time  `json:"from"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		var changeFields fields
+		if err := json.NewDecoder(r.Body).Decode(&changeFields); err != nil {
+			return err
+		}
+
+		change := burp.PriceChange{
+			BeerID: id,
+			Price:  changeFields.Price,
+			From:   changeFields.From.UTC(),
+		}
+
+		if err := scheduler.SchedulePrice(r.Context(), &change); err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		return json.NewEncoder(w).Encode(change)
+	}
+}
+
+// beerDetails are the catalogue details of a beer sent in request bodies.
+type beerDetails struct {
+	BreweryID   *burp.ID `json:"breweryId"`
+	Brewery     string   `json:"brewery"`
+	Style       string   `json:"style"`
+	ABV         float64  `json:"abv"`
+	IBU         uint     `json:"ibu"`
+	Volume      uint     `json:"volume"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+func (d beerDetails) set(beer *burp.Beer) {
+	beer.BreweryID = d.BreweryID
+	beer.Brewery = d.Brewery
+	beer.Style = d.Style
+	beer.ABV = d.ABV
+	beer.IBU = d.IBU
+	beer.Volume = d.Volume
+	beer.Description = d.Description
+	beer.Tags = d.Tags
+}
+
+// PutBeer creates or replaces a beer. Timestamps sent
+// in request body are ignored, they are set by server.
 func PutBeer(saver BeerSaver) HandlerWithErr {
+	type fields struct {
+		ID      burp.ID    `json:"id"`
+		Version uint       `json:"version"`
+		Name    string     `json:"name"`
+		Price   burp.Price `json:"price"`
+		beerDetails
+	}
+
 	return func(w http.ResponseWriter, r *http.Request) error {
-		var beer burp.Beer
+		var beerFields fields
 
-		if err := json.NewDecoder(r.Body).Decode(&beer); err != nil {
+		if err := json.NewDecoder(r.Body).Decode(&beerFields); err != nil {
 			return err
 		}
 
+		beer := burp.Beer{
+			ID:      beerFields.ID,
+			Version: beerFields.Version,
+			Name:    beerFields.Name,
+			Price:   beerFields.Price,
+		}
+		beerFields.beerDetails.set(&beer)
+
 		if id := chi.URLParam(r, "id"); id != beer.ID.String() {
 			return apiError{
 				Code:         http.StatusBadRequest,
@@ -67,28 +284,138 @@ func PutBeer(saver BeerSaver) HandlerWithErr {
 			}
 		}
 
-		if err := beer.Validate(); err != nil {
+		// If-Match header takes precedence over version sent in body
+		version, conditional, err := ifMatch(r)
+		if err != nil {
 			return err
 		}
+		if conditional {
+			beer.Version = version
+		}
+
+		err = saver.SaveBeer(r.Context(), &beer)
+		if conditional && errors.Is(err, burp.ErrVersionConflict) {
+			return apiError{
+				Code:         http.StatusPreconditionFailed,
+				ErrorMessage: err.Error(),
+			}
+		}
 
-		if err := saver.SaveBeer(r.Context(), &beer); err != nil {
+		if err != nil {
 			return err
 		}
 
+		w.Header().Set("ETag", etag(beer.Version))
 		w.WriteHeader(http.StatusAccepted)
 		return json.NewEncoder(w).Encode(beer)
 	}
 }
 
+// PatchBeer updates some fields of a beer, with either a JSON merge
+// patch or a JSON patch depending on request content type.
+// Identifier, timestamps and version are not patchable.
+func PatchBeer(app BeerSelectSaver) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if contentType != mergePatchType && contentType != jsonPatchType {
+			return apiError{
+				Code:         http.StatusUnsupportedMediaType,
+				ErrorMessage: fmt.Sprintf("content type must be %s or %s", mergePatchType, jsonPatchType),
+			}
+		}
+
+		beer, err := app.SelectBeer(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		version, conditional, err := ifMatch(r)
+		if err != nil {
+			return err
+		}
+		if !conditional {
+			version = beer.Version
+		}
+
+		var doc any
+		jsonB, err := json.Marshal(beer)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(jsonB, &doc); err != nil {
+			return err
+		}
+
+		if contentType == mergePatchType {
+			var patch any
+			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+				return apiError{
+					Code:         http.StatusBadRequest,
+					ErrorMessage: fmt.Sprintf("invalid merge patch: %s", err),
+				}
+			}
+			doc = mergePatch(doc, patch)
+		} else {
+			var ops []patchOp
+			if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+				return apiError{
+					Code:         http.StatusBadRequest,
+					ErrorMessage: fmt.Sprintf("invalid json patch: %s", err),
+				}
+			}
+
+			if doc, err = jsonPatch(doc, ops); err != nil {
+				return apiError{
+					Code:         http.StatusUnprocessableEntity,
+					ErrorMessage: fmt.Sprintf("unable to apply json patch: %s", err),
+				}
+			}
+		}
+
+		if jsonB, err = json.Marshal(doc); err != nil {
+			return err
+		}
+
+		var patched burp.Beer
+		if err := json.Unmarshal(jsonB, &patched); err != nil {
+			return err
+		}
+
+		patched.ID = beer.ID
+		patched.Version = version
+		patched.DeletedAt = nil
+
+		err = app.SaveBeer(r.Context(), &patched)
+		if conditional && errors.Is(err, burp.ErrVersionConflict) {
+			return apiError{
+				Code:         http.StatusPreconditionFailed,
+				ErrorMessage: err.Error(),
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(patched.Version))
+		return json.NewEncoder(w).Encode(patched)
+	}
+}
+
 func PostBeer(saver BeerSaver) HandlerWithErr {
 	type fields struct {
 		Name  string     `json:"name"`
 		Price burp.Price `json:"price"`
+		beerDetails
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) error {
 		var beerFields fields
-		now := time.Now().UTC()
 
 		err := json.NewDecoder(r.Body).Decode(&beerFields)
 		if err != nil {
@@ -96,9 +423,7 @@ func PostBeer(saver BeerSaver) HandlerWithErr {
 		}
 
 		beer := burp.Beer{
-			ID:        burp.ID{UUID: uuid.New()},
-			CreatedAt: now,
-			UpdatedAt: now,
+			ID: burp.ID{UUID: uuid.New()},
 
 			Name: beerFields.Name,
 			Price: burp.Price{
@@ -106,18 +431,206 @@ func PostBeer(saver BeerSaver) HandlerWithErr {
 				Amount:   beerFields.Price.Amount,
 			},
 		}
+		beerFields.beerDetails.set(&beer)
 
-		err = beer.Validate()
+		err = saver.SaveBeer(r.Context(), &beer)
 		if err != nil {
 			return err
 		}
 
-		err = saver.SaveBeer(r.Context(), &beer)
+		w.Header().Set("ETag", etag(beer.Version))
+		w.WriteHeader(http.StatusCreated)
+		return json.NewEncoder(w).Encode(beer)
+	}
+}
+
+// ListBeers lists beers page by page. Query parameters are:
+// name, currency, minPrice, maxPrice and tag, repeated for
+// beers having all given tags, to filter by,
+// sort prefixed by "-" for descending order,
+// limit of beers per page and cursor of the page to fetch.
+// Prices of listed beers are converted to convert query
+// parameter if any, after filtering them by currency.
+func ListBeers(app BeerListConverter) HandlerWithErr {
+	return listBeers(app, false)
+}
+
+// ListTrashedBeers lists beers in trash, the same way ListBeers does.
+func ListTrashedBeers(app BeerListConverter) HandlerWithErr {
+	return listBeers(app, true)
+}
+
+func listBeers(app BeerListConverter, deleted bool) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		params := r.URL.Query()
+		q, err := parseBeerQuery(params)
 		if err != nil {
 			return err
 		}
+		q.Filter.Deleted = deleted
 
-		w.WriteHeader(http.StatusCreated)
-		return json.NewEncoder(w).Encode(beer)
+		page, err := app.ListBeers(r.Context(), q)
+		if err != nil {
+			return err
+		}
+
+		if err := convertBeerPrices(r.Context(), app, page.Beers, parseConvert(params)); err != nil {
+			return err
+		}
+
+		return json.NewEncoder(w).Encode(page)
+	}
+}
+
+// parseBeerQuery reads the filter, sort, limit
+// and cursor query parameters of ListBeers.
+func parseBeerQuery(params url.Values) (burp.BeerQuery, error) {
+	filter, err := parseFilter(params)
+	if err != nil {
+		return burp.BeerQuery{}, err
+	}
+	q := burp.BeerQuery{Filter: filter}
+
+	if sort := params.Get("sort"); sort != "" {
+		q.Desc = strings.HasPrefix(sort, "-")
+		q.Sort = burp.BeerSort(strings.TrimPrefix(sort, "-"))
+	}
+
+	if q.Limit, err = parseLimit(params); err != nil {
+		return burp.BeerQuery{}, err
+	}
+
+	if p := params.Get("cursor"); p != "" {
+		cursor, err := burp.ParseCursor(p)
+		if err != nil {
+			return burp.BeerQuery{}, err
+		}
+		q.After = cursor
+	}
+
+	return q, nil
+}
+
+// SearchBeers returns beers whose name matches q query parameter,
+// best matches first. Limit query parameter caps results count,
+// convert converts their prices.
+func SearchBeers(app BeerSearchConverter) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		params := r.URL.Query()
+		s := burp.BeerSearch{Text: params.Get("q")}
+
+		limit, err := parseLimit(params)
+		if err != nil {
+			return err
+		}
+		s.Limit = limit
+
+		matches, err := app.SearchBeers(r.Context(), s)
+		if err != nil {
+			return err
+		}
+
+		if currency := parseConvert(params); currency != "" {
+			for _, match := range matches {
+				if err := convertPrice(r.Context(), app, &match.Beer.Price, currency); err != nil {
+					return err
+				}
+			}
+		}
+
+		return json.NewEncoder(w).Encode(map[string]any{"items": matches})
+	}
+}
+
+// parseFilter reads name, currency, minPrice, maxPrice
+// and tag query parameters to filter beers by.
+func parseFilter(params url.Values) (burp.BeerFilter, error) {
+	f := burp.BeerFilter{
+		Name:     params.Get("name"),
+		Currency: parseCurrency(params),
+		Tags:     burp.NormalizeTags(params["tag"]),
 	}
+
+	// bounds are read in order, for the error of
+	// invalid ones to always be that of the first
+	for _, bound := range []struct {
+		param  string
+		amount *uint
+	}{
+		{"minPrice", &f.MinAmount},
+		{"maxPrice", &f.MaxAmount},
+	} {
+		p := params.Get(bound.param)
+		if p == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(p, 10, 0)
+		if err != nil {
+			return f, apiError{
+				Code:         http.StatusBadRequest,
+				ErrorMessage: fmt.Sprintf("invalid %s %q: %s", bound.param, p, err),
+			}
+		}
+		*bound.amount = uint(n)
+	}
+
+	return f, nil
+}
+
+// parseCurrency reads the currency query parameter
+// beers are filtered by, empty when missing.
+func parseCurrency(params url.Values) burp.Currency {
+	return burp.ParseCurrency(params.Get("currency"))
+}
+
+// parseConvert reads the convert query parameter
+// prices are converted to, empty when missing.
+func parseConvert(params url.Values) burp.Currency {
+	return burp.ParseCurrency(params.Get("convert"))
+}
+
+// convertPrice converts p in place to currency to.
+func convertPrice(ctx context.Context, converter PriceConverter, p *burp.Price, to burp.Currency) error {
+	converted, err := converter.ConvertPrice(ctx, *p, to)
+	if err != nil {
+		return err
+	}
+
+	*p = converted
+	return nil
+}
+
+// convertBeerPrices converts prices of beers in place to currency
+// to, leaving them as they are when to is empty.
+func convertBeerPrices(ctx context.Context, converter PriceConverter, beers []*burp.Beer, to burp.Currency) error {
+	if to == "" {
+		return nil
+	}
+
+	for _, beer := range beers {
+		if err := convertPrice(ctx, converter, &beer.Price, to); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseLimit reads the limit query parameter, zero when missing.
+func parseLimit(params url.Values) (int, error) {
+	p := params.Get("limit")
+	if p == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(p)
+	if err != nil {
+		return 0, apiError{
+			Code:         http.StatusBadRequest,
+			ErrorMessage: fmt.Sprintf("invalid limit %q: %s", p, err),
+		}
+	}
+
+	return limit, nil
 }
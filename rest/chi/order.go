@@ -0,0 +1,102 @@
+package chi
+
+import (
+	"burp"
+	"encoding/json"
+	"net/http"
+)
+
+// PostOrder checks out a cart, placing a pending order of its beers
+// at their current price:
+//
+//	{"customer": "...", "items": [{"beerId": "...", "quantity": 2}]}
+func PostOrder(checkout OrderCheckout) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var cart burp.Cart
+		if err := json.NewDecoder(r.Body).Decode(&cart); err != nil {
+			return err
+		}
+
+		order, err := checkout.Checkout(r.Context(), &cart)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(order.Version))
+		w.WriteHeader(http.StatusCreated)
+		return json.NewEncoder(w).Encode(order)
+	}
+}
+
+func GetOrder(selector OrderSelector) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		order, err := selector.SelectOrder(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(order.Version))
+		return json.NewEncoder(w).Encode(order)
+	}
+}
+
+// ListOrders lists orders newest first, filtered by status parameter.
+func ListOrders(lister OrderLister) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		params := r.URL.Query()
+		q := burp.OrderQuery{Status: burp.OrderStatus(params.Get("status"))}
+
+		var err error
+		if q.Limit, err = parseLimit(params); err != nil {
+			return err
+		}
+
+		orders, err := lister.ListOrders(r.Context(), q)
+		if err != nil {
+			return err
+		}
+
+		return json.NewEncoder(w).Encode(map[string]any{"items": orders})
+	}
+}
+
+// PayOrder marks a pending order as paid, and returns it.
+func PayOrder(payer OrderPayer) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		order, err := payer.PayOrder(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(order.Version))
+		return json.NewEncoder(w).Encode(order)
+	}
+}
+
+// CancelOrder cancels a pending order, and returns it.
+func CancelOrder(canceller OrderCanceller) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		order, err := canceller.CancelOrder(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(order.Version))
+		return json.NewEncoder(w).Encode(order)
+	}
+}
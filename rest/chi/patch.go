@@ -0,0 +1,296 @@
+package chi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// mergePatch applies a JSON merge patch as defined by RFC 7396
+// to a document decoded in generic values, and returns the result.
+func mergePatch(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	d, ok := doc.(map[string]any)
+	if !ok {
+		d = make(map[string]any)
+	}
+
+	for key, value := range p {
+		if value == nil {
+			delete(d, key)
+			continue
+		}
+		d[key] = mergePatch(d[key], value)
+	}
+
+	return d
+}
+
+// patchOp is a JSON patch operation as defined by RFC 6902.
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+var errPathNotFound = errors.New("path not found")
+
+// jsonPatch applies ops in sequence to a document decoded
+// in generic values, and returns the result.
+func jsonPatch(doc any, ops []patchOp) (any, error) {
+	for i, op := range ops {
+		var err error
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d %q on %q: %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func (op patchOp) apply(doc any) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		var value any
+		if op.Value == nil {
+			return nil, errors.New("value is missing")
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, errors.New("test failed")
+			}
+			return doc, nil
+		}
+	case "remove":
+		return remove(doc, path)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from %q: %w", op.From, err)
+		}
+
+		if op.Op == "copy" {
+			return add(doc, path, deepCopy(value))
+		}
+
+		if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default:
+		return nil, errors.New("unknown operation")
+	}
+}
+
+// parsePointer splits a JSON pointer as defined by RFC 6901
+// in its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens, nil
+}
+
+func get(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, errPathNotFound
+			}
+			doc = value
+		case []any:
+			i, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[i]
+		default:
+			return nil, errPathNotFound
+		}
+	}
+
+	return doc, nil
+}
+
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(parent any, key string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			container[key] = value
+			return container, nil
+		case []any:
+			i := len(container)
+			if key != "-" {
+				var err error
+				if i, err = arrayIndex(key, len(container)); err != nil {
+					return nil, err
+				}
+			}
+			container = append(container, nil)
+			copy(container[i+1:], container[i:])
+			container[i] = value
+			return container, nil
+		default:
+			return nil, errPathNotFound
+		}
+	})
+}
+
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	return update(doc, path, func(parent any, key string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			if _, ok := container[key]; !ok {
+				return nil, errPathNotFound
+			}
+			delete(container, key)
+			return container, nil
+		case []any:
+			i, err := arrayIndex(key, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(container[:i], container[i+1:]...), nil
+		default:
+			return nil, errPathNotFound
+		}
+	})
+}
+
+// update walks doc down to the parent of path last token, replaces
+// it with the result of fn and returns the updated document.
+func update(doc any, path []string, fn func(parent any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	switch container := doc.(type) {
+	case map[string]any:
+		child, ok := container[path[0]]
+		if !ok {
+			return nil, errPathNotFound
+		}
+
+		child, err := update(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		container[path[0]] = child
+		return container, nil
+	case []any:
+		i, err := arrayIndex(path[0], len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := update(container[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		container[i] = child
+		return container, nil
+	default:
+		return nil, errPathNotFound
+	}
+}
+
+// arrayIndex parses an array index token, up to max included.
+func arrayIndex(token string, max int) (int, error) {
+	if token != "0" && strings.HasPrefix(token, "0") {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	if i > max {
+		return 0, errPathNotFound
+	}
+
+	return i, nil
+}
+
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, child := range v {
+			c[key] = deepCopy(child)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, child := range v {
+			c[i] = deepCopy(child)
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -5,12 +5,41 @@ import (
 	"context"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"time"
 )
 
 type App interface {
 	BeerSaver
 	BeerRemover
+	BeerRestorer
+	BeerPurger
 	BeerSelector
+	BeerLister
+	BeerSearcher
+	BeerHistorySelector
+	PriceScheduler
+	PriceHistorySelector
+	PriceAtSelector
+	BeerImporter
+	BeerExporter
+	BeerBatcher
+	PriceConverter
+	BrewerySaver
+	BrewerySelector
+	BreweryLister
+	BreweryRemover
+	BreweryBeerLister
+	BeerTagger
+	BeerUntagger
+	BeerStockSelector
+	StockMover
+	StockThresholdSetter
+	StockLister
+	OrderCheckout
+	OrderSelector
+	OrderLister
+	OrderPayer
+	OrderCanceller
 }
 
 type BeerSaver interface {
@@ -21,17 +50,216 @@ type BeerSelector interface {
 	SelectBeer(ctx context.Context, id burp.ID) (*burp.Beer, error)
 }
 
+type BeerSelectConverter interface {
+	BeerSelector
+	PriceConverter
+}
+
+type BeerSelectSaver interface {
+	BeerSelector
+	BeerSaver
+}
+
 type BeerRemover interface {
 	RemoveBeer(ctx context.Context, id burp.ID) error
 }
 
+type BeerRestorer interface {
+	RestoreBeer(ctx context.Context, id burp.ID) error
+}
+
+type BeerPurger interface {
+	PurgeBeer(ctx context.Context, id burp.ID) error
+}
+
+type BeerLister interface {
+	ListBeers(ctx context.Context, q burp.BeerQuery) (*burp.BeerPage, error)
+}
+
+type BeerListConverter interface {
+	BeerLister
+	PriceConverter
+}
+
+type BeerSearcher interface {
+	SearchBeers(ctx context.Context, s burp.BeerSearch) ([]*burp.BeerMatch, error)
+}
+
+type BeerSearchConverter interface {
+	BeerSearcher
+	PriceConverter
+}
+
+type BeerHistorySelector interface {
+	BeerHistory(ctx context.Context, id burp.ID) ([]*burp.BeerEvent, error)
+}
+
+type PriceScheduler interface {
+	SchedulePrice(ctx context.Context, c *burp.PriceChange) error
+}
+
+type PriceHistorySelector interface {
+	PriceHistory(ctx context.Context, id burp.ID) ([]*burp.PricePeriod, error)
+}
+
+type PriceHistoryConverter interface {
+	PriceHistorySelector
+	PriceConverter
+}
+
+type PriceAtSelector interface {
+	PriceAt(ctx context.Context, id burp.ID, at time.Time) (*burp.Price, error)
+}
+
+type PriceAtConverter interface {
+	PriceAtSelector
+	PriceConverter
+}
+
+type PriceConverter interface {
+	ConvertPrice(ctx context.Context, p burp.Price, to burp.Currency) (burp.Price, error)
+}
+
+type BeerImporter interface {
+	ImportBeers(ctx context.Context, dec burp.BeerDecoder, mode burp.ImportMode) (*burp.ImportReport, error)
+}
+
+type BeerExporter interface {
+	ExportBeers(ctx context.Context, f burp.BeerFilter, enc burp.BeerEncoder) error
+}
+
+type BeerBatcher interface {
+	Batch(ctx context.Context, ops []*burp.BatchOp, atomic bool) ([]*burp.BatchResult, error)
+}
+
+type BeerTagger interface {
+	TagBeer(ctx context.Context, id burp.ID, tags ...string) (*burp.Beer, error)
+}
+
+type BeerUntagger interface {
+	UntagBeer(ctx context.Context, id burp.ID, tag string) (*burp.Beer, error)
+}
+
+type BeerStockSelector interface {
+	BeerStock(ctx context.Context, id burp.ID) ([]*burp.Stock, error)
+}
+
+type StockMover interface {
+	MoveStock(ctx context.Context, m *burp.StockMovement) (*burp.Stock, error)
+}
+
+type StockThresholdSetter interface {
+	SetStockThreshold(ctx context.Context, t *burp.StockThreshold) (*burp.Stock, error)
+}
+
+type StockLister interface {
+	ListStock(ctx context.Context, q burp.StockQuery) ([]*burp.Stock, error)
+}
+
+type OrderCheckout interface {
+	Checkout(ctx context.Context, cart *burp.Cart) (*burp.Order, error)
+}
+
+type OrderSelector interface {
+	SelectOrder(ctx context.Context, id burp.ID) (*burp.Order, error)
+}
+
+type OrderLister interface {
+	ListOrders(ctx context.Context, q burp.OrderQuery) ([]*burp.Order, error)
+}
+
+type OrderPayer interface {
+	PayOrder(ctx context.Context, id burp.ID) (*burp.Order, error)
+}
+
+type OrderCanceller interface {
+	CancelOrder(ctx context.Context, id burp.ID) (*burp.Order, error)
+}
+
+type BrewerySaver interface {
+	SaveBrewery(ctx context.Context, brewery *burp.Brewery) error
+}
+
+type BrewerySelector interface {
+	SelectBrewery(ctx context.Context, id burp.ID) (*burp.Brewery, error)
+}
+
+type BreweryLister interface {
+	ListBreweries(ctx context.Context, q burp.BreweryQuery) (*burp.BreweryPage, error)
+}
+
+type BreweryRemover interface {
+	RemoveBrewery(ctx context.Context, id burp.ID) error
+}
+
+type BreweryBeerLister interface {
+	ListBreweryBeers(ctx context.Context, id burp.ID, q burp.BeerQuery) (*burp.BeerPage, error)
+}
+
+type BreweryBeerListConverter interface {
+	BreweryBeerLister
+	PriceConverter
+}
+
+// ActorHeader names who acts on beers, recorded in beers history.
+// It is not authenticated, so the actor is only a hint sent by the
+// client, which must not be trusted for auditing.
+const ActorHeader = "X-Actor"
+
+// withActor passes request actor to the app through its context,
+// as sent by the client without any authentication.
+func withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			r = r.WithContext(burp.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func Handler(app App) http.Handler {
 	r := chi.NewRouter()
+	r.Use(withActor)
 
+	r.Get("/api/v1/beers", Handle(ListBeers(app)))
+	r.Get("/api/v1/beers/search", Handle(SearchBeers(app)))
+	r.Get("/api/v1/beers/export", Handle(ExportBeers(app)))
+	r.Post("/api/v1/beers/import", Handle(ImportBeers(app)))
+	r.Post("/api/v1/beers/batch", Handle(PostBatch(app)))
 	r.Post("/api/v1/beers", Handle(PostBeer(app)))
 	r.Put("/api/v1/beers/{id}", Handle(PutBeer(app)))
+	r.Patch("/api/v1/beers/{id}", Handle(PatchBeer(app)))
 	r.Get("/api/v1/beers/{id}", Handle(GetBeer(app)))
 	r.Delete("/api/v1/beers/{id}", Handle(DeleteBeer(app)))
+	r.Get("/api/v1/beers/{id}/history", Handle(GetBeerHistory(app)))
+	r.Get("/api/v1/beers/{id}/price", Handle(GetPriceAt(app)))
+	r.Get("/api/v1/beers/{id}/prices", Handle(GetPriceHistory(app)))
+	r.Post("/api/v1/beers/{id}/prices", Handle(PostPriceChange(app)))
+	r.Get("/api/v1/beers/{id}/tags", Handle(GetBeerTags(app)))
+	r.Put("/api/v1/beers/{id}/tags/{tag}", Handle(PutBeerTag(app)))
+	r.Delete("/api/v1/beers/{id}/tags/{tag}", Handle(DeleteBeerTag(app)))
+	r.Get("/api/v1/beers/{id}/stock", Handle(GetBeerStock(app)))
+	r.Put("/api/v1/beers/{id}/stock/{location}", Handle(PutStockThreshold(app)))
+	r.Post("/api/v1/beers/{id}/stock/{location}/movements", Handle(PostStockMovement(app)))
+
+	r.Get("/api/v1/stock", Handle(ListStock(app)))
+
+	r.Get("/api/v1/orders", Handle(ListOrders(app)))
+	r.Post("/api/v1/orders", Handle(PostOrder(app)))
+	r.Get("/api/v1/orders/{id}", Handle(GetOrder(app)))
+	r.Post("/api/v1/orders/{id}/pay", Handle(PayOrder(app)))
+	r.Post("/api/v1/orders/{id}/cancel", Handle(CancelOrder(app)))
+
+	r.Get("/api/v1/breweries", Handle(ListBreweries(app)))
+	r.Post("/api/v1/breweries", Handle(PostBrewery(app)))
+	r.Put("/api/v1/breweries/{id}", Handle(PutBrewery(app)))
+	r.Get("/api/v1/breweries/{id}", Handle(GetBrewery(app)))
+	r.Delete("/api/v1/breweries/{id}", Handle(DeleteBrewery(app)))
+	r.Get("/api/v1/breweries/{id}/beers", Handle(ListBreweryBeers(app)))
+
+	r.Get("/api/v1/trash/beers", Handle(ListTrashedBeers(app)))
+	r.Post("/api/v1/trash/beers/{id}/restore", Handle(RestoreBeer(app)))
+	r.Delete("/api/v1/trash/beers/{id}", Handle(PurgeBeer(app)))
 
 	return r
 }
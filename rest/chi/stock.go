@@ -0,0 +1,137 @@
+package chi
+
+import (
+	"burp"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// parseLocation reads a stock location from URL path.
+func parseLocation(r *http.Request) (string, error) {
+	p := chi.URLParam(r, "location")
+	location, err := url.PathUnescape(p)
+	if err != nil {
+		return "", apiError{
+			Code:         http.StatusBadRequest,
+			ErrorMessage: fmt.Sprintf("invalid location %q: %s", p, err),
+		}
+	}
+
+	return location, nil
+}
+
+// GetBeerStock returns the stock of a beer at every location.
+func GetBeerStock(selector BeerStockSelector) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		stock, err := selector.BeerStock(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		return json.NewEncoder(w).Encode(map[string]any{"items": stock})
+	}
+}
+
+// PostStockMovement moves units of a beer in or out of a location,
+// and returns the stock there. Removing more units than stored
+// fails with a conflict, leaving stock unchanged.
+func PostStockMovement(mover StockMover) HandlerWithErr {
+	type fields struct {
+		Delta int `json:"delta"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		location, err := parseLocation(r)
+		if err != nil {
+			return err
+		}
+
+		var movementFields fields
+		if err := json.NewDecoder(r.Body).Decode(&movementFields); err != nil {
+			return err
+		}
+
+		m := burp.StockMovement{BeerID: id, Location: location, Delta: movementFields.Delta}
+		stock, err := mover.MoveStock(r.Context(), &m)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		return json.NewEncoder(w).Encode(stock)
+	}
+}
+
+// PutStockThreshold sets the low-stock threshold of a beer at a
+// location, and returns the stock there.
+func PutStockThreshold(setter StockThresholdSetter) HandlerWithErr {
+	type fields struct {
+		Threshold uint `json:"threshold"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		location, err := parseLocation(r)
+		if err != nil {
+			return err
+		}
+
+		var thresholdFields fields
+		if err := json.NewDecoder(r.Body).Decode(&thresholdFields); err != nil {
+			return err
+		}
+
+		t := burp.StockThreshold{BeerID: id, Location: location, Threshold: thresholdFields.Threshold}
+		stock, err := setter.SetStockThreshold(r.Context(), &t)
+		if err != nil {
+			return err
+		}
+
+		return json.NewEncoder(w).Encode(stock)
+	}
+}
+
+// ListStock returns stock of every beer, filtered by location
+// parameter and, when low parameter is true, below threshold only.
+func ListStock(lister StockLister) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		params := r.URL.Query()
+		q := burp.StockQuery{Location: params.Get("location")}
+
+		if p := params.Get("low"); p != "" {
+			low, err := strconv.ParseBool(p)
+			if err != nil {
+				return apiError{
+					Code:         http.StatusBadRequest,
+					ErrorMessage: fmt.Sprintf("invalid low %q: %s", p, err),
+				}
+			}
+			q.Low = low
+		}
+
+		stock, err := lister.ListStock(r.Context(), q)
+		if err != nil {
+			return err
+		}
+
+		return json.NewEncoder(w).Encode(map[string]any{"items": stock})
+	}
+}
@@ -0,0 +1,93 @@
+package chi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/url"
+)
+
+// parseTag reads a beer tag from URL path.
+func parseTag(r *http.Request) (string, error) {
+	p := chi.URLParam(r, "tag")
+	tag, err := url.PathUnescape(p)
+	if err != nil {
+		return "", apiError{
+			Code:         http.StatusBadRequest,
+			ErrorMessage: fmt.Sprintf("invalid tag %q: %s", p, err),
+		}
+	}
+
+	return tag, nil
+}
+
+// GetBeerTags returns the tags of a beer, sorted.
+func GetBeerTags(selector BeerSelector) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		beer, err := selector.SelectBeer(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		tags := beer.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		return json.NewEncoder(w).Encode(map[string]any{"items": tags})
+	}
+}
+
+// PutBeerTag tags a beer, and returns it. Tagging a
+// beer with one of its tags leaves it unchanged.
+func PutBeerTag(tagger BeerTagger) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		tag, err := parseTag(r)
+		if err != nil {
+			return err
+		}
+
+		beer, err := tagger.TagBeer(r.Context(), id, tag)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(beer.Version))
+		return json.NewEncoder(w).Encode(beer)
+	}
+}
+
+// DeleteBeerTag removes a tag from a beer, and returns it.
+// Removing a tag the beer does not have leaves it unchanged.
+func DeleteBeerTag(untagger BeerUntagger) HandlerWithErr {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseID(r)
+		if err != nil {
+			return err
+		}
+
+		tag, err := parseTag(r)
+		if err != nil {
+			return err
+		}
+
+		beer, err := untagger.UntagBeer(r.Context(), id, tag)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("ETag", etag(beer.Version))
+		return json.NewEncoder(w).Encode(beer)
+	}
+}
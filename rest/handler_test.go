@@ -4,7 +4,9 @@ import (
 	"burp"
 	"burp/burptest"
 	"burp/repo"
+	"burp/rest/chi"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,8 +14,10 @@ import (
 	"github.com/google/uuid"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDeleteBeer(t *testing.T) {
@@ -38,6 +42,107 @@ func TestDeleteBeer(t *testing.T) {
 	}
 }
 
+func TestDeleteBeerTwice(t *testing.T) {
+	beer := burptest.RandBeer()
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+
+	repository.SaveBeer(ctx, beer)
+
+	sendReq(t, http.MethodDelete, endpoint, http.NoBody)
+	response := sendReq(t, http.MethodDelete, endpoint, http.NoBody)
+
+	if response.status != http.StatusNotFound {
+		t.Errorf("DELETE beer already in trash at endpoint %q returned status %d, want %d",
+			endpoint,
+			response.status,
+			http.StatusNotFound,
+		)
+	}
+}
+
+func TestRestoreBeer(t *testing.T) {
+	beer := burptest.RandBeer()
+	beerEndpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+	trashEndpoint := fmt.Sprintf("http://%s/api/v1/trash/beers?name=%s", addr, beer.Name)
+	restoreEndpoint := fmt.Sprintf("http://%s/api/v1/trash/beers/%s/restore", addr, beer.ID.String())
+
+	repository.SaveBeer(ctx, beer)
+	sendReq(t, http.MethodDelete, beerEndpoint, http.NoBody)
+
+	response := sendReq(t, http.MethodGet, trashEndpoint, http.NoBody)
+
+	var page struct {
+		Items []*burp.Beer `json:"items"`
+	}
+	if err := json.Unmarshal(response.body, &page); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a page returned error %s", string(response.body), err)
+	}
+
+	if len(page.Items) != 1 || page.Items[0].ID != beer.ID || page.Items[0].DeletedAt == nil {
+		t.Errorf("GET trash at endpoint %q returned beers %+v, want deleted beer %q", trashEndpoint, page.Items, beer.ID)
+	}
+
+	response = sendReq(t, http.MethodPost, restoreEndpoint, http.NoBody)
+
+	if response.status != http.StatusNoContent {
+		t.Errorf("POST restore at endpoint %q returned status %d, want %d",
+			restoreEndpoint,
+			response.status,
+			http.StatusNoContent,
+		)
+	}
+
+	got, err := repository.SelectBeer(ctx, beer.ID)
+	if err != nil || got.DeletedAt != nil {
+		t.Errorf("Selecting beer from repository after its restore request returned %+v, %v, want restored beer", got, err)
+	}
+
+	response = sendReq(t, http.MethodPost, restoreEndpoint, http.NoBody)
+
+	if response.status != http.StatusNotFound {
+		t.Errorf("POST restore of beer not in trash at endpoint %q returned status %d, want %d",
+			restoreEndpoint,
+			response.status,
+			http.StatusNotFound,
+		)
+	}
+}
+
+func TestPurgeBeer(t *testing.T) {
+	beer := burptest.RandBeer()
+	beerEndpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+	purgeEndpoint := fmt.Sprintf("http://%s/api/v1/trash/beers/%s", addr, beer.ID.String())
+
+	repository.SaveBeer(ctx, beer)
+
+	response := sendReq(t, http.MethodDelete, purgeEndpoint, http.NoBody)
+
+	if response.status != http.StatusNotFound {
+		t.Errorf("DELETE beer not in trash at endpoint %q returned status %d, want %d",
+			purgeEndpoint,
+			response.status,
+			http.StatusNotFound,
+		)
+	}
+
+	sendReq(t, http.MethodDelete, beerEndpoint, http.NoBody)
+	response = sendReq(t, http.MethodDelete, purgeEndpoint, http.NoBody)
+
+	if response.status != http.StatusNoContent {
+		t.Errorf("DELETE beer in trash at endpoint %q returned status %d, want %d",
+			purgeEndpoint,
+			response.status,
+			http.StatusNoContent,
+		)
+	}
+
+	response = sendReq(t, http.MethodPost, purgeEndpoint+"/restore", http.NoBody)
+
+	if response.status != http.StatusNotFound {
+		t.Errorf("POST restore of purged beer returned status %d, want %d", response.status, http.StatusNotFound)
+	}
+}
+
 func TestDeleteBeerWithInvalidID(t *testing.T) {
 	id := burptest.RandString(10)
 	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, id)
@@ -87,6 +192,32 @@ func TestGetBeer(t *testing.T) {
 	}
 }
 
+func TestGetBeerNotModified(t *testing.T) {
+	beer := burptest.RandBeer()
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+
+	repository.SaveBeer(ctx, beer)
+
+	response := sendReq(t, http.MethodGet, endpoint, http.NoBody)
+
+	tag := response.header.Get("ETag")
+	if tag != `"1"` {
+		t.Errorf("GET beer at endpoint %q returned ETag %s, want %s", endpoint, tag, `"1"`)
+	}
+
+	header := http.Header{"If-None-Match": {tag}}
+	response = sendReqWithHeader(t, http.MethodGet, endpoint, http.NoBody, header)
+
+	if response.status != http.StatusNotModified {
+		t.Errorf("GET beer with If-None-Match %s at endpoint %q returned status %d, want %d",
+			tag,
+			endpoint,
+			response.status,
+			http.StatusNotModified,
+		)
+	}
+}
+
 func TestGetBeerWithInvalidID(t *testing.T) {
 	id := burptest.RandString(10)
 	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, id)
@@ -156,10 +287,144 @@ func TestPutBeer(t *testing.T) {
 		)
 	}
 
+	beer.Version = 1
+
 	got, _ := repository.SelectBeer(ctx, beer.ID)
+	if got != nil {
+		beer.CreatedAt, beer.UpdatedAt = got.CreatedAt, got.UpdatedAt
+	}
+
 	if diff := cmp.Diff(beer, got); diff != "" {
 		t.Errorf("RandBeer found in repository with id %q should match from one sent in PUT request, (-want/+got):\n%s", beer.ID, diff)
 	}
+
+	if tag := response.header.Get("ETag"); tag != `"1"` {
+		t.Errorf("PUT beer at endpoint %q returned ETag %s, want %s", endpoint, tag, `"1"`)
+	}
+}
+
+func TestPutBeerIgnoresTimestamps(t *testing.T) {
+	beer := burptest.RandBeer()
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+
+	repository.SaveBeer(ctx, beer)
+	createdAt := beer.CreatedAt
+
+	fields := map[string]any{
+		"id":        beer.ID,
+		"version":   beer.Version,
+		"createdAt": burptest.RandTime(),
+		"updatedAt": -1,
+		"name":      beer.Name,
+		"price":     beer.Price,
+	}
+
+	jsonB, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("Marshalling fields %v returned unexpected error: %s", fields, jsonB)
+	}
+
+	before := time.Now()
+	response := sendReq(t, http.MethodPut, endpoint, bytes.NewReader(jsonB))
+
+	if response.status != http.StatusAccepted {
+		t.Fatalf("PUT beer json %s at endpoint %q returned status %d, want %d, body: %s",
+			string(jsonB),
+			endpoint,
+			response.status,
+			http.StatusAccepted,
+			string(response.body),
+		)
+	}
+
+	got, _ := repository.SelectBeer(ctx, beer.ID)
+
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("PUT beer json %s at endpoint %q changed creation date to %s, want %s", string(jsonB), endpoint, got.CreatedAt, createdAt)
+	}
+
+	if got.UpdatedAt.Before(before) {
+		t.Errorf("PUT beer json %s at endpoint %q set update date to %s, want server time", string(jsonB), endpoint, got.UpdatedAt)
+	}
+}
+
+func TestPutBeerWithStaleVersion(t *testing.T) {
+	beer := burptest.RandBeer()
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+
+	repository.SaveBeer(ctx, beer)
+	repository.SaveBeer(ctx, beer)
+
+	stale := *beer
+	stale.Version = 1
+	jsonB, err := json.Marshal(stale)
+	if err != nil {
+		t.Fatalf("Marshalling beer %+v returned unexpected error: %s", stale, jsonB)
+	}
+
+	response := sendReq(t, http.MethodPut, endpoint, bytes.NewReader(jsonB))
+
+	if response.status != http.StatusConflict {
+		t.Errorf("PUT beer json %s at endpoint %q returned status %d, want %d, body: %s",
+			string(jsonB),
+			endpoint,
+			response.status,
+			http.StatusConflict,
+			string(response.body),
+		)
+	}
+
+	want := burp.ErrVersionConflict.Error()
+	if !strings.Contains(string(response.body), want) {
+		t.Errorf(
+			"PUT beer json %s\nat endpoint %q\nreturned body: %s\nwant body: %s",
+			string(jsonB),
+			endpoint,
+			string(response.body),
+			want,
+		)
+	}
+}
+
+func TestPutBeerWithIfMatch(t *testing.T) {
+	beer := burptest.RandBeer()
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+
+	repository.SaveBeer(ctx, beer)
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    int
+	}{
+		{name: "Stale", ifMatch: `"0"`, want: http.StatusPreconditionFailed},
+		{name: "Current", ifMatch: `"1"`, want: http.StatusAccepted},
+		{name: "Corrupted", ifMatch: "one", want: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			update := *beer
+			update.Version = 0
+			jsonB, err := json.Marshal(update)
+			if err != nil {
+				t.Fatalf("Marshalling beer %+v returned unexpected error: %s", update, jsonB)
+			}
+
+			header := http.Header{"If-Match": {test.ifMatch}}
+			response := sendReqWithHeader(t, http.MethodPut, endpoint, bytes.NewReader(jsonB), header)
+
+			if response.status != test.want {
+				t.Errorf("PUT beer with If-Match %s at endpoint %q returned status %d, want %d, body: %s",
+					test.ifMatch,
+					endpoint,
+					response.status,
+					test.want,
+					string(response.body),
+				)
+			}
+		})
+	}
 }
 
 func TestPutBeerWithMismatchingID(t *testing.T) {
@@ -224,34 +489,6 @@ func TestPutBeerWithInvalidFields(t *testing.T) {
 
 			want: burp.ErrBeerNameTooLong.Error(),
 		},
-		{
-			name:  "CreatedAtEmpty",
-			key:   "createdAt",
-			value: nil,
-
-			want: burp.ErrBeerCreateDateMissing.Error(),
-		},
-		{
-			name:  "CreatedAtCorrupted",
-			key:   "createdAt",
-			value: -1,
-
-			want: "corrupted time value: -1",
-		},
-		{
-			name:  "UpdatedAtEmpty",
-			key:   "updatedAt",
-			value: nil,
-
-			want: burp.ErrBeerUpdateDateMissing.Error(),
-		},
-		{
-			name:  "UpdatedAtCorrupted",
-			key:   "updatedAt",
-			value: -1,
-
-			want: "corrupted time value: -1",
-		},
 		{
 			name: "NameEmpty",
 
@@ -301,6 +538,22 @@ func TestPutBeerWithInvalidFields(t *testing.T) {
 
 			want: "corrupted price.amount type",
 		},
+		{
+			name: "ABVOutOfRange",
+
+			key:   "abv",
+			value: 120.5,
+
+			want: burp.ErrBeerABVOutOfRange.Error(),
+		},
+		{
+			name: "DescriptionTooLong",
+
+			key:   "description",
+			value: burptest.RandString(burp.MaxDescriptionLength + 1),
+
+			want: burp.ErrBeerDescriptionTooLong.Error(),
+		},
 	}
 
 	for _, test := range tests {
@@ -358,6 +611,13 @@ func TestPostBeer(t *testing.T) {
 			"currency": beer.Price.Currency,
 			"amount":   beer.Price.Amount,
 		},
+		"brewery":     beer.Brewery,
+		"style":       beer.Style,
+		"abv":         beer.ABV,
+		"ibu":         beer.IBU,
+		"volume":      beer.Volume,
+		"description": beer.Description,
+		"tags":        beer.Tags,
 	}
 
 	jsonB, err := json.Marshal(fields)
@@ -500,29 +760,1160 @@ func TestPostBeerWithInvalidFields(t *testing.T) {
 	}
 }
 
-type resp struct {
-	status int
-	body   []byte
-}
+func TestListBeers(t *testing.T) {
+	prefix := burptest.RandString(10)
+	beers := []*burp.Beer{burptest.RandBeer(), burptest.RandBeer(), burptest.RandBeer()}
+	for i, beer := range beers {
+		beer.Name = prefix + string(rune('c'-i))
+		repository.SaveBeer(ctx, beer)
+	}
 
-func sendReq(t *testing.T, method string, url string, reader io.Reader) resp {
-	r, err := http.NewRequest(method, url, reader)
-	if err != nil {
-		t.Fatalf("creating an HTTP request with method %q and URL %q failed: %s", method, url, err)
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers?name=%s&sort=-name&limit=2", addr, strings.ToLower(prefix))
+
+	var got []*burp.Beer
+	for endpoint != "" {
+		response := sendReq(t, http.MethodGet, endpoint, http.NoBody)
+
+		if response.status != http.StatusOK {
+			t.Fatalf("GET beers at endpoint %q returned status %d, want %d, body: %s",
+				endpoint,
+				response.status,
+				http.StatusOK,
+				string(response.body),
+			)
+		}
+
+		var page struct {
+			Items []*burp.Beer `json:"items"`
+			Next  string       `json:"next"`
+		}
+		if err := json.Unmarshal(response.body, &page); err != nil {
+			t.Fatalf("Unmarshalling response body %s into a page returned error %s", string(response.body), err)
+		}
+
+		got = append(got, page.Items...)
+
+		endpoint = ""
+		if page.Next != "" {
+			endpoint = fmt.Sprintf("http://%s/api/v1/beers?name=%s&sort=-name&limit=2&cursor=%s", addr, prefix, page.Next)
+		}
 	}
 
-	response, err := client.Do(r)
-	if err != nil {
-		t.Fatalf("sending request with Do() failed: %s", err)
+	if diff := cmp.Diff(beers, got); diff != "" {
+		t.Errorf("Beers listed page by page should match the ones saved in repository, (-want/+got):\n%s", diff)
 	}
+}
 
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		t.Fatalf("reading http.Response.Body failed: %s", err)
+func TestListBeersWithInvalidParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "SortNotSupported",
+			query: "sort=color",
+			want:  burp.ErrSortNotSupported.Error(),
+		},
+		{
+			name:  "LimitCorrupted",
+			query: "limit=ten",
+			want:  "invalid limit \\\"ten\\\"",
+		},
+		{
+			name:  "LimitTooHigh",
+			query: fmt.Sprintf("limit=%d", burp.MaxPageSize+1),
+			want:  burp.ErrPageSizeOutOfRange.Error(),
+		},
+		{
+			name:  "MinPriceCorrupted",
+			query: "currency=Euro&minPrice=-1",
+			want:  "invalid minPrice \\\"-1\\\"",
+		},
+		{
+			name:  "PriceBoundsCorrupted",
+			query: "currency=EUR&maxPrice=ten&minPrice=-1",
+			want:  "invalid minPrice \\\"-1\\\"",
+		},
+		{
+			name:  "PriceRangeWithoutCurrency",
+			query: "maxPrice=10",
+			want:  burp.ErrPriceRangeWithoutCurrency.Error(),
+		},
+		{
+			name:  "PriceSortWithoutCurrency",
+			query: "sort=price",
+			want:  burp.ErrPriceSortWithoutCurrency.Error(),
+		},
+		{
+			name:  "CursorCorrupted",
+			query: "cursor=" + burptest.RandString(10),
+			want:  burp.ErrCursorInvalid.Error(),
+		},
 	}
 
-	return resp{
-		status: response.StatusCode,
-		body:   body,
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			endpoint := fmt.Sprintf("http://%s/api/v1/beers?%s", addr, test.query)
+
+			response := sendReq(t, http.MethodGet, endpoint, http.NoBody)
+
+			if response.status != http.StatusBadRequest {
+				t.Errorf("GET beers at endpoint %q returned status %d, want %d",
+					endpoint,
+					response.status,
+					http.StatusBadRequest,
+				)
+			}
+
+			if !strings.Contains(string(response.body), test.want) {
+				t.Errorf(
+					"GET beers at endpoint %q\nreturned body: %s\nwant body: %s",
+					endpoint,
+					string(response.body),
+					test.want,
+				)
+			}
+		})
+	}
+}
+
+func TestSearchBeers(t *testing.T) {
+	word := burptest.RandString(8)
+	exact, misspelled := burptest.RandBeer(), burptest.RandBeer()
+	exact.Name = word + " ale"
+	misspelled.Name = word[:7] + "x"
+	repository.SaveBeer(ctx, misspelled)
+	repository.SaveBeer(ctx, exact)
+
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/search?q=%s", addr, word)
+
+	response := sendReq(t, http.MethodGet, endpoint, http.NoBody)
+
+	if response.status != http.StatusOK {
+		t.Fatalf("GET beers search at endpoint %q returned status %d, want %d, body: %s",
+			endpoint,
+			response.status,
+			http.StatusOK,
+			string(response.body),
+		)
+	}
+
+	var results struct {
+		Items []*burp.BeerMatch `json:"items"`
+	}
+	if err := json.Unmarshal(response.body, &results); err != nil {
+		t.Fatalf("Unmarshalling response body %s into search results returned error %s", string(response.body), err)
+	}
+
+	var got []*burp.Beer
+	for _, match := range results.Items {
+		got = append(got, match.Beer)
+	}
+
+	if diff := cmp.Diff([]*burp.Beer{exact, misspelled}, got); diff != "" {
+		t.Errorf("Beers found searching %q should be ranked best matches first, (-want/+got):\n%s", word, diff)
+	}
+}
+
+func TestSearchBeersWithoutText(t *testing.T) {
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/search", addr)
+
+	response := sendReq(t, http.MethodGet, endpoint, http.NoBody)
+
+	if response.status != http.StatusBadRequest {
+		t.Errorf("GET beers search at endpoint %q returned status %d, want %d",
+			endpoint,
+			response.status,
+			http.StatusBadRequest,
+		)
+	}
+
+	want := burp.ErrSearchTextMissing.Error()
+	if !strings.Contains(string(response.body), want) {
+		t.Errorf(
+			"GET beers search at endpoint %q\nreturned body: %s\nwant body: %s",
+			endpoint,
+			string(response.body),
+			want,
+		)
+	}
+}
+
+func TestListBeersByPrice(t *testing.T) {
+	prefix := burptest.RandString(10)
+	amounts := []uint{300, 100, 200, 400}
+	var beers []*burp.Beer
+	for i, amount := range amounts {
+		beer := burptest.RandBeer()
+		beer.Name = prefix + string(rune('a'+i))
+		beer.Price = burp.Price{Currency: burp.EUR, Amount: amount}
+		repository.SaveBeer(ctx, beer)
+		beers = append(beers, beer)
+	}
+
+	dollarBeer := burptest.RandBeer()
+	dollarBeer.Name = prefix + "z"
+	dollarBeer.Price = burp.Price{Currency: burp.USD, Amount: 200}
+	repository.SaveBeer(ctx, dollarBeer)
+
+	endpoint := fmt.Sprintf(
+		"http://%s/api/v1/beers?name=%s&currency=%s&minPrice=150&maxPrice=400&sort=price",
+		addr, prefix, burp.EUR,
+	)
+
+	response := sendReq(t, http.MethodGet, endpoint, http.NoBody)
+
+	if response.status != http.StatusOK {
+		t.Fatalf("GET beers at endpoint %q returned status %d, want %d, body: %s",
+			endpoint,
+			response.status,
+			http.StatusOK,
+			string(response.body),
+		)
+	}
+
+	var page struct {
+		Items []*burp.Beer `json:"items"`
+	}
+	if err := json.Unmarshal(response.body, &page); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a page returned error %s", string(response.body), err)
+	}
+
+	want := []*burp.Beer{beers[2], beers[0], beers[3]}
+	if diff := cmp.Diff(want, page.Items); diff != "" {
+		t.Errorf("Beers listed at endpoint %q should be filtered and sorted by price, (-want/+got):\n%s", endpoint, diff)
+	}
+}
+
+type resp struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func sendReq(t *testing.T, method string, url string, reader io.Reader) resp {
+	return sendReqWithHeader(t, method, url, reader, nil)
+}
+
+func sendReqWithHeader(t *testing.T, method string, url string, reader io.Reader, header http.Header) resp {
+	r, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("creating an HTTP request with method %q and URL %q failed: %s", method, url, err)
+	}
+
+	for key, values := range header {
+		r.Header[key] = values
+	}
+
+	response, err := client.Do(r)
+	if err != nil {
+		t.Fatalf("sending request with Do() failed: %s", err)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("reading http.Response.Body failed: %s", err)
+	}
+
+	return resp{
+		status: response.StatusCode,
+		header: response.Header,
+		body:   body,
+	}
+}
+
+func TestPatchBeer(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		patch       func(beer *burp.Beer) string
+		want        func(beer *burp.Beer)
+	}{
+		{
+			name:        "MergePatch",
+			contentType: "application/merge-patch+json",
+			patch: func(beer *burp.Beer) string {
+				return `{"name": "Patched", "price": {"amount": 42}, "createdAt": null}`
+			},
+			want: func(beer *burp.Beer) {
+				beer.Name = "Patched"
+				beer.Price.Amount = 42
+			},
+		},
+		{
+			name:        "JSONPatch",
+			contentType: "application/json-patch+json",
+			patch: func(beer *burp.Beer) string {
+				return fmt.Sprintf(`[
+					{"op": "test", "path": "/name", "value": %q},
+					{"op": "replace", "path": "/price/currency", "value": "Dollar"},
+					{"op": "copy", "from": "/price/currency", "path": "/name"}
+				]`, beer.Name)
+			},
+			want: func(beer *burp.Beer) {
+				beer.Name = "Dollar"
+				beer.Price.Currency = burp.USD
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			beer := burptest.RandBeer()
+			endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+
+			repository.SaveBeer(ctx, beer)
+			want := *beer
+			test.want(&want)
+			want.Version++
+
+			header := http.Header{"Content-Type": {test.contentType}}
+			patch := test.patch(beer)
+			response := sendReqWithHeader(t, http.MethodPatch, endpoint, strings.NewReader(patch), header)
+
+			if response.status != http.StatusOK {
+				t.Fatalf("PATCH beer with %s at endpoint %q returned status %d, want %d, body: %s",
+					patch,
+					endpoint,
+					response.status,
+					http.StatusOK,
+					string(response.body),
+				)
+			}
+
+			got, _ := repository.SelectBeer(ctx, beer.ID)
+			if !got.UpdatedAt.After(beer.UpdatedAt) {
+				t.Errorf("PATCH beer at endpoint %q did not bump update date %s", endpoint, got.UpdatedAt)
+			}
+
+			want.UpdatedAt = got.UpdatedAt
+			if diff := cmp.Diff(&want, got); diff != "" {
+				t.Errorf("RandBeer found in repository with id %q should be patched with %s, (-want/+got):\n%s", beer.ID, patch, diff)
+			}
+		})
+	}
+}
+
+func TestPatchBeerWithInvalidPatch(t *testing.T) {
+	beer := burptest.RandBeer()
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID.String())
+
+	repository.SaveBeer(ctx, beer)
+
+	tests := []struct {
+		name        string
+		contentType string
+		ifMatch     string
+		patch       string
+
+		status int
+		want   string
+	}{
+		{
+			name:        "ContentTypeUnsupported",
+			contentType: "application/json",
+			patch:       `{"name": "Patched"}`,
+			status:      http.StatusUnsupportedMediaType,
+			want:        "content type must be",
+		},
+		{
+			name:        "NameTooLong",
+			contentType: "application/merge-patch+json",
+			patch:       fmt.Sprintf(`{"name": %q}`, burptest.RandString(16)),
+			status:      http.StatusBadRequest,
+			want:        burp.ErrBeerNameTooLong.Error(),
+		},
+		{
+			name:        "NameCorrupted",
+			contentType: "application/merge-patch+json",
+			patch:       `{"name": 1}`,
+			status:      http.StatusBadRequest,
+			want:        "corrupted name type",
+		},
+		{
+			name:        "PatchCorrupted",
+			contentType: "application/json-patch+json",
+			patch:       `{"op": "remove"}`,
+			status:      http.StatusBadRequest,
+			want:        "invalid json patch",
+		},
+		{
+			name:        "PathNotFound",
+			contentType: "application/json-patch+json",
+			patch:       `[{"op": "remove", "path": "/color"}]`,
+			status:      http.StatusUnprocessableEntity,
+			want:        "path not found",
+		},
+		{
+			name:        "TestFailed",
+			contentType: "application/json-patch+json",
+			patch:       `[{"op": "test", "path": "/name", "value": ""}]`,
+			status:      http.StatusUnprocessableEntity,
+			want:        "test failed",
+		},
+		{
+			name:        "StaleIfMatch",
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"0"`,
+			patch:       `{"name": "Patched"}`,
+			status:      http.StatusPreconditionFailed,
+			want:        burp.ErrVersionConflict.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{"Content-Type": {test.contentType}}
+			if test.ifMatch != "" {
+				header.Set("If-Match", test.ifMatch)
+			}
+
+			response := sendReqWithHeader(t, http.MethodPatch, endpoint, strings.NewReader(test.patch), header)
+
+			if response.status != test.status {
+				t.Errorf("PATCH beer with %s at endpoint %q returned status %d, want %d, body: %s",
+					test.patch,
+					endpoint,
+					response.status,
+					test.status,
+					string(response.body),
+				)
+			}
+
+			if !strings.Contains(string(response.body), test.want) {
+				t.Errorf(
+					"PATCH beer with %s\nat endpoint %q\nreturned body: %s\nwant body: %s",
+					test.patch,
+					endpoint,
+					string(response.body),
+					test.want,
+				)
+			}
+		})
+	}
+}
+
+func TestGetBeerHistory(t *testing.T) {
+	endpoint := "http://" + addr + "/api/v1/beers"
+	header := http.Header{"X-Actor": {"brewmaster"}}
+	fields := `{"name": "History", "price": {"currency": "Euro", "amount": 100}}`
+
+	response := sendReqWithHeader(t, http.MethodPost, endpoint, strings.NewReader(fields), header)
+
+	var beer burp.Beer
+	if err := json.Unmarshal(response.body, &beer); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a burp.Beer returned error %s", string(response.body), err)
+	}
+
+	beerEndpoint := fmt.Sprintf("%s/%s", endpoint, beer.ID)
+	sendReqWithHeader(t, http.MethodDelete, beerEndpoint, http.NoBody, header)
+
+	historyEndpoint := beerEndpoint + "/history"
+	response = sendReq(t, http.MethodGet, historyEndpoint, http.NoBody)
+
+	if response.status != http.StatusOK {
+		t.Fatalf("GET beer history at endpoint %q returned status %d, want %d, body: %s",
+			historyEndpoint,
+			response.status,
+			http.StatusOK,
+			string(response.body),
+		)
+	}
+
+	var history struct {
+		Items []*burp.BeerEvent `json:"items"`
+	}
+	if err := json.Unmarshal(response.body, &history); err != nil {
+		t.Fatalf("Unmarshalling response body %s into beer history returned error %s", string(response.body), err)
+	}
+
+	var kinds []burp.BeerEventKind
+	for _, e := range history.Items {
+		kinds = append(kinds, e.Kind)
+		if e.Actor != "brewmaster" {
+			t.Errorf("GET beer history at endpoint %q returned event %+v, want actor %q", historyEndpoint, e, "brewmaster")
+		}
+	}
+
+	want := []burp.BeerEventKind{burp.BeerCreated, burp.BeerDeleted}
+	if diff := cmp.Diff(want, kinds); diff != "" {
+		t.Errorf("GET beer history at endpoint %q returned unexpected events, (-want/+got):\n%s", historyEndpoint, diff)
+	}
+
+	if diff := cmp.Diff(&beer, history.Items[1].Before); diff != "" {
+		t.Errorf("Delete event should snapshot beer before its deletion, (-want/+got):\n%s", diff)
+	}
+}
+
+func TestBeerPrices(t *testing.T) {
+	endpoint := "http://" + addr + "/api/v1/beers"
+	fields := `{"name": "Priced", "price": {"currency": "Euro", "amount": 100}}`
+
+	response := sendReq(t, http.MethodPost, endpoint, strings.NewReader(fields))
+
+	var beer burp.Beer
+	if err := json.Unmarshal(response.body, &beer); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a burp.Beer returned error %s", string(response.body), err)
+	}
+
+	pricesEndpoint := fmt.Sprintf("%s/%s/prices", endpoint, beer.ID)
+	from := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	change := fmt.Sprintf(`{"price": {"currency": "Euro", "amount": 150}, "from": %q}`, from.Format(time.RFC3339))
+
+	response = sendReq(t, http.MethodPost, pricesEndpoint, strings.NewReader(change))
+	if response.status != http.StatusCreated {
+		t.Fatalf("POST price change at endpoint %q returned status %d, want %d, body: %s",
+			pricesEndpoint,
+			response.status,
+			http.StatusCreated,
+			string(response.body),
+		)
+	}
+
+	past := fmt.Sprintf(`{"price": {"currency": "Euro", "amount": 150}, "from": %q}`, "2000-01-01T00:00:00Z")
+	response = sendReq(t, http.MethodPost, pricesEndpoint, strings.NewReader(past))
+	if response.status != http.StatusBadRequest {
+		t.Errorf("POST past price change at endpoint %q returned status %d, want %d", pricesEndpoint, response.status, http.StatusBadRequest)
+	}
+
+	response = sendReq(t, http.MethodGet, pricesEndpoint, http.NoBody)
+
+	var history struct {
+		Items []*burp.PricePeriod `json:"items"`
+	}
+	if err := json.Unmarshal(response.body, &history); err != nil {
+		t.Fatalf("Unmarshalling response body %s into price history returned error %s", string(response.body), err)
+	}
+
+	if len(history.Items) != 2 || history.Items[0].Price != beer.Price || history.Items[1].Price.Amount != 150 {
+		t.Fatalf("GET price history at endpoint %q returned unexpected periods %s", pricesEndpoint, string(response.body))
+	}
+
+	tests := []struct {
+		at   time.Time
+		want uint
+	}{
+		{at: from.Add(-time.Second), want: 100},
+		{at: from, want: 150},
+	}
+
+	for _, test := range tests {
+		priceEndpoint := fmt.Sprintf("%s/%s/price?at=%s", endpoint, beer.ID, test.at.Format(time.RFC3339))
+		response = sendReq(t, http.MethodGet, priceEndpoint, http.NoBody)
+
+		var price burp.Price
+		if err := json.Unmarshal(response.body, &price); err != nil {
+			t.Fatalf("Unmarshalling response body %s into a burp.Price returned error %s", string(response.body), err)
+		}
+
+		if price.Amount != test.want {
+			t.Errorf("GET price at endpoint %q returned amount %d, want %d", priceEndpoint, price.Amount, test.want)
+		}
+	}
+}
+
+func TestGetPriceWithoutPriceHistory(t *testing.T) {
+	// saved straight to the repo, without recording its price
+	beer := burptest.RandBeer()
+	repository.SaveBeer(ctx, beer)
+
+	endpoint := fmt.Sprintf("http://%s/api/v1/beers/%s/price", addr, beer.ID)
+	response := sendReq(t, http.MethodGet, endpoint, http.NoBody)
+	if response.status != http.StatusOK {
+		t.Fatalf("GET price at endpoint %q returned status %d, want %d, body: %s", endpoint, response.status, http.StatusOK, string(response.body))
+	}
+
+	var price burp.Price
+	if err := json.Unmarshal(response.body, &price); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a burp.Price returned error %s", string(response.body), err)
+	}
+
+	if price != beer.Price {
+		t.Errorf("GET price at endpoint %q returned price %+v, want current price %+v", endpoint, price, beer.Price)
+	}
+}
+
+func TestConvertPrices(t *testing.T) {
+	endpoint := "http://" + addr + "/api/v1/beers"
+	fields := `{"name": "Converted", "price": {"currency": "EUR", "amount": 350}}`
+
+	response := sendReq(t, http.MethodPost, endpoint, strings.NewReader(fields))
+
+	var beer burp.Beer
+	if err := json.Unmarshal(response.body, &beer); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a burp.Beer returned error %s", string(response.body), err)
+	}
+
+	beerEndpoint := fmt.Sprintf("%s/%s", endpoint, beer.ID)
+	tests := []struct {
+		url    string
+		status int
+		want   burp.Price
+	}{
+		{url: beerEndpoint + "?convert=USD", status: http.StatusOK, want: burp.Price{Currency: burp.USD, Amount: 385}},
+		{url: beerEndpoint + "?convert=jpy", status: http.StatusOK, want: burp.Price{Currency: "JPY", Amount: 560}},
+		{url: beerEndpoint + "?convert=Euro", status: http.StatusOK, want: beer.Price},
+		{url: beerEndpoint + "?convert=GBP", status: http.StatusBadRequest},
+		{url: beerEndpoint + "?convert=Yen", status: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		response := sendReq(t, http.MethodGet, test.url, http.NoBody)
+		if response.status != test.status {
+			t.Errorf("GET %q returned status %d, want %d, body: %s", test.url, response.status, test.status, string(response.body))
+			continue
+		}
+
+		if test.status != http.StatusOK {
+			continue
+		}
+
+		var got burp.Beer
+		if err := json.Unmarshal(response.body, &got); err != nil {
+			t.Fatalf("Unmarshalling response body %s into a burp.Beer returned error %s", string(response.body), err)
+		}
+
+		if got.Price != test.want {
+			t.Errorf("GET %q returned price %+v, want %+v", test.url, got.Price, test.want)
+		}
+	}
+
+	priceEndpoint := beerEndpoint + "/price?convert=USD"
+	response = sendReq(t, http.MethodGet, priceEndpoint, http.NoBody)
+
+	var price burp.Price
+	if err := json.Unmarshal(response.body, &price); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a burp.Price returned error %s", string(response.body), err)
+	}
+
+	if want := (burp.Price{Currency: burp.USD, Amount: 385}); price != want {
+		t.Errorf("GET %q returned price %+v, want %+v", priceEndpoint, price, want)
+	}
+
+	listEndpoint := endpoint + "?name=Converted&currency=EUR&convert=USD"
+	response = sendReq(t, http.MethodGet, listEndpoint, http.NoBody)
+
+	var page burp.BeerPage
+	if err := json.Unmarshal(response.body, &page); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a burp.BeerPage returned error %s", string(response.body), err)
+	}
+
+	if len(page.Beers) == 0 {
+		t.Fatalf("GET %q returned no beer, want the converted one", listEndpoint)
+	}
+
+	for _, got := range page.Beers {
+		if want := (burp.Price{Currency: burp.USD, Amount: 385}); got.Price != want {
+			t.Errorf("GET %q returned beer %q priced %+v, want %+v", listEndpoint, got.Name, got.Price, want)
+		}
+	}
+}
+
+func TestImportExportBeers(t *testing.T) {
+	endpoint := "http://" + addr + "/api/v1/beers"
+	prefix := "Bulk" + burptest.RandString(5)
+	csv := fmt.Sprintf("name,currency,amount\n%[1]s A,Euro,100\n%[1]s B,Euro,oops\n%[1]s C,Dollar,300\n", prefix)
+	header := http.Header{"Content-Type": {"text/csv"}}
+
+	response := sendReqWithHeader(t, http.MethodPost, endpoint+"/import?mode=all", strings.NewReader(csv), header)
+	if response.status != http.StatusUnprocessableEntity {
+		t.Errorf("POST import of all beers with an invalid row returned status %d, want %d, body: %s",
+			response.status,
+			http.StatusUnprocessableEntity,
+			string(response.body),
+		)
+	}
+
+	response = sendReqWithHeader(t, http.MethodPost, endpoint+"/import", strings.NewReader(csv), header)
+	if response.status != http.StatusOK {
+		t.Fatalf("POST import returned status %d, want %d, body: %s", response.status, http.StatusOK, string(response.body))
+	}
+
+	want := `{"imported":2,"errors":[{"row":3,"error":"invalid amount \"oops\""}]}`
+	if diff := cmp.Diff(want, strings.TrimSpace(string(response.body))); diff != "" {
+		t.Errorf("POST import returned unexpected report, (-want/+got):\n%s", diff)
+	}
+
+	response = sendReqWithHeader(t, http.MethodPost, endpoint+"/import", strings.NewReader(csv), http.Header{"Content-Type": {"application/xml"}})
+	if response.status != http.StatusUnsupportedMediaType {
+		t.Errorf("POST import of xml returned status %d, want %d", response.status, http.StatusUnsupportedMediaType)
+	}
+
+	exportEndpoint := endpoint + "/export?format=ndjson&name=" + prefix
+	response = sendReq(t, http.MethodGet, exportEndpoint, http.NoBody)
+	if got := response.header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("GET export at endpoint %q returned content type %q, want %q", exportEndpoint, got, "application/x-ndjson")
+	}
+
+	var names []string
+	dec := json.NewDecoder(bytes.NewReader(response.body))
+	for dec.More() {
+		var beer burp.Beer
+		if err := dec.Decode(&beer); err != nil {
+			t.Fatalf("Decoding exported beer from %s returned error %s", string(response.body), err)
+		}
+		names = append(names, beer.Name)
+	}
+
+	if diff := cmp.Diff([]string{prefix + " A", prefix + " C"}, names); diff != "" {
+		t.Errorf("GET export at endpoint %q returned unexpected beers, (-want/+got):\n%s", exportEndpoint, diff)
+	}
+
+	response = sendReq(t, http.MethodGet, endpoint+"/export?format=xml", http.NoBody)
+	if response.status != http.StatusBadRequest {
+		t.Errorf("GET export of xml returned status %d, want %d", response.status, http.StatusBadRequest)
+	}
+}
+
+func TestPostBatch(t *testing.T) {
+	endpoint := "http://" + addr + "/api/v1/beers"
+	beer := burptest.RandBeer()
+	if err := repository.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	batch := fmt.Sprintf(`{"operations": [
+		{"op": "create", "name": "Batched", "price": {"currency": "Euro", "amount": 100}},
+		{"op": "create", "name": "Batched", "price": {"currency": "Yen", "amount": 100}},
+		{"op": "update", "id": %[1]q, "version": 1, "name": "Updated", "price": {"currency": "Euro", "amount": 100}},
+		{"op": "update", "id": %[1]q, "version": 1, "name": "Stale", "price": {"currency": "Euro", "amount": 100}},
+		{"op": "delete", "id": %[1]q},
+		{"op": "delete", "id": %[1]q}
+	]}`, beer.ID)
+
+	tests := []struct {
+		description string
+		body        string
+		want        []int
+	}{
+		{
+			description: "independent operations",
+			body:        batch,
+			want: []int{
+				http.StatusCreated,
+				http.StatusBadRequest,
+				http.StatusOK,
+				http.StatusConflict,
+				http.StatusNoContent,
+				http.StatusNotFound,
+			},
+		},
+		{
+			description: "atomic operations",
+			body: `{"atomic": true, "operations": [
+				{"op": "create", "name": "Batched", "price": {"currency": "Euro", "amount": 100}},
+				{"op": "create", "name": "Batched", "price": {"currency": "Yen", "amount": 100}}
+			]}`,
+			want: []int{http.StatusFailedDependency, http.StatusBadRequest},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			response := sendReq(t, http.MethodPost, endpoint+"/batch", strings.NewReader(test.body))
+			if response.status != http.StatusOK {
+				t.Fatalf("POST batch returned status %d, want %d, body: %s", response.status, http.StatusOK, string(response.body))
+			}
+
+			var results struct {
+				Items []struct {
+					Status int        `json:"status"`
+					Beer   *burp.Beer `json:"beer"`
+				} `json:"items"`
+			}
+			if err := json.Unmarshal(response.body, &results); err != nil {
+				t.Fatalf("Unmarshalling response body %s into batch results returned error %s", string(response.body), err)
+			}
+
+			var got []int
+			for _, item := range results.Items {
+				got = append(got, item.Status)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("POST batch of %s returned unexpected statuses, (-want/+got):\n%s", test.description, diff)
+			}
+		})
+	}
+
+	response := sendReq(t, http.MethodPost, endpoint+"/batch", strings.NewReader(`{"operations": []}`))
+	if response.status != http.StatusBadRequest {
+		t.Errorf("POST empty batch returned status %d, want %d", response.status, http.StatusBadRequest)
+	}
+}
+
+func TestBreweries(t *testing.T) {
+	endpoint := "http://" + addr + "/api/v1/breweries"
+	name := burptest.RandString(12)
+	fields := fmt.Sprintf(`{"name": %q, "country": "Belgium", "city": "Malle"}`, name)
+
+	response := sendReq(t, http.MethodPost, endpoint, strings.NewReader(fields))
+	if response.status != http.StatusCreated {
+		t.Fatalf("POST %q returned status %d, want %d, body: %s", endpoint, response.status, http.StatusCreated, string(response.body))
+	}
+
+	var brewery burp.Brewery
+	if err := json.Unmarshal(response.body, &brewery); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a burp.Brewery returned error %s", string(response.body), err)
+	}
+
+	breweryEndpoint := fmt.Sprintf("%s/%s", endpoint, brewery.ID)
+	beerFields := fmt.Sprintf(`{"name": "Tripel", "price": {"currency": "EUR", "amount": 390}, "breweryId": %q}`, brewery.ID)
+	response = sendReq(t, http.MethodPost, "http://"+addr+"/api/v1/beers", strings.NewReader(beerFields))
+	if response.status != http.StatusCreated {
+		t.Fatalf("POST beer of brewery returned status %d, want %d, body: %s", response.status, http.StatusCreated, string(response.body))
+	}
+
+	var beer burp.Beer
+	if err := json.Unmarshal(response.body, &beer); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a burp.Beer returned error %s", string(response.body), err)
+	}
+
+	tests := []struct {
+		method string
+		url    string
+		body   string
+		status int
+	}{
+		{method: http.MethodGet, url: breweryEndpoint, status: http.StatusOK},
+		{method: http.MethodGet, url: endpoint + "?name=" + name, status: http.StatusOK},
+		{method: http.MethodGet, url: breweryEndpoint + "/beers", status: http.StatusOK},
+		{method: http.MethodGet, url: fmt.Sprintf("%s/%s", endpoint, uuid.New()), status: http.StatusNotFound},
+		{method: http.MethodGet, url: fmt.Sprintf("%s/%s/beers", endpoint, uuid.New()), status: http.StatusNotFound},
+		{method: http.MethodPost, url: endpoint, body: `{"name": ""}`, status: http.StatusBadRequest},
+		{method: http.MethodPut, url: breweryEndpoint, body: `{"version": 1, "name": "Westmalle"}`, status: http.StatusAccepted},
+		{method: http.MethodPut, url: breweryEndpoint, body: `{"version": 1, "name": "Westmalle"}`, status: http.StatusConflict},
+		{method: http.MethodDelete, url: breweryEndpoint, status: http.StatusConflict},
+		{
+			method: http.MethodPost,
+			url:    "http://" + addr + "/api/v1/beers",
+			body:   fmt.Sprintf(`{"name": "Orphan", "price": {"currency": "EUR", "amount": 390}, "breweryId": %q}`, uuid.New()),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		response := sendReq(t, test.method, test.url, strings.NewReader(test.body))
+		if response.status != test.status {
+			t.Errorf("%s %q returned status %d, want %d, body: %s", test.method, test.url, response.status, test.status, string(response.body))
+		}
+	}
+
+	response = sendReq(t, http.MethodGet, breweryEndpoint+"/beers", http.NoBody)
+
+	var page struct {
+		Items []*burp.Beer `json:"items"`
+	}
+	if err := json.Unmarshal(response.body, &page); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a page returned error %s", string(response.body), err)
+	}
+
+	if len(page.Items) != 1 || page.Items[0].ID != beer.ID {
+		t.Errorf("GET %q returned beers %+v, want beer %q only", breweryEndpoint+"/beers", page.Items, beer.ID)
+	}
+
+	beerEndpoint := fmt.Sprintf("http://%s/api/v1/beers/%s", addr, beer.ID)
+	sendReq(t, http.MethodDelete, beerEndpoint, http.NoBody)
+	sendReq(t, http.MethodDelete, fmt.Sprintf("http://%s/api/v1/trash/beers/%s", addr, beer.ID), http.NoBody)
+
+	response = sendReq(t, http.MethodDelete, breweryEndpoint, http.NoBody)
+	if response.status != http.StatusNoContent {
+		t.Errorf("DELETE %q without beers returned status %d, want %d, body: %s", breweryEndpoint, response.status, http.StatusNoContent, string(response.body))
+	}
+}
+
+func TestBeerTags(t *testing.T) {
+	beer := burptest.RandBeer()
+	beer.Tags = nil
+	repository.SaveBeer(ctx, beer)
+
+	tagsEndpoint := fmt.Sprintf("http://%s/api/v1/beers/%s/tags", addr, beer.ID)
+
+	tests := []struct {
+		method string
+		url    string
+		status int
+		want   []string
+	}{
+		{method: http.MethodPut, url: tagsEndpoint + "/Seasonal", status: http.StatusOK, want: []string{"seasonal"}},
+		{method: http.MethodPut, url: tagsEndpoint + "/low%20alcohol", status: http.StatusOK, want: []string{"low alcohol", "seasonal"}},
+		{method: http.MethodPut, url: tagsEndpoint + "/ipa;sour", status: http.StatusBadRequest},
+		{method: http.MethodDelete, url: tagsEndpoint + "/seasonal", status: http.StatusOK, want: []string{"low alcohol"}},
+		{method: http.MethodPut, url: fmt.Sprintf("http://%s/api/v1/beers/%s/tags/ipa", addr, uuid.New()), status: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		response := sendReq(t, test.method, test.url, http.NoBody)
+		if response.status != test.status {
+			t.Errorf("%s %q returned status %d, want %d, body: %s", test.method, test.url, response.status, test.status, string(response.body))
+			continue
+		}
+
+		if test.status != http.StatusOK {
+			continue
+		}
+
+		var got burp.Beer
+		if err := json.Unmarshal(response.body, &got); err != nil {
+			t.Fatalf("Unmarshalling response body %s into a burp.Beer returned error %s", string(response.body), err)
+		}
+
+		if !cmp.Equal(got.Tags, test.want) {
+			t.Errorf("%s %q returned tags %q, want %q", test.method, test.url, got.Tags, test.want)
+		}
+	}
+
+	response := sendReq(t, http.MethodGet, tagsEndpoint, http.NoBody)
+	if want := `{"items":["low alcohol"]}`; strings.TrimSpace(string(response.body)) != want {
+		t.Errorf("GET %q returned body %s, want %s", tagsEndpoint, string(response.body), want)
+	}
+
+	listEndpoint := fmt.Sprintf("http://%s/api/v1/beers?name=%s&tag=Low+Alcohol", addr, beer.Name)
+	response = sendReq(t, http.MethodGet, listEndpoint, http.NoBody)
+
+	var page struct {
+		Items []*burp.Beer `json:"items"`
+	}
+	if err := json.Unmarshal(response.body, &page); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a page returned error %s", string(response.body), err)
+	}
+
+	if len(page.Items) != 1 || page.Items[0].ID != beer.ID {
+		t.Errorf("GET %q returned beers %+v, want tagged beer %q only", listEndpoint, page.Items, beer.ID)
+	}
+}
+
+func TestStock(t *testing.T) {
+	beer := burptest.RandBeer()
+	repository.SaveBeer(ctx, beer)
+
+	stockEndpoint := fmt.Sprintf("http://%s/api/v1/beers/%s/stock", addr, beer.ID)
+	location := burptest.RandString(12)
+
+	tests := []struct {
+		method string
+		url    string
+		body   string
+		status int
+		want   burp.Stock
+	}{
+		{
+			method: http.MethodPost,
+			url:    stockEndpoint + "/" + location + "/movements",
+			body:   `{"delta":6}`,
+			status: http.StatusCreated,
+			want:   burp.Stock{BeerID: beer.ID, Location: location, Quantity: 6},
+		},
+		{
+			method: http.MethodPut,
+			url:    stockEndpoint + "/" + location,
+			body:   `{"threshold":4}`,
+			status: http.StatusOK,
+			want:   burp.Stock{BeerID: beer.ID, Location: location, Quantity: 6, Threshold: 4},
+		},
+		{
+			method: http.MethodPost,
+			url:    stockEndpoint + "/" + location + "/movements",
+			body:   `{"delta":-3}`,
+			status: http.StatusCreated,
+			want:   burp.Stock{BeerID: beer.ID, Location: location, Quantity: 3, Threshold: 4},
+		},
+		{
+			method: http.MethodPost,
+			url:    stockEndpoint + "/" + location + "/movements",
+			body:   `{"delta":-4}`,
+			status: http.StatusConflict,
+		},
+		{
+			method: http.MethodPost,
+			url:    stockEndpoint + "/" + location + "/movements",
+			body:   `{"delta":0}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			method: http.MethodPut,
+			url:    stockEndpoint + "/" + location,
+			body:   `{"threshold":-1}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			method: http.MethodPost,
+			url:    fmt.Sprintf("http://%s/api/v1/beers/%s/stock/cellar/movements", addr, uuid.New()),
+			body:   `{"delta":1}`,
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		response := sendReq(t, test.method, test.url, strings.NewReader(test.body))
+		if response.status != test.status {
+			t.Errorf("%s %q with body %s returned status %d, want %d, body: %s", test.method, test.url, test.body, response.status, test.status, string(response.body))
+			continue
+		}
+
+		if test.status >= http.StatusBadRequest {
+			continue
+		}
+
+		var got burp.Stock
+		if err := json.Unmarshal(response.body, &got); err != nil {
+			t.Fatalf("Unmarshalling response body %s into a burp.Stock returned error %s", string(response.body), err)
+		}
+
+		got.UpdatedAt = time.Time{}
+		if got != test.want {
+			t.Errorf("%s %q with body %s returned stock %+v, want %+v", test.method, test.url, test.body, got, test.want)
+		}
+	}
+
+	lowEndpoint := fmt.Sprintf("http://%s/api/v1/stock?low=true&location=%s", addr, location)
+	for _, url := range []string{stockEndpoint, lowEndpoint} {
+		response := sendReq(t, http.MethodGet, url, http.NoBody)
+
+		var page struct {
+			Items []map[string]any `json:"items"`
+		}
+		if err := json.Unmarshal(response.body, &page); err != nil {
+			t.Fatalf("Unmarshalling response body %s into a page returned error %s", string(response.body), err)
+		}
+
+		if len(page.Items) != 1 || page.Items[0]["quantity"] != 3.0 || page.Items[0]["low"] != true {
+			t.Errorf("GET %q returned stock %+v, want 3 units low", url, page.Items)
+		}
+	}
+}
+
+func TestOrders(t *testing.T) {
+	beers := []*burp.Beer{burptest.RandBeer(), burptest.RandBeer()}
+	beers[1].Price = burp.Price{Currency: burp.USD, Amount: 500}
+	for _, beer := range beers {
+		repository.SaveBeer(ctx, beer)
+	}
+
+	ordersEndpoint := fmt.Sprintf("http://%s/api/v1/orders", addr)
+	body := fmt.Sprintf(`{"customer":"Ann","items":[{"beerId":%q,"quantity":2},{"beerId":%q,"quantity":1}]}`, beers[0].ID, beers[1].ID)
+
+	response := sendReq(t, http.MethodPost, ordersEndpoint, strings.NewReader(body))
+	if response.status != http.StatusCreated {
+		t.Fatalf("POST %q returned status %d, want %d, body: %s", ordersEndpoint, response.status, http.StatusCreated, string(response.body))
+	}
+
+	var order struct {
+		burp.Order
+		Totals []burp.Price `json:"totals"`
+	}
+	if err := json.Unmarshal(response.body, &order); err != nil {
+		t.Fatalf("Unmarshalling response body %s into an order returned error %s", string(response.body), err)
+	}
+
+	if order.Status != burp.OrderPending || len(order.Lines) != 2 || order.Lines[0].Price != beers[0].Price {
+		t.Errorf("POST %q returned order %+v, want a pending order of beers at their price", ordersEndpoint, order.Order)
+	}
+
+	wantTotals := []burp.Price{{Currency: burp.EUR, Amount: 2 * beers[0].Price.Amount}, {Currency: burp.USD, Amount: 500}}
+	if !cmp.Equal(order.Totals, wantTotals) {
+		t.Errorf("POST %q returned totals %+v, want %+v", ordersEndpoint, order.Totals, wantTotals)
+	}
+
+	orderEndpoint := fmt.Sprintf("%s/%s", ordersEndpoint, order.ID)
+	tests := []struct {
+		method string
+		url    string
+		body   string
+		status int
+	}{
+		{method: http.MethodGet, url: orderEndpoint, status: http.StatusOK},
+		{method: http.MethodPost, url: orderEndpoint + "/pay", status: http.StatusOK},
+		{method: http.MethodPost, url: orderEndpoint + "/cancel", status: http.StatusConflict},
+		{method: http.MethodGet, url: fmt.Sprintf("%s/%s", ordersEndpoint, uuid.New()), status: http.StatusNotFound},
+		{method: http.MethodGet, url: ordersEndpoint + "?status=shipped", status: http.StatusBadRequest},
+		{method: http.MethodPost, url: ordersEndpoint, body: `{"customer":"Ann","items":[]}`, status: http.StatusBadRequest},
+		{
+			method: http.MethodPost,
+			url:    ordersEndpoint,
+			body:   fmt.Sprintf(`{"customer":"Ann","items":[{"beerId":%q,"quantity":1}]}`, uuid.New()),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		response := sendReq(t, test.method, test.url, strings.NewReader(test.body))
+		if response.status != test.status {
+			t.Errorf("%s %q with body %s returned status %d, want %d, body: %s", test.method, test.url, test.body, response.status, test.status, string(response.body))
+		}
+	}
+
+	listEndpoint := ordersEndpoint + "?status=paid&limit=1"
+	response = sendReq(t, http.MethodGet, listEndpoint, http.NoBody)
+
+	var page struct {
+		Items []*burp.Order `json:"items"`
+	}
+	if err := json.Unmarshal(response.body, &page); err != nil {
+		t.Fatalf("Unmarshalling response body %s into a page returned error %s", string(response.body), err)
+	}
+
+	if len(page.Items) != 1 || page.Items[0].ID != order.ID || page.Items[0].Status != burp.OrderPaid {
+		t.Errorf("GET %q returned orders %+v, want paid order %q", listEndpoint, page.Items, order.ID)
+	}
+}
+
+type exporterStub struct {
+	Beers []*burp.Beer
+	Err   error
+}
+
+func (s exporterStub) ExportBeers(ctx context.Context, f burp.BeerFilter, enc burp.BeerEncoder) error {
+	for _, beer := range s.Beers {
+		if err := enc.Encode(beer); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+
+	return s.Err
+}
+
+func TestExportBeersFailing(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter exporterStub
+	}{
+		{name: "BeforeStreaming", exporter: exporterStub{Err: repo.Error("unavailable")}},
+		{name: "WhileStreaming", exporter: exporterStub{Beers: []*burp.Beer{burptest.RandBeer()}, Err: repo.Error("unavailable")}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(chi.Handle(chi.ExportBeers(test.exporter)))
+			defer server.Close()
+
+			// an aborted response fails either on its headers or body
+			response, err := http.Get(server.URL + "?format=ndjson")
+			var body []byte
+			if err == nil {
+				defer response.Body.Close()
+				body, err = io.ReadAll(response.Body)
+			}
+
+			if len(test.exporter.Beers) == 0 {
+				if err != nil || response.StatusCode != http.StatusInternalServerError {
+					t.Fatalf("GET export failing before streaming returned error %v, want status %d", err, http.StatusInternalServerError)
+				}
+
+				if got := response.Header.Get("Content-Type"); strings.Contains(got, "ndjson") {
+					t.Errorf("GET export failing before streaming returned content type %q, want an error body", got)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Errorf("GET export failing while streaming returned status %d and complete body %s, want it aborted", response.StatusCode, string(body))
+			}
+		})
 	}
 }
@@ -2,9 +2,12 @@ package rest_test
 
 import (
 	"burp"
+	"burp/rates"
 	"burp/repo/repotest"
 	"burp/rest/chi"
 	"context"
+	"log"
+	"net"
 	"net/http"
 	"testing"
 	"time"
@@ -27,7 +30,14 @@ func TestMain(m *testing.M) {
 	// list of all routers/handlers to e2e test against
 	handlers := []http.Handler{
 		chi.Handler(&burp.Brewer{
-			BeerRepo: repository,
+			BeerRepo:    repository,
+			EventRepo:   repository,
+			PriceRepo:   repository,
+			BreweryRepo: repository,
+			StockRepo:   repository,
+			OrderRepo:   repository,
+			Tx:          repository,
+			Rates:       &rates.Static{Base: burp.EUR, Rates: map[burp.Currency]float64{burp.USD: 1.1, "JPY": 160}},
 		}),
 	}
 
@@ -37,7 +47,13 @@ func TestMain(m *testing.M) {
 			Handler: handler,
 		}
 
-		go server.ListenAndServe()
+		// listen before running tests so that first requests are not refused
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("Could not listen on %s: %s", addr, err)
+		}
+
+		go server.Serve(listener)
 
 		m.Run()
 
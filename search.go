@@ -0,0 +1,78 @@
+package burp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SimilarityThreshold is the minimum similarity for a beer name
+// to match a search text it does not contain.
+const SimilarityThreshold = 0.3
+
+// MaxSearchTextLength bounds search texts to keep searches cheap.
+const MaxSearchTextLength = 100
+
+// BeerSearch looks for at most Limit beers whose name matches Text.
+type BeerSearch struct {
+	Text  string
+	Limit int
+}
+
+type BeerMatch struct {
+	Beer  *Beer   `json:"beer"`
+	Score float64 `json:"score"`
+}
+
+// Score ranks how well beer name matches a search text.
+// It is the trigram similarity of both, plus one when
+// the name contains the text. Zero means no match.
+func Score(b *Beer, text string) float64 {
+	score := Similarity(b.Name, text)
+	if strings.Contains(strings.ToLower(b.Name), strings.ToLower(text)) {
+		return score + 1
+	}
+
+	if score < SimilarityThreshold {
+		return 0
+	}
+
+	return score
+}
+
+// Similarity returns how close two strings are, from 0 to 1,
+// as the ratio of trigrams they share, the same way Postgres
+// pg_trgm extension does.
+func Similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// trigrams splits str in lower-cased words, each padded
+// with two spaces before and one after, and returns
+// the set of their three characters sequences.
+func trigrams(str string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(str), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,49 @@
+package burp_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "word", b: "word", want: 1},
+		{a: "word", b: "WORD", want: 1},
+		{a: "word", b: "two words", want: 4.0 / 11},
+		{a: "abc", b: "xyz", want: 0},
+		{a: "", b: "word", want: 0},
+	}
+
+	for _, test := range tests {
+		if got := burp.Similarity(test.a, test.b); got != test.want {
+			t.Errorf("Similarity(%q, %q) got %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	beer := burptest.RandBeer()
+	beer.Name = "Guinness Stout"
+
+	tests := []struct {
+		name string
+		text string
+		want func(score float64) bool
+	}{
+		{name: "Contained", text: "ness st", want: func(s float64) bool { return s > 1 }},
+		{name: "Misspelled", text: "guiness", want: func(s float64) bool { return s >= burp.SimilarityThreshold && s < 1 }},
+		{name: "Unrelated", text: "lager", want: func(s float64) bool { return s == 0 }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := burp.Score(beer, test.text); !test.want(got) {
+				t.Errorf("Score(%q, %q) got unexpected score %v", beer.Name, test.text, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package burp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Bounds of beer tags.
+const (
+	MaxTags      = 20
+	MaxTagLength = 30
+)
+
+var (
+	ErrTagMissing  = Error("tag is missing")
+	ErrTagTooLong  = Errorf("tag exceed %d character", MaxTagLength)
+	ErrTagInvalid  = Error("tag must only have letters, digits, spaces, dashes and underscores")
+	ErrTooManyTags = Errorf("beer has more than %d tags", MaxTags)
+)
+
+// NormalizeTag returns tag trimmed and lower-cased,
+// so that "IPA" and " ipa" are the same tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags returns tags normalized, sorted and
+// without duplicates, nil when there are none.
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	set := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if !set[tag] {
+			set[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	sort.Strings(normalized)
+	return normalized
+}
+
+// ValidateTag fails unless tag is normalized and made of
+// letters, digits, spaces, dashes and underscores.
+func ValidateTag(tag string) error {
+	if tag == "" {
+		return ErrTagMissing
+	}
+
+	if len(tag) > MaxTagLength {
+		return ErrTagTooLong
+	}
+
+	if tag != NormalizeTag(tag) {
+		return ErrTagInvalid
+	}
+
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '-' && r != '_' {
+			return ErrTagInvalid
+		}
+	}
+
+	return nil
+}
+
+// HasTag reports whether beer is tagged with tag.
+func (b *Beer) HasTag(tag string) bool {
+	i := sort.SearchStrings(b.Tags, tag)
+	return i < len(b.Tags) && b.Tags[i] == tag
+}
+
+// TagBeer adds tags to the beer of given id, saving it as SaveBeer
+// does unless it already has them all. It returns the tagged beer.
+func (b *Brewer) TagBeer(ctx context.Context, id ID, tags ...string) (*Beer, error) {
+	return b.retag(ctx, id, func(beer *Beer) {
+		beer.Tags = NormalizeTags(append(beer.Tags, tags...))
+	})
+}
+
+// UntagBeer removes tag from the beer of given id, saving it as SaveBeer
+// does unless it does not have the tag. It returns the untagged beer.
+func (b *Brewer) UntagBeer(ctx context.Context, id ID, tag string) (*Beer, error) {
+	tag = NormalizeTag(tag)
+
+	return b.retag(ctx, id, func(beer *Beer) {
+		tags := beer.Tags[:0:0]
+		for _, t := range beer.Tags {
+			if t != tag {
+				tags = append(tags, t)
+			}
+		}
+		beer.Tags = NormalizeTags(tags)
+	})
+}
+
+// retag changes tags of the beer of given id with fn,
+// then saves it in a transaction if they did change.
+func (b *Brewer) retag(ctx context.Context, id ID, fn func(beer *Beer)) (*Beer, error) {
+	var beer *Beer
+
+	err := b.inTx(ctx, func(ctx context.Context) error {
+		var err error
+		beer, err = b.BeerRepo.SelectBeer(ctx, id)
+		if err != nil {
+			return fmt.Errorf("unable to select beer %q: %w", id, err)
+		}
+
+		before := strings.Join(beer.Tags, ",")
+		fn(beer)
+		if strings.Join(beer.Tags, ",") == before {
+			return nil
+		}
+
+		return b.SaveBeer(ctx, beer)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return beer, nil
+}
@@ -0,0 +1,115 @@
+package burp_test
+
+import (
+	"burp"
+	"burp/burptest"
+	"burp/repo/memory"
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{tags: nil, want: nil},
+		{tags: []string{}, want: nil},
+		{tags: []string{"Seasonal", " IPA ", "ipa", "gluten-free"}, want: []string{"gluten-free", "ipa", "seasonal"}},
+	}
+
+	for _, test := range tests {
+		if got := burp.NormalizeTags(test.tags); !cmp.Equal(got, test.want) {
+			t.Errorf("NormalizeTags(%q) returned %q, want %q", test.tags, got, test.want)
+		}
+	}
+}
+
+func TestValidateBeerTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want error
+	}{
+		{name: "None", tags: nil},
+		{name: "Valid", tags: []string{"gluten-free", "low alcohol", "trappist_ale"}},
+		{name: "Empty", tags: []string{""}, want: burp.ErrTagMissing},
+		{name: "TooLong", tags: []string{strings.Repeat("a", burp.MaxTagLength+1)}, want: burp.ErrTagTooLong},
+		{name: "NotNormalized", tags: []string{"IPA"}, want: burp.ErrTagInvalid},
+		{name: "Separator", tags: []string{"ipa;sour"}, want: burp.ErrTagInvalid},
+		{name: "TooMany", tags: make([]string, burp.MaxTags+1), want: burp.ErrTooManyTags},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			beer := burptest.RandBeer()
+			beer.Tags = test.tags
+
+			err := beer.Validate()
+			if !errors.Is(err, test.want) {
+				t.Errorf("beer %+v Validate() returned error %v, want %v", beer, err, test.want)
+			}
+		})
+	}
+}
+
+func TestSaveBeerNormalizesTags(t *testing.T) {
+	beer := burptest.RandBeer()
+	beer.Tags = []string{"Sour", "IPA", "sour"}
+	brewer := &burp.Brewer{BeerRepo: memory.New()}
+
+	if err := brewer.SaveBeer(context.Background(), beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	if want := []string{"ipa", "sour"}; !cmp.Equal(beer.Tags, want) {
+		t.Errorf("SaveBeer(ctx, beer) saved tags %q, want %q", beer.Tags, want)
+	}
+}
+
+func TestTagBeer(t *testing.T) {
+	ctx := context.Background()
+	memRepo := memory.New()
+	brewer := &burp.Brewer{BeerRepo: memRepo, Tx: memRepo}
+
+	beer := burptest.RandBeer()
+	beer.Tags = nil
+	if err := brewer.SaveBeer(ctx, beer); err != nil {
+		t.Fatalf("SaveBeer(ctx, %+v) returned unexpected error %s", beer, err)
+	}
+
+	tagged, err := brewer.TagBeer(ctx, beer.ID, "Seasonal", "ipa")
+	if err != nil {
+		t.Fatalf("TagBeer(ctx, %s, tags) returned unexpected error %s", beer.ID, err)
+	}
+
+	if want := []string{"ipa", "seasonal"}; !cmp.Equal(tagged.Tags, want) || tagged.Version != 2 {
+		t.Errorf("TagBeer(ctx, %s, tags) returned beer %+v, want tags %q at version 2", beer.ID, tagged, want)
+	}
+
+	// tagging a beer with its tags does not save it again
+	again, err := brewer.TagBeer(ctx, beer.ID, "ipa")
+	if err != nil {
+		t.Fatalf("TagBeer(ctx, %s, ipa) returned unexpected error %s", beer.ID, err)
+	}
+
+	if again.Version != 2 {
+		t.Errorf("TagBeer(ctx, %s, ipa) of a tagged beer set version %d, want 2", beer.ID, again.Version)
+	}
+
+	untagged, err := brewer.UntagBeer(ctx, beer.ID, "IPA")
+	if err != nil {
+		t.Fatalf("UntagBeer(ctx, %s, IPA) returned unexpected error %s", beer.ID, err)
+	}
+
+	if want := []string{"seasonal"}; !cmp.Equal(untagged.Tags, want) || untagged.Version != 3 {
+		t.Errorf("UntagBeer(ctx, %s, IPA) returned beer %+v, want tags %q at version 3", beer.ID, untagged, want)
+	}
+
+	if _, err := brewer.TagBeer(ctx, beer.ID, "ipa;sour"); !errors.Is(err, burp.ErrTagInvalid) {
+		t.Errorf("TagBeer(ctx, %s, ipa;sour) returned unexpected error:\ngot %v want %v", beer.ID, err, burp.ErrTagInvalid)
+	}
+}
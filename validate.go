@@ -1,11 +1,13 @@
 package burp
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"strconv"
+	"time"
+)
 
 func (p Price) Validate() error {
-	switch p.Currency {
-	case EUR, USD:
-	default:
+	if _, ok := LookupCurrency(p.Currency); !ok {
 		return ErrCurrencyNotSupported
 	}
 
@@ -20,6 +22,22 @@ func (id ID) Validate() error {
 	return nil
 }
 
+func (c *PriceChange) Validate() error {
+	if err := c.BeerID.Validate(); err != nil {
+		return Errorf("invalid beer id: %w", err)
+	}
+
+	if err := c.Price.Validate(); err != nil {
+		return Errorf("invalid price: %w", err)
+	}
+
+	if c.From.IsZero() {
+		return ErrPriceChangeDateMissing
+	}
+
+	return nil
+}
+
 func (b *Beer) Validate() error {
 	if err := b.ID.Validate(); err != nil {
 		return Errorf("invalid id: %w", err)
@@ -37,6 +55,12 @@ func (b *Beer) Validate() error {
 		return Errorf("invalid price: %w", err)
 	}
 
+	if b.BreweryID != nil {
+		if err := b.BreweryID.Validate(); err != nil {
+			return Errorf("invalid brewery id: %w", err)
+		}
+	}
+
 	if b.Name == "" {
 		return ErrBeerNameMissing
 	}
@@ -45,5 +69,328 @@ func (b *Beer) Validate() error {
 		return ErrBeerNameTooLong
 	}
 
+	return b.validateDetails()
+}
+
+// Bounds of beer catalogue details.
+const (
+	MaxBreweryLength     = 100
+	MaxStyleLength       = 50
+	MaxDescriptionLength = 2000
+	MaxABV               = 100.0
+	MaxIBU               = 1000
+	// MaxVolume is a 100 liters keg.
+	MaxVolume = 100000
+)
+
+func (b *Beer) validateDetails() error {
+	if len(b.Brewery) > MaxBreweryLength {
+		return ErrBeerBreweryTooLong
+	}
+
+	if len(b.Style) > MaxStyleLength {
+		return ErrBeerStyleTooLong
+	}
+
+	if len(b.Description) > MaxDescriptionLength {
+		return ErrBeerDescriptionTooLong
+	}
+
+	// negated so that NaN is out of range too
+	if !(b.ABV >= 0 && b.ABV <= MaxABV) {
+		return ErrBeerABVOutOfRange
+	}
+
+	if b.IBU > MaxIBU {
+		return ErrBeerIBUOutOfRange
+	}
+
+	if b.Volume > MaxVolume {
+		return ErrBeerVolumeOutOfRange
+	}
+
+	return b.validateTags()
+}
+
+func (b *Beer) validateTags() error {
+	if len(b.Tags) > MaxTags {
+		return ErrTooManyTags
+	}
+
+	for i, tag := range b.Tags {
+		if err := ValidateTag(tag); err != nil {
+			return Errorf("invalid tag %q: %w", tag, err)
+		}
+
+		// NormalizeTags sorts tags and removes duplicates
+		if i > 0 && b.Tags[i-1] >= tag {
+			return Errorf("invalid tags %q: must be normalized", b.Tags)
+		}
+	}
+
 	return nil
 }
+
+func (s BeerSort) Validate() error {
+	switch s {
+	case SortByName, SortByCreatedAt, SortByPrice:
+	default:
+		return ErrSortNotSupported
+	}
+
+	return nil
+}
+
+func (c *Cursor) Validate() error {
+	if err := c.Sort.Validate(); err != nil {
+		return ErrCursorInvalid
+	}
+
+	if err := c.ID.Validate(); err != nil {
+		return ErrCursorInvalid
+	}
+
+	switch c.Sort {
+	case SortByCreatedAt:
+		if _, err := time.Parse(sortKeyTimeLayout, c.Key); err != nil {
+			return ErrCursorInvalid
+		}
+	case SortByPrice:
+		if _, err := strconv.ParseUint(c.Key, 10, 64); err != nil {
+			return ErrCursorInvalid
+		}
+	}
+
+	return nil
+}
+
+func (f *BeerFilter) Validate() error {
+	if f.Currency != "" {
+		if err := (Price{Currency: f.Currency}).Validate(); err != nil {
+			return err
+		}
+	} else if f.MinAmount != 0 || f.MaxAmount != 0 {
+		return ErrPriceRangeWithoutCurrency
+	}
+
+	if f.MaxAmount != 0 && f.MinAmount > f.MaxAmount {
+		return ErrPriceRangeInvalid
+	}
+
+	for _, tag := range f.Tags {
+		if err := ValidateTag(tag); err != nil {
+			return Errorf("invalid tag %q: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+func (q *BeerQuery) Validate() error {
+	if err := q.Filter.Validate(); err != nil {
+		return Errorf("invalid filter: %w", err)
+	}
+
+	if err := q.Sort.Validate(); err != nil {
+		return Errorf("invalid sort %q: %w", q.Sort, err)
+	}
+
+	if q.Sort == SortByPrice && q.Filter.Currency == "" {
+		return ErrPriceSortWithoutCurrency
+	}
+
+	if q.Limit < 1 || q.Limit > MaxPageSize {
+		return ErrPageSizeOutOfRange
+	}
+
+	if q.After == nil {
+		return nil
+	}
+
+	if err := q.After.Validate(); err != nil {
+		return err
+	}
+
+	if q.After.Sort != q.Sort || q.After.Desc != q.Desc {
+		return ErrCursorMismatch
+	}
+
+	return nil
+}
+
+func (s *BeerSearch) Validate() error {
+	if s.Text == "" {
+		return ErrSearchTextMissing
+	}
+
+	if len(s.Text) > MaxSearchTextLength {
+		return ErrSearchTextTooLong
+	}
+
+	if s.Limit < 1 || s.Limit > MaxPageSize {
+		return ErrPageSizeOutOfRange
+	}
+
+	return nil
+}
+
+func (b *Brewery) Validate() error {
+	if err := b.ID.Validate(); err != nil {
+		return Errorf("invalid id: %w", err)
+	}
+
+	if b.CreatedAt.IsZero() {
+		return ErrBreweryCreateDateMissing
+	}
+
+	if b.UpdatedAt.IsZero() {
+		return ErrBreweryUpdateDateMissing
+	}
+
+	if b.Name == "" {
+		return ErrBreweryNameMissing
+	}
+
+	if len(b.Name) > MaxBreweryLength {
+		return ErrBreweryNameTooLong
+	}
+
+	if len(b.Country) > MaxBreweryLength || len(b.City) > MaxBreweryLength {
+		return ErrBreweryPlaceTooLong
+	}
+
+	return nil
+}
+
+func (q *BreweryQuery) Validate() error {
+	if q.Limit < 1 || q.Limit > MaxPageSize {
+		return ErrPageSizeOutOfRange
+	}
+
+	if q.After == nil {
+		return nil
+	}
+
+	if err := q.After.Validate(); err != nil {
+		return err
+	}
+
+	if q.After.Sort != SortByName || q.After.Desc {
+		return ErrBreweryCursorInvalid
+	}
+
+	return nil
+}
+
+func validateLocation(location string) error {
+	if location == "" {
+		return ErrLocationMissing
+	}
+
+	if len(location) > MaxLocationLength {
+		return ErrLocationTooLong
+	}
+
+	return nil
+}
+
+func (m *StockMovement) Validate() error {
+	if err := m.BeerID.Validate(); err != nil {
+		return Errorf("invalid beer id: %w", err)
+	}
+
+	if err := validateLocation(m.Location); err != nil {
+		return err
+	}
+
+	if m.Delta == 0 {
+		return ErrStockDeltaMissing
+	}
+
+	if m.Delta > MaxStockQuantity || m.Delta < -MaxStockQuantity {
+		return ErrStockDeltaTooLarge
+	}
+
+	return nil
+}
+
+func (t *StockThreshold) Validate() error {
+	if err := t.BeerID.Validate(); err != nil {
+		return Errorf("invalid beer id: %w", err)
+	}
+
+	if err := validateLocation(t.Location); err != nil {
+		return err
+	}
+
+	if t.Threshold > MaxStockQuantity {
+		return ErrStockThresholdTooHigh
+	}
+
+	return nil
+}
+
+func (q *StockQuery) Validate() error {
+	if len(q.Location) > MaxLocationLength {
+		return ErrLocationTooLong
+	}
+
+	return nil
+}
+
+func (c *Cart) Validate() error {
+	if c.Customer == "" {
+		return ErrCustomerMissing
+	}
+
+	if len(c.Customer) > MaxCustomerLength {
+		return ErrCustomerTooLong
+	}
+
+	if len(c.Items) == 0 {
+		return ErrCartEmpty
+	}
+
+	if len(c.Items) > MaxOrderLines {
+		return ErrCartTooLarge
+	}
+
+	seen := make(map[ID]bool, len(c.Items))
+	for _, item := range c.Items {
+		if err := item.BeerID.Validate(); err != nil {
+			return Errorf("invalid beer id: %w", err)
+		}
+
+		if seen[item.BeerID] {
+			return Errorf("invalid beer id %q: %w", item.BeerID, ErrCartBeerDuplicate)
+		}
+		seen[item.BeerID] = true
+
+		if item.Quantity < 1 || item.Quantity > MaxOrderQuantity {
+			return ErrOrderQuantityInvalid
+		}
+	}
+
+	return nil
+}
+
+func (s OrderStatus) Validate() error {
+	switch s {
+	case OrderPending, OrderPaid, OrderCancelled:
+		return nil
+	default:
+		return ErrOrderStatusInvalid
+	}
+}
+
+func (q *OrderQuery) Validate() error {
+	if q.Limit < 1 || q.Limit > MaxPageSize {
+		return ErrPageSizeOutOfRange
+	}
+
+	if q.Status == "" {
+		return nil
+	}
+
+	return q.Status.Validate()
+}
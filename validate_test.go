@@ -4,6 +4,7 @@ import (
 	"burp"
 	"burp/burptest"
 	"errors"
+	"math"
 	"testing"
 	"time"
 )
@@ -74,6 +75,36 @@ func TestValidateBeerWithTooLongName(t *testing.T) {
 	}
 }
 
+func TestValidateBeerDetails(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(beer *burp.Beer)
+		want   error
+	}{
+		{name: "Unknown", modify: func(b *burp.Beer) { b.ABV, b.IBU, b.Volume = 0, 0, 0 }},
+		{name: "BreweryTooLong", modify: func(b *burp.Beer) { b.Brewery = burptest.RandString(burp.MaxBreweryLength + 1) }, want: burp.ErrBeerBreweryTooLong},
+		{name: "StyleTooLong", modify: func(b *burp.Beer) { b.Style = burptest.RandString(burp.MaxStyleLength + 1) }, want: burp.ErrBeerStyleTooLong},
+		{name: "DescriptionTooLong", modify: func(b *burp.Beer) { b.Description = burptest.RandString(burp.MaxDescriptionLength + 1) }, want: burp.ErrBeerDescriptionTooLong},
+		{name: "ABVNegative", modify: func(b *burp.Beer) { b.ABV = -0.5 }, want: burp.ErrBeerABVOutOfRange},
+		{name: "ABVTooHigh", modify: func(b *burp.Beer) { b.ABV = burp.MaxABV + 0.1 }, want: burp.ErrBeerABVOutOfRange},
+		{name: "ABVNaN", modify: func(b *burp.Beer) { b.ABV = math.NaN() }, want: burp.ErrBeerABVOutOfRange},
+		{name: "IBUTooHigh", modify: func(b *burp.Beer) { b.IBU = burp.MaxIBU + 1 }, want: burp.ErrBeerIBUOutOfRange},
+		{name: "VolumeTooHigh", modify: func(b *burp.Beer) { b.Volume = burp.MaxVolume + 1 }, want: burp.ErrBeerVolumeOutOfRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			beer := burptest.RandBeer()
+			test.modify(beer)
+
+			err := beer.Validate()
+			if !errors.Is(err, test.want) {
+				t.Errorf("beer %+v Validate() returned error %v, want %v", beer, err, test.want)
+			}
+		})
+	}
+}
+
 func TestIDValidateWithEmptyUUID(t *testing.T) {
 	id := burp.ID{}
 	err := id.Validate()
@@ -95,3 +126,139 @@ func TestNewPriceWithInvalidCurrency(t *testing.T) {
 		t.Errorf("price %v Validate() got error %s, want %s", price, err, want)
 	}
 }
+
+func TestValidateBeerQuery(t *testing.T) {
+	beer := burptest.RandBeer()
+
+	tests := []struct {
+		name  string
+		query burp.BeerQuery
+		want  error
+	}{
+		{
+			name:  "SortNotSupported",
+			query: burp.BeerQuery{Sort: "color", Limit: 1},
+			want:  burp.ErrSortNotSupported,
+		},
+		{
+			name:  "LimitTooLow",
+			query: burp.BeerQuery{Sort: burp.SortByName, Limit: -1},
+			want:  burp.ErrPageSizeOutOfRange,
+		},
+		{
+			name:  "LimitTooHigh",
+			query: burp.BeerQuery{Sort: burp.SortByName, Limit: burp.MaxPageSize + 1},
+			want:  burp.ErrPageSizeOutOfRange,
+		},
+		{
+			name: "FilterCurrencyNotSupported",
+			query: burp.BeerQuery{
+				Filter: burp.BeerFilter{Currency: "Pesos"},
+				Sort:   burp.SortByName,
+				Limit:  1,
+			},
+			want: burp.ErrCurrencyNotSupported,
+		},
+		{
+			name: "FilterPriceRangeWithoutCurrency",
+			query: burp.BeerQuery{
+				Filter: burp.BeerFilter{MinAmount: 10},
+				Sort:   burp.SortByName,
+				Limit:  1,
+			},
+			want: burp.ErrPriceRangeWithoutCurrency,
+		},
+		{
+			name: "FilterPriceRangeInvalid",
+			query: burp.BeerQuery{
+				Filter: burp.BeerFilter{Currency: burp.EUR, MinAmount: 20, MaxAmount: 10},
+				Sort:   burp.SortByName,
+				Limit:  1,
+			},
+			want: burp.ErrPriceRangeInvalid,
+		},
+		{
+			name:  "PriceSortWithoutCurrency",
+			query: burp.BeerQuery{Sort: burp.SortByPrice, Limit: 1},
+			want:  burp.ErrPriceSortWithoutCurrency,
+		},
+		{
+			name: "CursorPriceKeyCorrupted",
+			query: burp.BeerQuery{
+				Filter: burp.BeerFilter{Currency: burp.EUR},
+				Sort:   burp.SortByPrice,
+				Limit:  1,
+				After:  &burp.Cursor{Sort: burp.SortByPrice, Key: beer.Name, ID: beer.ID},
+			},
+			want: burp.ErrCursorInvalid,
+		},
+		{
+			name: "CursorSortMismatch",
+			query: burp.BeerQuery{
+				Sort:  burp.SortByName,
+				Limit: 1,
+				After: burp.NewCursor(beer, burp.SortByCreatedAt, false),
+			},
+			want: burp.ErrCursorMismatch,
+		},
+		{
+			name: "CursorKeyCorrupted",
+			query: burp.BeerQuery{
+				Sort:  burp.SortByCreatedAt,
+				Limit: 1,
+				After: &burp.Cursor{Sort: burp.SortByCreatedAt, Key: beer.Name, ID: beer.ID},
+			},
+			want: burp.ErrCursorInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.query.Validate()
+			if !errors.Is(err, test.want) {
+				t.Errorf("query %+v Validate() got error %s, want %s", test.query, err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	want := burp.NewCursor(burptest.RandBeer(), burp.SortByCreatedAt, true)
+
+	got, err := burp.ParseCursor(want.String())
+	if err != nil {
+		t.Fatalf("ParseCursor(%q) returned unexpected error %s", want, err)
+	}
+
+	if *got != *want {
+		t.Errorf("ParseCursor(%q) got %+v, want %+v", want, got, want)
+	}
+
+	_, err = burp.ParseCursor(burptest.RandString(10))
+	if !errors.Is(err, burp.ErrCursorInvalid) {
+		t.Errorf("ParseCursor of random string got error %s, want %s", err, burp.ErrCursorInvalid)
+	}
+}
+
+func TestValidateBreweryWithoutDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		reset func(*burp.Brewery)
+		want  error
+	}{
+		{"CreatedAt", func(b *burp.Brewery) { b.CreatedAt = time.Time{} }, burp.ErrBreweryCreateDateMissing},
+		{"UpdatedAt", func(b *burp.Brewery) { b.UpdatedAt = time.Time{} }, burp.ErrBreweryUpdateDateMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brewery := burptest.RandBrewery()
+			tt.reset(brewery)
+
+			err := brewery.Validate()
+			if !errors.Is(err, tt.want) {
+				t.Errorf("RandBrewery %+v Validate() returned error %s, want %s", brewery, err, tt.want)
+			}
+		})
+	}
+}